@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/spf13/cobra"
+)
+
+// areaPath resolves an area path or the unique last segment(s) of one (see
+// az.FindAreaPath). When the areas cannot be listed, paths are used as
+// given.
+func areaPath(name string) (string, error) {
+	paths, err := az.AreaPaths()
+	if err != nil {
+		if strings.Contains(name, `\`) {
+			return strings.Trim(strings.TrimSpace(name), `\`), nil
+		}
+		return "", err
+	}
+	return az.FindAreaPath(paths, name)
+}
+
+// areaField is the area picker of the create and edit forms, populated from
+// the project's areas. value preselects an area and defaults to the project
+// root. A plain input is used when the areas cannot be listed.
+func areaField(value *string) huh.Field {
+	paths, err := az.AreaPaths()
+	if err != nil || len(paths) == 0 {
+		return huh.NewInput().Title("Area path").Value(value)
+	}
+	if *value == "" || !contains(paths, *value) {
+		*value = paths[0]
+	}
+	height := len(paths) + 2
+	if height > 10 {
+		height = 10
+	}
+	return huh.NewSelect[string]().Title("Area").Options(optsFrom(paths)...).Height(height).Value(value)
+}
+
+// completeAreas completes area paths.
+func completeAreas(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	defer quietCompletion()()
+	paths, err := az.AreaPaths()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp
+}
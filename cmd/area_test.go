@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_Areas(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		p.Add("User Story", "Root", nil)
+		p.Add("User Story", "App", map[string]any{"System.AreaPath": `Fake\Frontend\Mobile`})
+		p.Add("Bug", "Web", map[string]any{"System.AreaPath": `Fake\Frontend`})
+
+		createArea = "backend"
+		defer func() { createArea, createAreaPath = "", "" }()
+		captureStdout(t, func() error { return createStoryCmd.RunE(createStoryCmd, []string{"Service"}) })
+		if got := p.Field(4, "System.AreaPath"); got != `Fake\Backend` {
+			t.Fatalf("created in %q", got)
+		}
+
+		editArea = "Mobile"
+		defer func() { editArea = "" }()
+		captureStdout(t, func() error { return editCmd.RunE(editCmd, []string{"1"}) })
+		if got := p.Field(1, "System.AreaPath"); got != `Fake\Frontend\Mobile` {
+			t.Fatalf("edit --area moved to %q", got)
+		}
+
+		listArea = `Fake\Frontend`
+		defer func() { listArea, listAreaPath, listUnder, listShowArea = "", "", false, false }()
+		count := func() int {
+			t.Helper()
+			if err := resolveListFilters(); err != nil {
+				t.Fatal(err)
+			}
+			items, err := queryItems("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return len(items)
+		}
+		if n := count(); n != 1 {
+			t.Fatalf("--area matched %d items, want 1", n)
+		}
+		listUnder = true
+		if n := count(); n != 3 {
+			t.Fatalf("--area --under matched %d items, want 3", n)
+		}
+
+		listShowArea = true
+		items, _ := queryItems("")
+		var md string
+		var err error
+		captureStdout(t, func() error { md, err = renderItems(items); return err })
+		if !strings.Contains(md, "| Title | Area |") || !strings.Contains(md, `Fake\\Frontend\\Mobile`) {
+			t.Fatalf("area column missing:\n%s", md)
+		}
+
+		listArea = "Nowhere"
+		if err := resolveListFilters(); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("expected unknown area error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/term"
+	"github.com/sa6mwa/ab/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	attachComment       string
+	attachmentsDownload string
+	attachmentsForce    bool
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <id> <file...>",
+	Short: "Upload files and attach them to a work-item",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(args[0])
+		paths := make([]string, 0, len(args)-1)
+		for _, a := range args[1:] {
+			xp, err := util.ExpandTilde(a)
+			if err != nil {
+				return err
+			}
+			fi, err := os.Stat(xp)
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				return fmt.Errorf("%s is a directory", a)
+			}
+			paths = append(paths, xp)
+		}
+		var recs []output.Attachment
+		for _, p := range paths {
+			name := filepath.Base(p)
+			ref, err := az.UploadAttachment(p, name)
+			if err != nil {
+				return err
+			}
+			raw, err := az.LinkAttachment(id, ref.URL, attachComment)
+			if err != nil {
+				return err
+			}
+			rec := output.Attachment{ID: ref.ID, Name: name, URL: ref.URL}
+			rec.Item, _ = strconv.Atoi(id)
+			var wi az.WorkItem
+			if json.Unmarshal(raw, &wi) == nil {
+				for _, a := range az.Attachments(&wi) {
+					if a.URL == ref.URL {
+						rec = attachmentRecord(wi.ID, a)
+					}
+				}
+			}
+			if rec.Size == 0 {
+				if fi, err := os.Stat(p); err == nil {
+					rec.Size = fi.Size()
+				}
+			}
+			recs = append(recs, rec)
+			if !structured() {
+				fmt.Fprintf(os.Stderr, "Attached %s (%s) to %s\n", name, humanSize(rec.Size), id)
+			}
+		}
+		if structured() {
+			return emitRecords(output.AttachmentColumns, recs, "")
+		}
+		return nil
+	},
+}
+
+var attachmentsCmd = &cobra.Command{
+	Use:   "attachments <id> [--download [dir]]",
+	Short: "List or download the attachments of a work-item",
+	Long:  "List the files attached to a work-item. With --download the files are saved to dir (default the current directory); existing files are only overwritten with --force.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(args[0])
+		download := cmd.Flags().Changed("download")
+		if len(args) == 2 {
+			if !download {
+				return fmt.Errorf("unexpected argument %q (did you mean --download %s?)", args[1], args[1])
+			}
+			attachmentsDownload = args[1]
+		}
+		_, wi, err := az.ShowWorkItem(id)
+		if err != nil {
+			return err
+		}
+		atts := az.Attachments(wi)
+		recs := make([]output.Attachment, 0, len(atts))
+		for _, a := range atts {
+			recs = append(recs, attachmentRecord(wi.ID, a))
+		}
+		if download {
+			if err := downloadAttachments(recs, attachmentsDownload, attachmentsForce); err != nil {
+				return err
+			}
+		}
+		if structured() {
+			return emitRecords(output.AttachmentColumns, recs, "")
+		}
+		if download {
+			for _, r := range recs {
+				fmt.Fprintf(os.Stderr, "Saved %s (%s) to %s\n", r.Name, humanSize(r.Size), r.Path)
+			}
+			if len(recs) == 0 {
+				fmt.Fprintf(os.Stderr, "No attachments on %s\n", id)
+			}
+			return nil
+		}
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "# Attachments AB#%d\n\n", wi.ID)
+		b.WriteString(attachmentsMarkdown(recs))
+		r, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(term.DetectWidth()),
+		)
+		if err != nil {
+			return err
+		}
+		out, err := r.Render(b.String())
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		return nil
+	},
+}
+
+// downloadAttachments saves recs into dir and sets their Path. Attachments
+// sharing a name are saved with their id as prefix. Nothing is downloaded
+// when a destination exists and force is false.
+func downloadAttachments(recs []output.Attachment, dir string, force bool) error {
+	xd, err := util.ExpandTilde(strings.TrimSpace(dir))
+	if err != nil {
+		return err
+	}
+	if xd == "" {
+		xd = "."
+	}
+	if err := os.MkdirAll(xd, 0755); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	var exists []string
+	for i := range recs {
+		// Never trust the server-provided name with a path.
+		name := filepath.Base(filepath.Clean("/" + recs[i].Name))
+		if name == "/" || name == "." {
+			name = recs[i].ID
+		}
+		if seen[name] {
+			name = recs[i].ID + "-" + name
+		}
+		seen[name] = true
+		recs[i].Path = filepath.Join(xd, name)
+		if _, err := os.Stat(recs[i].Path); err == nil && !force {
+			exists = append(exists, recs[i].Path)
+		}
+	}
+	if len(exists) > 0 {
+		return fmt.Errorf("refusing to overwrite %s (use --force)", strings.Join(exists, ", "))
+	}
+	for _, r := range recs {
+		if err := az.DownloadAttachment(r.URL, r.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachmentsMarkdown renders attachments as a Markdown table.
+func attachmentsMarkdown(recs []output.Attachment) string {
+	if len(recs) == 0 {
+		return "No attachments.\n"
+	}
+	var b strings.Builder
+	b.WriteString("| Name | Size | Added |\n|---|---:|---|\n")
+	for _, r := range recs {
+		added := "(no date)"
+		if t, err := time.Parse(time.RFC3339, r.Created); err == nil {
+			added = commentDate(t)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", strings.ReplaceAll(r.Name, "|", "\\|"), humanSize(r.Size), added)
+	}
+	return b.String()
+}
+
+func attachmentRecord(item int, a az.Attachment) output.Attachment {
+	created := ""
+	if !a.Created.IsZero() {
+		created = a.Created.UTC().Format(time.RFC3339)
+	}
+	return output.Attachment{ID: a.ID, Item: item, Name: a.Name, Size: a.Size, Created: created, URL: a.URL}
+}
+
+func init() {
+	attachCmd.Flags().StringVarP(&attachComment, "comment", "m", "", "Comment stored with each attachment")
+	attachmentsCmd.Flags().StringVar(&attachmentsDownload, "download", "", "Download the attachments to `dir`")
+	attachmentsCmd.Flags().Lookup("download").NoOptDefVal = "."
+	attachmentsCmd.Flags().BoolVar(&attachmentsForce, "force", false, "Overwrite existing files when downloading")
+	rootCmd.AddCommand(attachCmd, attachmentsCmd)
+}
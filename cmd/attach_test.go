@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_AttachmentsOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		id := p.Add("User Story", "Story", nil)
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+
+		src := t.TempDir()
+		bin := []byte{0, 1, 2, 0xff, '\n', 'x'}
+		if err := os.WriteFile(src+"/blob.bin", bin, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(src+"/other", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(src+"/other/blob.bin", []byte("second"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := attachCmd.RunE(attachCmd, []string{"1", src + "/blob.bin", src + "/other/blob.bin"}); err != nil {
+			t.Fatalf("attach: %v", err)
+		}
+		if got := p.Field(id, "System.AttachedFileCount"); got != "2" {
+			t.Fatalf("AttachedFileCount = %q", got)
+		}
+
+		formatFlag = output.JSON
+		defer func() { formatFlag = "" }()
+		var recs []output.Attachment
+		out := captureStdout(t, func() error { return attachmentsCmd.RunE(attachmentsCmd, []string{"1"}) })
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("attachments output is not JSON: %v\n%s", err, out)
+		}
+		if len(recs) != 2 || recs[0].Name != "blob.bin" || recs[0].Size != int64(len(bin)) || recs[1].Item != id {
+			t.Fatalf("attachments = %+v", recs)
+		}
+
+		dst := t.TempDir()
+		if err := downloadAttachments(recs, dst, false); err != nil {
+			t.Fatalf("download: %v", err)
+		}
+		if got, _ := os.ReadFile(dst + "/blob.bin"); string(got) != string(bin) {
+			t.Fatalf("downloaded %q, want %q", got, bin)
+		}
+		if got, _ := os.ReadFile(recs[1].Path); string(got) != "second" || recs[1].Path == recs[0].Path {
+			t.Fatalf("duplicate name saved to %s as %q", recs[1].Path, got)
+		}
+		if err := downloadAttachments(recs, dst, false); err == nil || !strings.Contains(err.Error(), "--force") {
+			t.Fatalf("expected overwrite refusal, got %v", err)
+		}
+	})
+}
@@ -6,8 +6,6 @@ import (
 	"os"
 
 	"github.com/sa6mwa/ab/internal/az"
-	"github.com/sa6mwa/ab/internal/board"
-	"github.com/sa6mwa/ab/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -26,25 +24,7 @@ var backwardCmd = &cobra.Command{
 				return err
 			}
 		}
-		_, item, err := az.ShowWorkItem(id)
-		if err != nil {
-			return err
-		}
-		if item == nil {
-			return fmt.Errorf("unable to inspect work item %s", id)
-		}
-
-		colField, curCol := util.FindKanbanColumn(item.Fields)
-		if colField == "" || curCol == "" {
-			return fmt.Errorf("kanban column field not found on work item %s", id)
-		}
-		prevCol, err := board.PrevColumn(curCol)
-		if err != nil {
-			return err
-		}
-
-		fields := map[string]string{colField: prevCol}
-		raw, err := az.UpdateWorkItemFields(id, fields)
+		curCol, prevCol, raw, err := moveColumn(id, -1)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/kanban"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var boardMine bool
+var boardAll bool
+
+var boardCmd = &cobra.Command{
+	Use:   "board",
+	Short: "Interactive full-screen Kanban board",
+	Long: "Show the team board full-screen with a card (ID, type, assignee, title) per work-item in its Kanban column.\n" +
+		"Keys: ←/→ (h/l) select column, ↑/↓ (j/k) select card, H/L or shift+←/→ move the card back/forward " +
+		"(like `ab backward`/`ab forward`), enter opens the card as `ab show`, m toggles your own cards, r refreshes, q quits.\n" +
+		"Moves are confirmed inside the board unless --confirm never (or -y) is in effect.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if structured() {
+			return fmt.Errorf("board is interactive; use `ab list --format %s` instead", formatFlag)
+		}
+		opts := kanban.Options{
+			Columns: board.ColumnsFor("User Story"),
+			Mine:    boardMine,
+			Confirm: az.CurrentConfirmMode() != az.ConfirmNever,
+		}
+		// The board owns the screen: az command lines and prompts would
+		// tear it, so it asks before moves itself.
+		defer az.WithConfirmMode(az.ConfirmNever)()
+		defer az.SetSilent(silentFlag)
+		az.SetSilent(true)
+		return kanban.Run(boardSource{all: boardAll}, opts)
+	},
+}
+
+// boardComments is how many of the latest comments the card view shows.
+const boardComments = 3
+
+// boardSource serves the board from Azure DevOps.
+type boardSource struct{ all bool }
+
+func (s boardSource) Cards() ([]kanban.Card, error) {
+	items, err := queryItemsWithOrder("", s.all, poOrderGlobal)
+	if err != nil {
+		return nil, err
+	}
+	me, _ := az.CurrentUserDisplayName()
+	cards := make([]kanban.Card, 0, len(items))
+	for _, it := range items {
+		c := cardFrom(output.FromFields(it.ID, it.Fields), me)
+		// Only items on a board have a column; tasks live on the taskboard.
+		if c.Column != "" {
+			cards = append(cards, c)
+		}
+	}
+	return cards, nil
+}
+
+func (s boardSource) Move(id, delta int) (kanban.Card, error) {
+	_, _, raw, err := moveColumn(strconv.Itoa(id), delta)
+	if err != nil {
+		return kanban.Card{}, err
+	}
+	var wi az.WorkItem
+	if err := json.Unmarshal(raw, &wi); err != nil {
+		return kanban.Card{}, fmt.Errorf("decode work item %d: %w", id, err)
+	}
+	me, _ := az.CurrentUserDisplayName()
+	return cardFrom(output.FromWorkItem(&wi), me), nil
+}
+
+func (s boardSource) Show(id, width int) (string, error) {
+	_, wi, err := az.ShowWorkItem(strconv.Itoa(id))
+	if err != nil {
+		return "", err
+	}
+	if wi == nil {
+		return "", fmt.Errorf("unable to inspect work item %d", id)
+	}
+	children, err := showChildren(wi, false)
+	if err != nil {
+		return "", err
+	}
+	comments, err := az.Comments(strconv.Itoa(id), boardComments)
+	if err != nil {
+		return "", err
+	}
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(width),
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return "", err
+	}
+	return r.Render(showMarkdown(wi, children, comments, true))
+}
+
+func cardFrom(it output.Item, me string) kanban.Card {
+	return kanban.Card{
+		ID:       it.ID,
+		Type:     it.Type,
+		Assignee: it.Assignee,
+		Title:    it.Title,
+		Column:   it.Column,
+		Mine:     me != "" && strings.EqualFold(it.Assignee, me),
+	}
+}
+
+func init() {
+	boardCmd.Flags().BoolVarP(&boardMine, "mine", "m", false, "Start with only cards assigned to me")
+	boardCmd.Flags().BoolVarP(&boardAll, "all", "a", false, "Include Closed work-items")
+	rootCmd.AddCommand(boardCmd)
+}
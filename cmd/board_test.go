@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_BoardSourceCardsMoveAndShow(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Story", map[string]any{"System.AssignedTo": "me@example.com"})
+		p.Add("User Story", "Other story", nil)
+		task := p.Add("Task", "Task", nil)
+		_ = p.SetParent(task, story)
+		feature := p.Add("Feature", "Checkout", nil)
+		_ = p.SetParent(story, feature)
+
+		src := boardSource{}
+		cards, err := src.Cards()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cards) != 2 {
+			t.Fatalf("cards = %+v, want the two stories only", cards)
+		}
+		if !cards[0].Mine && !cards[1].Mine {
+			t.Fatalf("own story not marked mine: %+v", cards)
+		}
+		card, err := src.Move(story, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if card.Column != "Ready for Development" || p.Field(story, fake.KanbanField) != card.Column {
+			t.Fatalf("moved card = %+v, board has %q", card, p.Field(story, fake.KanbanField))
+		}
+		doc, err := src.Show(story, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(doc, "Story") || !strings.Contains(doc, "Children") {
+			t.Fatalf("show document lacks title or children:\n%s", doc)
+		}
+		doc, err = src.Show(feature, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(doc, "0/2 done") || !strings.Contains(doc, "Story") {
+			t.Fatalf("feature document lacks the hierarchy below it:\n%s", doc)
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/term"
+	"github.com/sa6mwa/ab/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bulkFailFast bool
+	bulkParallel int
+)
+
+// bulkWorkers is how many work-items a bulk command changes at a time
+// unless --parallel says otherwise.
+const bulkWorkers = 4
+
+// errSkipped is the result of the ids a --fail-fast run never started.
+var errSkipped = errors.New("not attempted after an earlier failure")
+
+// addBulkFlags registers the flags shared by the bulk commands.
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&bulkFailFast, "fail-fast", false, "Stop at the first failure instead of continuing with the other work-items")
+	cmd.Flags().IntVar(&bulkParallel, "parallel", bulkWorkers, "How many work-items to change at the same time")
+}
+
+// bulkIDs returns the id argument or, without one, the picked ids.
+func bulkIDs(args []string) ([]string, error) {
+	if len(args) == 1 {
+		return []string{args[0]}, nil
+	}
+	return pickNonClosedIDs()
+}
+
+// bulkResult is the outcome of a bulk action on one id.
+type bulkResult struct {
+	id  string
+	raw []byte
+	err error
+}
+
+// runBulk runs action for every id on a bounded pool of workers and returns
+// the results in the order of ids. Failures do not stop the others unless
+// --fail-fast is given (or a prompt is aborted); ids not started then fail
+// with errSkipped.
+func runBulk(verb string, ids []string, action func(id string) ([]byte, error)) []bulkResult {
+	results := make([]bulkResult, len(ids))
+	prog := newProgress(verb, len(ids))
+	var stop sync.Once
+	stopped := make(chan struct{})
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(bulkParallel, 1), len(ids)) {
+		wg.Go(func() {
+			for i := range next {
+				raw, err := action(ids[i])
+				results[i] = bulkResult{id: ids[i], raw: raw, err: err}
+				prog.step(err != nil)
+				if err != nil && (bulkFailFast || errors.Is(err, huh.ErrUserAborted)) {
+					stop.Do(func() { close(stopped) })
+				}
+			}
+		})
+	}
+	i := 0
+dispatch:
+	for ; i < len(ids); i++ {
+		select {
+		case <-stopped:
+			break dispatch
+		default:
+		}
+		select {
+		case next <- i:
+		case <-stopped:
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+	prog.clear()
+	for ; i < len(ids); i++ {
+		results[i] = bulkResult{id: ids[i], err: errSkipped}
+	}
+	return results
+}
+
+// reportBulk renders the succeeded results with render (as records with
+// action when structured), then, for several ids or any failure, a
+// summary; it returns an error when anything failed. A single id that
+// failed returns its own error.
+func reportBulk(results []bulkResult, items map[string]az.WorkItem, action string, render func(id string, raw []byte) error) error {
+	if len(results) == 1 && results[0].err != nil {
+		return results[0].err
+	}
+	var recs []output.Item
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			if structured() {
+				wi := items[r.id]
+				rec := output.FromWorkItem(&wi)
+				rec.Action, rec.Error = bulkOutcome(r), r.err.Error()
+				recs = append(recs, rec)
+			}
+			continue
+		}
+		if structured() {
+			recs = append(recs, mutationRecord(r.id, r.raw, action))
+			continue
+		}
+		if err := render(r.id, r.raw); err != nil {
+			return err
+		}
+	}
+	if structured() {
+		if err := emitItems(recs, ""); err != nil {
+			return err
+		}
+	} else if len(results) > 1 {
+		if err := renderMarkdown(bulkSummaryMarkdown(results, items, action)); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d work-items failed", failed, len(results))
+	}
+	return nil
+}
+
+// bulkOutcome names the result of r for the summary.
+func bulkOutcome(r bulkResult) string {
+	switch {
+	case r.err == nil:
+		return "ok"
+	case errors.Is(r.err, errSkipped):
+		return "skipped"
+	}
+	return "failed"
+}
+
+// bulkSummaryMarkdown tabulates results with their titles and errors.
+func bulkSummaryMarkdown(results []bulkResult, items map[string]az.WorkItem, action string) string {
+	var b strings.Builder
+	ok := 0
+	for _, r := range results {
+		if r.err == nil {
+			ok++
+		}
+	}
+	fmt.Fprintf(&b, "# Summary\n\n%d of %d work-items %s.\n\n", ok, len(results), action)
+	b.WriteString("| ID | Title | Result | Error |\n|---:|:------|:-------|:------|\n")
+	for _, r := range results {
+		title := util.FieldString(items[r.id].Fields, "System.Title")
+		msg := ""
+		if r.err != nil {
+			msg = strings.Join(strings.Fields(r.err.Error()), " ")
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", r.id, tableCell(title), bulkOutcome(r), tableCell(msg))
+	}
+	return b.String()
+}
+
+// tableCell escapes s for a Markdown table cell.
+func tableCell(s string) string { return strings.ReplaceAll(s, "|", "\\|") }
+
+// progress keeps a "Closing 3/30, 1 failed" line on stderr while a bulk
+// command runs. It is only drawn on a terminal and when no prompts are
+// expected; the cursor is left at the start of the line so that printed
+// az command lines write over it.
+type progress struct {
+	mu                  sync.Mutex
+	verb                string
+	total, done, failed int
+	show                bool
+}
+
+func newProgress(verb string, total int) *progress {
+	p := &progress{
+		verb:  verb,
+		total: total,
+		show:  total > 1 && az.CurrentConfirmMode() == az.ConfirmNever && term.IsTerminal(os.Stderr),
+	}
+	p.draw()
+	return p
+}
+
+// step counts a finished id.
+func (p *progress) step(failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	p.drawLocked()
+}
+
+func (p *progress) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.drawLocked()
+}
+
+func (p *progress) drawLocked() {
+	if !p.show {
+		return
+	}
+	line := fmt.Sprintf("%s %d/%d", p.verb, p.done, p.total)
+	if p.failed > 0 {
+		line += fmt.Sprintf(", %d failed", p.failed)
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s\r", line)
+}
+
+// clear erases the progress line.
+func (p *progress) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.show {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+// failingBackend fails the updates of one work item.
+type failingBackend struct {
+	*fake.Project
+	id string
+}
+
+func (f failingBackend) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	if id == f.id {
+		return nil, fmt.Errorf("TF26071: work item %s | changed by someone else", id)
+	}
+	return f.Project.UpdateWorkItemFields(id, fields)
+}
+
+func TestFake_BulkContinuesOnErrorAndSummarizes(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		var ids []string
+		for i := range 6 {
+			ids = append(ids, strconv.Itoa(p.Add("Task", fmt.Sprintf("Task %d", i), nil)))
+		}
+		defer azpkg.Use(failingBackend{Project: p, id: ids[2]})()
+		closeAll := func(id string) ([]byte, error) {
+			return azpkg.UpdateWorkItemFields(id, map[string]string{"System.State": "Closed"})
+		}
+		cur, err := fetchItems(ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results := runBulk("Closing", ids, closeAll)
+		for i, r := range results {
+			n, _ := strconv.Atoi(ids[i])
+			if r.id != ids[i] || (i == 2) != (r.err != nil) {
+				t.Fatalf("result %d = %+v", i, r)
+			}
+			if want := map[bool]string{true: "New", false: "Closed"}[i == 2]; p.Field(n, "System.State") != want {
+				t.Fatalf("AB#%d state = %s, want %s", n, p.Field(n, "System.State"), want)
+			}
+		}
+
+		formatFlag = output.JSON
+		defer func() { formatFlag = "" }()
+		var recs []output.Item
+		var reportErr error
+		out := captureStdout(t, func() error {
+			reportErr = reportBulk(results, cur, "closed", nil)
+			return nil
+		})
+		if reportErr == nil || reportErr.Error() != "1 of 6 work-items failed" {
+			t.Fatalf("report error = %v", reportErr)
+		}
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("summary is not JSON: %v\n%s", err, out)
+		}
+		if len(recs) != 6 || recs[2].Action != "failed" || !strings.Contains(recs[2].Error, "TF26071") || recs[2].Title != "Task 2" || recs[0].Action != "closed" {
+			t.Fatalf("records = %s", out)
+		}
+		md := bulkSummaryMarkdown(results, cur, "closed")
+		if !strings.Contains(md, "5 of 6 work-items closed") || !strings.Contains(md, `work item `+ids[2]+` \| changed`) {
+			t.Fatalf("summary = %s", md)
+		}
+
+		for _, id := range ids {
+			if _, err := p.UpdateWorkItemFields(id, map[string]string{"System.State": "New"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		bulkFailFast, bulkParallel = true, 1
+		defer func() { bulkFailFast, bulkParallel = false, bulkWorkers }()
+		results = runBulk("Closing", ids, closeAll)
+		for i, r := range results {
+			switch {
+			case i < 2 && r.err != nil, i == 2 && r.err == nil, i > 2 && !errors.Is(r.err, errSkipped):
+				t.Fatalf("fail-fast result %d = %+v", i, r)
+			}
+		}
+		if n, _ := strconv.Atoi(ids[5]); p.Field(n, "System.State") != "New" {
+			t.Fatal("fail-fast changed an item after the failure")
+		}
+	})
+}
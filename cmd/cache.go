@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/spf13/cobra"
+)
+
+var cacheClearAll bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the disk cache",
+	Long: "ab caches the signed-in identity, board columns, the repo list, work item snapshots and picker queries per organization/project " +
+		"under the user cache directory ($XDG_CACHE_HOME/ab, e.g. ~/.cache/ab). Pickers open from the cache and refresh it in the background. " +
+		"Use --no-cache (or AB_NO_CACHE=true) to bypass it.",
+}
+
+var cacheStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the cache directory and its entries for the current project",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		st, err := az.GetCacheStatus()
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		state := "enabled"
+		if !st.Enabled {
+			state = "disabled"
+		}
+		fmt.Fprintf(out, "Directory: %s (%s)\n", st.Dir, state)
+		if len(st.Entries) == 0 {
+			fmt.Fprintln(out, "Empty")
+			return nil
+		}
+		kinds := make([]string, 0, len(st.Entries))
+		for k := range st.Entries {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		for _, k := range kinds {
+			fmt.Fprintf(out, "  %-10s %d\n", k, st.Entries[k])
+		}
+		fmt.Fprintf(out, "Size:      %s\n", humanSize(st.Bytes))
+		fmt.Fprintf(out, "Oldest:    %s ago\n", time.Since(st.Oldest).Round(time.Second))
+		fmt.Fprintf(out, "Newest:    %s ago\n", time.Since(st.Newest).Round(time.Second))
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cache of the current project (--all for every project)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := az.ClearCache(cacheClearAll)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Cleared %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	cacheClearCmd.Flags().BoolVar(&cacheClearAll, "all", false, "Clear the cache of every organization and project")
+	cacheCmd.AddCommand(cacheStatusCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/term"
+	"github.com/spf13/cobra"
+)
+
+var commentsTop int
+
+var commentCmd = &cobra.Command{
+	Use:   "comment <id> [text]",
+	Short: "Add a comment to the Discussion of a work-item",
+	Long:  "Post a Markdown comment to the work-item's Discussion. Without text, a Markdown text area opens.",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(args[0])
+		text := strings.TrimSpace(strings.Join(args[1:], " "))
+		if text == "" {
+			heading := fmt.Sprintf("Comment on %s: %s", id, parentTitleByID(id))
+			area := huh.NewText().Title("Comment (Markdown)").Description(heading).Lines(10).Value(&text)
+			if err := huh.NewForm(huh.NewGroup(area)).Run(); err != nil {
+				return err
+			}
+			text = strings.TrimSpace(text)
+		}
+		if text == "" {
+			return fmt.Errorf("empty comment; nothing posted")
+		}
+		c, err := az.AddComment(id, markdownToHTML(text))
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return emitRecords(output.CommentColumns, []output.Comment{commentRecord(*c)}, "")
+		}
+		fmt.Fprintf(os.Stderr, "Added comment %d to %s\n", c.ID, id)
+		return nil
+	},
+}
+
+var commentsCmd = &cobra.Command{
+	Use:   "comments <id>",
+	Short: "Show the Discussion of a work-item",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(args[0])
+		comments, err := az.Comments(id, commentsTop)
+		if err != nil {
+			return err
+		}
+		if structured() {
+			recs := make([]output.Comment, 0, len(comments))
+			for _, c := range comments {
+				recs = append(recs, commentRecord(c))
+			}
+			return emitRecords(output.CommentColumns, recs, "")
+		}
+		var b bytes.Buffer
+		fmt.Fprintf(&b, "# Discussion AB#%s\n\n", id)
+		b.WriteString(commentsMarkdown(comments))
+		r, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(term.DetectWidth()),
+			glamour.WithPreservedNewLines(),
+		)
+		if err != nil {
+			return err
+		}
+		out, err := r.Render(b.String())
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+		return nil
+	},
+}
+
+// commentsMarkdown renders comments, oldest first, as Markdown paragraphs
+// headed by author and date.
+func commentsMarkdown(comments []az.Comment) string {
+	if len(comments) == 0 {
+		return "No comments.\n"
+	}
+	var b strings.Builder
+	for i, c := range comments {
+		if i > 0 {
+			b.WriteString("---\n\n")
+		}
+		fmt.Fprintf(&b, "**%s** · %s  \n", commentAuthor(c), commentDate(c.CreatedDate))
+		text := htmlToMarkdown(c.Text)
+		if text == "" {
+			text = "NIL"
+		}
+		fmt.Fprintf(&b, "%s\n\n", text)
+	}
+	return b.String()
+}
+
+func commentAuthor(c az.Comment) string {
+	if c.CreatedBy.DisplayName != "" {
+		return c.CreatedBy.DisplayName
+	}
+	if c.CreatedBy.UniqueName != "" {
+		return c.CreatedBy.UniqueName
+	}
+	return "(unknown)"
+}
+
+func commentDate(t time.Time) string {
+	if t.IsZero() {
+		return "(no date)"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func commentRecord(c az.Comment) output.Comment {
+	created := ""
+	if !c.CreatedDate.IsZero() {
+		created = c.CreatedDate.UTC().Format(time.RFC3339)
+	}
+	return output.Comment{
+		ID:      c.ID,
+		Item:    c.WorkItemID,
+		Author:  commentAuthor(c),
+		Created: created,
+		Text:    htmlToMarkdown(c.Text),
+	}
+}
+
+func init() {
+	commentsCmd.Flags().IntVarP(&commentsTop, "top", "n", 0, "Only the latest N comments (0 for all)")
+	rootCmd.AddCommand(commentCmd, commentsCmd)
+}
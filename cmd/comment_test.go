@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_CommentsOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		id := p.Add("User Story", "Story", nil)
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+
+		for _, text := range []string{"First **note**", "Second", "Third"} {
+			if err := commentCmd.RunE(commentCmd, []string{"1", text}); err != nil {
+				t.Fatalf("comment: %v", err)
+			}
+		}
+		if got := p.Field(id, "System.CommentCount"); got != "3" {
+			t.Fatalf("CommentCount = %q", got)
+		}
+
+		formatFlag = output.JSON
+		defer func() { formatFlag = "" }()
+		var recs []output.Comment
+		out := captureStdout(t, func() error { return commentsCmd.RunE(commentsCmd, []string{"1"}) })
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("comments output is not JSON: %v\n%s", err, out)
+		}
+		if len(recs) != 3 || recs[0].Text != "First **note**" || recs[0].Author != p.Me.DisplayName || recs[2].Item != id {
+			t.Fatalf("comments = %+v", recs)
+		}
+
+		formatFlag = ""
+		_, wi, err := azpkg.ShowWorkItem("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		latest, err := azpkg.Comments("1", 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := showMarkdown(wi, nil, latest, true)
+		if !strings.Contains(md, "# Discussion") || !strings.Contains(md, "Latest 2 of 3 comments") ||
+			strings.Contains(md, "First") || strings.Index(md, "Second") > strings.Index(md, "Third") {
+			t.Fatalf("show discussion section:\n%s", md)
+		}
+	})
+}
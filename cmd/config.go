@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/config"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var profileFlag string
+
+// defaultAssignee is the assignee for new work items when -a is not given
+// (AB_ASSIGNEE or the profile's assignee).
+var defaultAssignee = strings.TrimSpace(os.Getenv("AB_ASSIGNEE"))
+
+// withDefaultAssignee fills an empty assignee flag value with defaultAssignee.
+func withDefaultAssignee(flag *string) {
+	if strings.TrimSpace(*flag) == "" {
+		*flag = defaultAssignee
+	}
+}
+
+// applyProfile applies the active profile's settings that were not given as
+// a flag or env var. Precedence is flag > env > profile > az defaults.
+func applyProfile(cmd *cobra.Command) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	name := cfg.Active(profileFlag)
+	p := cfg.Profile(name)
+	if p == nil {
+		if profileFlag != "" || os.Getenv("AB_PROFILE") != "" {
+			return fmt.Errorf("profile %q not found in %s", name, cfg.File())
+		}
+		return nil
+	}
+	unset := func(flag string, envs ...string) bool {
+		if flag != "" && cmd.Flags().Changed(flag) {
+			return false
+		}
+		for _, e := range envs {
+			if strings.TrimSpace(os.Getenv(e)) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	if p.Organization != "" && unset("", "AB_ORGANIZATION") {
+		az.SetScope(p.Organization, "")
+	}
+	if p.Project != "" && unset("", "AB_PROJECT") {
+		az.SetScope("", p.Project)
+	}
+	if p.Team != "" && unset("", "AB_TEAM") {
+		az.SetTeam(p.Team)
+	}
+	if len(p.Columns) > 0 && unset("default-columns", "AB_COLUMNS") {
+		if err := board.SetColumnOrderFromCSV(strings.Join(p.Columns, ",")); err != nil {
+			return err
+		}
+	}
+	if p.Confirm != "" && unset("confirm", "AB_CONFIRM") && !yesFlag {
+		if err := az.SetConfirmMode(p.Confirm); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	if p.POOrder != nil && unset("po-order", "AB_PO_ORDER", "AB_STACKRANK") {
+		poOrderGlobal = *p.POOrder
+	}
+	if p.Assignee != "" && unset("", "AB_ASSIGNEE") {
+		defaultAssignee = p.Assignee
+	}
+	if p.Format != "" && unset("format", "AB_FORMAT") {
+		formatFlag = p.Format
+	}
+	if p.Backend != "" && unset("backend", "AB_BACKEND") {
+		if err := az.SetBackend(p.Backend); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	if p.Auth != "" && unset("auth", "AB_AUTH") {
+		if err := az.SetAuthMode(p.Auth); err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateSetting rejects values the corresponding flag would reject,
+// without applying them.
+func validateSetting(key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var err error
+	switch strings.ToLower(key) {
+	case "confirm":
+		_, err = az.ParseConfirmMode(value)
+	case "format":
+		_, err = output.Normalize(value)
+	case "backend":
+		_, err = az.ParseBackend(value)
+	case "auth":
+		_, err = az.ParseAuthMode(value)
+	}
+	return err
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write settings of the active profile",
+	Long: "Read and write settings in ~/.config/ab/config.yaml (honors XDG_CONFIG_HOME and AB_CONFIG).\n" +
+		"Settings apply to the active profile: --profile, else AB_PROFILE, else the current profile (see `ab profile use`), else \"default\".\n" +
+		"Keys: " + strings.Join(config.Keys, ", "),
+	Annotations: map[string]string{"skipProfile": "true"},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		v, err := cfg.Get(cfg.Active(profileFlag), args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), v)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> [value]",
+	Short: "Change a setting (omit value to unset)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		value := ""
+		if len(args) == 2 {
+			value = args[1]
+		}
+		if err := validateSetting(args[0], value); err != nil {
+			return err
+		}
+		name := cfg.Active(profileFlag)
+		if err := cfg.Set(name, args[0], value); err != nil {
+			return err
+		}
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s in profile %s (%s)\n", args[0], name, cfg.File())
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the settings of the active profile",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		name := cfg.Active(profileFlag)
+		fmt.Fprintf(os.Stderr, "# profile %s (%s)\n", name, cfg.File())
+		for _, k := range config.Keys {
+			v, _ := cfg.Get(name, k)
+			fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", k, v)
+		}
+		return nil
+	},
+}
+
+var profileCmd = &cobra.Command{
+	Use:         "profile",
+	Short:       "Manage named configuration profiles",
+	Annotations: map[string]string{"skipProfile": "true"},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the current one (creating it if needed)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		cfg.Use(args[0])
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Using profile %s (%s)\n", args[0], cfg.File())
+		return nil
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles; the active one is marked with *",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		active := cfg.Active(profileFlag)
+		for _, n := range cfg.Names() {
+			mark := " "
+			if n == active {
+				mark = "*"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", mark, n)
+		}
+		return nil
+	},
+}
+
+// skipsProfile reports whether cmd manages the config itself, in which case
+// a broken profile must not prevent it from running.
+func skipsProfile(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations["skipProfile"] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd)
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileUseCmd, profileListCmd)
+}
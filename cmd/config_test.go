@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Flag > env > profile for the settings a profile can carry.
+func TestApplyProfile_Precedence(t *testing.T) {
+	t.Setenv("AB_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("AB_PROFILE", "")
+	t.Setenv("AB_FORMAT", "")
+	t.Setenv("AB_COLUMNS", "")
+	t.Setenv("AB_ASSIGNEE", "")
+	t.Setenv("AB_PO_ORDER", "")
+	t.Setenv("AB_STACKRANK", "")
+	cfg, _ := config.Load()
+	cfg.Use("work")
+	for k, v := range map[string]string{"format": "csv", "columns": "A,B", "assignee": "@me", "po_order": "true"} {
+		_ = cfg.Set("work", k, v)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	defer board.ResetColumnOrder()
+	defer func() { formatFlag, defaultAssignee, poOrderGlobal = "", "", false }()
+	defer azpkg.SetConfirmMode("never")
+
+	newCmd := func() *cobra.Command {
+		c := &cobra.Command{Use: "x"}
+		c.Flags().StringVarP(&formatFlag, "format", "F", "", "")
+		return c
+	}
+
+	// Profile applies where nothing else is set.
+	formatFlag, defaultAssignee, poOrderGlobal = "", "", false
+	if err := applyProfile(newCmd()); err != nil {
+		t.Fatal(err)
+	}
+	if formatFlag != "csv" || defaultAssignee != "@me" || !poOrderGlobal || !board.Overridden() || board.ColumnOrder[1] != "B" {
+		t.Fatalf("profile not applied: format=%q assignee=%q po=%v cols=%v", formatFlag, defaultAssignee, poOrderGlobal, board.ColumnOrder)
+	}
+
+	// Env beats profile.
+	board.ResetColumnOrder()
+	c := newCmd()
+	formatFlag = "json" // flag default taken from AB_FORMAT
+	t.Setenv("AB_FORMAT", "json")
+	t.Setenv("AB_COLUMNS", "X,Y")
+	if err := applyProfile(c); err != nil {
+		t.Fatal(err)
+	}
+	if formatFlag != "json" || board.Overridden() {
+		t.Fatalf("env should win: format=%q overridden=%v", formatFlag, board.Overridden())
+	}
+
+	// Flag beats env and profile.
+	t.Setenv("AB_FORMAT", "")
+	c = newCmd()
+	_ = c.Flags().Set("format", "ids")
+	if err := applyProfile(c); err != nil {
+		t.Fatal(err)
+	}
+	if formatFlag != "ids" {
+		t.Fatalf("flag should win: format=%q", formatFlag)
+	}
+
+	// Unknown explicit profile is an error.
+	profileFlag = "nope"
+	defer func() { profileFlag = "" }()
+	if err := applyProfile(newCmd()); err == nil {
+		t.Fatal("expected error for missing profile")
+	}
+}
+
+// Setting a value validates it without applying it to the running process.
+func TestConfigSet_LeavesProcessStateAlone(t *testing.T) {
+	t.Setenv("AB_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("AB_PROFILE", "")
+	confirm, backend, auth := azpkg.CurrentConfirmMode(), azpkg.CurrentBackend(), azpkg.CurrentAuthMode()
+	for _, kv := range [][]string{{"confirm", "always"}, {"backend", "rest"}, {"auth", "pat"}} {
+		if err := configSetCmd.RunE(configSetCmd, kv); err != nil {
+			t.Fatalf("config set %s: %v", kv[0], err)
+		}
+	}
+	if got := azpkg.CurrentConfirmMode(); got != confirm {
+		t.Fatalf("confirm mode changed to %v", got)
+	}
+	if got := azpkg.CurrentBackend(); got != backend {
+		t.Fatalf("backend changed to %s", got)
+	}
+	if got := azpkg.CurrentAuthMode(); got != auth {
+		t.Fatalf("auth mode changed to %q", got)
+	}
+	if err := configSetCmd.RunE(configSetCmd, []string{"backend", "carrier-pigeon"}); err == nil {
+		t.Fatal("expected an invalid backend to be rejected")
+	}
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+// editConflict is a field changed in the edit form, compared three ways:
+// when the form opened (original), in the latest revision (theirs) and as
+// edited (mine). Values are as the form shows them, Markdown for HTML
+// fields.
+type editConflict struct {
+	ref, label             string
+	original, theirs, mine string
+	html                   bool
+}
+
+// both reports whether the field was changed differently by someone else.
+func (c editConflict) both() bool { return c.theirs != c.original && c.theirs != c.mine }
+
+// status describes who changed the field.
+func (c editConflict) status() string {
+	switch {
+	case c.theirs == c.original:
+		return "changed by you"
+	case c.theirs == c.mine:
+		return "same change by both"
+	}
+	return "changed by both"
+}
+
+// editResolution is how to go on after a conflict: reapply all my changes,
+// merge them (taking mine for the conflicting fields in keepMine) or abort.
+type editResolution struct {
+	action   string
+	keepMine map[string]bool
+}
+
+// resolveEdit asks how to resolve a conflict; tests replace it.
+var resolveEdit = promptEditResolution
+
+// applyEdit writes the fields changed in the edit form, provided the work
+// item is still at the revision orig was read at. When someone changed it
+// meanwhile, the fields are compared three ways and the changes are
+// re-applied, merged or abandoned as chosen.
+func applyEdit(id string, orig *az.WorkItem, mine map[string]string) ([]byte, error) {
+	rev := orig.Rev
+	az.NoteCurrent(*orig)
+	for {
+		raw, err := az.UpdateWorkItemFieldsAt(id, rev, mine)
+		if !errors.Is(err, az.ErrConflict) {
+			return raw, err
+		}
+		_, theirs, err := az.ShowWorkItem(id)
+		if err != nil {
+			return nil, err
+		}
+		if theirs == nil {
+			return nil, fmt.Errorf("unable to inspect work item %s", id)
+		}
+		az.NoteCurrent(*theirs)
+		rows := compareEdit(orig, theirs, mine)
+		if err := renderMarkdown(conflictMarkdown(id, orig, theirs, rows)); err != nil {
+			return nil, err
+		}
+		res, err := resolveEdit(rows)
+		if err != nil {
+			return nil, err
+		}
+		if res.action == "abort" {
+			return nil, fmt.Errorf("edit of AB#%s aborted: it changed from revision %d to %d while editing", id, orig.Rev, theirs.Rev)
+		}
+		mine = resolvedFields(rows, mine, res)
+		if len(mine) == 0 {
+			return json.Marshal(theirs)
+		}
+		rev = theirs.Rev
+	}
+}
+
+// compareEdit lines up the fields in mine with their values in orig and
+// theirs, in history order.
+func compareEdit(orig, theirs *az.WorkItem, mine map[string]string) []editConflict {
+	rows := make([]editConflict, 0, len(mine))
+	for ref, v := range mine {
+		label, _ := historyLabel(ref)
+		rows = append(rows, editConflict{
+			ref:      ref,
+			label:    label,
+			original: editValue(orig.Fields, ref),
+			theirs:   editValue(theirs.Fields, ref),
+			mine:     sentValue(ref, v),
+			html:     historyHTMLFields[ref],
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		_, pi := historyLabel(rows[i].ref)
+		_, pj := historyLabel(rows[j].ref)
+		if pi != pj {
+			return pi < pj
+		}
+		return rows[i].ref < rows[j].ref
+	})
+	return rows
+}
+
+// editValue is the value of ref in fields as the edit form shows it.
+func editValue(fields map[string]any, ref string) string {
+	return sentValue(ref, historyValue(fields[ref]))
+}
+
+// sentValue is v as the edit form shows it: Markdown for HTML fields.
+func sentValue(ref, v string) string {
+	if historyHTMLFields[ref] {
+		return htmlToMarkdown(v)
+	}
+	return strings.TrimSpace(v)
+}
+
+// resolvedFields returns the fields to write after a conflict: all of mine
+// when re-applying; when merging, mine where only I changed the field and,
+// for fields changed by both, mine only where picked.
+func resolvedFields(rows []editConflict, mine map[string]string, res editResolution) map[string]string {
+	out := map[string]string{}
+	for _, r := range rows {
+		switch {
+		case res.action == "reapply":
+			out[r.ref] = mine[r.ref]
+		case r.theirs == r.mine:
+		case r.theirs == r.original || res.keepMine[r.ref]:
+			out[r.ref] = mine[r.ref]
+		}
+	}
+	return out
+}
+
+// theirChanges names the fields someone else changed that the form did not.
+func theirChanges(orig, theirs *az.WorkItem, rows []editConflict) []string {
+	mine := map[string]bool{}
+	for _, r := range rows {
+		mine[r.label] = true
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, fields := range []map[string]any{orig.Fields, theirs.Fields} {
+		for ref := range fields {
+			label, pos := historyLabel(ref)
+			if mine[label] || seen[label] || pos == len(historyLabels) {
+				continue
+			}
+			if editValue(orig.Fields, ref) != editValue(theirs.Fields, ref) {
+				seen[label] = true
+				out = append(out, label)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// conflictMarkdown shows the three-way comparison of rows.
+func conflictMarkdown(id string, orig, theirs *az.WorkItem, rows []editConflict) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# AB#%s changed while you were editing\n\n", id)
+	fmt.Fprintf(&b, "You opened revision %d; it is now at revision %d.", orig.Rev, theirs.Rev)
+	if others := theirChanges(orig, theirs, rows); len(others) > 0 {
+		fmt.Fprintf(&b, " They also changed %s, which your edit leaves alone.", strings.Join(others, ", "))
+	}
+	b.WriteString("\n\n")
+	for _, r := range rows {
+		fmt.Fprintf(&b, "## %s: %s\n\n", r.label, r.status())
+		if !r.html {
+			fmt.Fprintf(&b, "- **Original:** %s\n- **Theirs:** %s\n- **Mine:** %s\n\n", historyInline(r.original), historyInline(r.theirs), historyInline(r.mine))
+			continue
+		}
+		for _, side := range []struct{ name, value string }{{"Theirs", r.theirs}, {"Mine", r.mine}} {
+			if side.value == r.original {
+				fmt.Fprintf(&b, "- **%s:** unchanged\n\n", side.name)
+				continue
+			}
+			fmt.Fprintf(&b, "- **%s:**\n\n```diff\n", side.name)
+			for _, l := range lineDiff(r.original, side.value, 2) {
+				b.WriteString(l + "\n")
+			}
+			b.WriteString("```\n\n")
+		}
+	}
+	return b.String()
+}
+
+// promptEditResolution asks whether to re-apply, merge or abort and, when
+// merging, whose value wins for each field changed by both.
+func promptEditResolution(rows []editConflict) (editResolution, error) {
+	res := editResolution{action: "merge", keepMine: map[string]bool{}}
+	form := huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().Title("How do you want to continue?").Options(
+			huh.NewOption("Merge: keep their changes, add mine", "merge"),
+			huh.NewOption("Re-apply all my changes over theirs", "reapply"),
+			huh.NewOption("Abort, keep their version", "abort"),
+		).Value(&res.action),
+	))
+	if err := form.Run(); err != nil {
+		return res, err
+	}
+	if res.action != "merge" {
+		return res, nil
+	}
+	for _, r := range rows {
+		if !r.both() {
+			continue
+		}
+		var pick string
+		sel := huh.NewSelect[string]().Title(r.label+" was changed by both").Options(
+			huh.NewOption("Keep theirs", "theirs"),
+			huh.NewOption("Use mine", "mine"),
+		).Value(&pick)
+		if err := huh.NewForm(huh.NewGroup(sel)).Run(); err != nil {
+			return res, err
+		}
+		res.keepMine[r.ref] = pick == "mine"
+	}
+	return res, nil
+}
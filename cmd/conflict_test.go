@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_EditConflictOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		id := p.Add("User Story", "Checkout", map[string]any{"System.Description": "<p>one</p>"})
+		sid := strconv.Itoa(id)
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+		defer func() { resolveEdit = promptEditResolution }()
+
+		// edit reads the item, someone renames it, then edit writes.
+		conflict := func(theirs string) *azpkg.WorkItem {
+			t.Helper()
+			_, orig, err := azpkg.ShowWorkItem(sid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := p.UpdateWorkItemFields(sid, map[string]string{"System.Title": theirs}); err != nil {
+				t.Fatal(err)
+			}
+			return orig
+		}
+		mine := map[string]string{"System.Title": "Mine", "System.Description": "<p>two</p>"}
+
+		var rows []editConflict
+		resolveEdit = func(r []editConflict) (editResolution, error) {
+			rows = r
+			return editResolution{action: "merge"}, nil
+		}
+		orig := conflict("Theirs")
+		var md string
+		captureStdout(t, func() error {
+			md = conflictMarkdown(sid, orig, orig, nil)
+			_, err := applyEdit(sid, orig, mine)
+			return err
+		})
+		if !strings.Contains(md, "changed while you were editing") {
+			t.Fatalf("heading missing:\n%s", md)
+		}
+		if len(rows) != 2 || rows[0].label != "Title" || !rows[0].both() || rows[1].both() {
+			t.Fatalf("rows = %+v", rows)
+		}
+		if got := p.Field(id, "System.Title"); got != "Theirs" {
+			t.Fatalf("merge overwrote their title: %q", got)
+		}
+		if got := p.Field(id, "System.Description"); !strings.Contains(got, "two") {
+			t.Fatalf("merge dropped my description: %q", got)
+		}
+
+		resolveEdit = func([]editConflict) (editResolution, error) { return editResolution{action: "reapply"}, nil }
+		orig = conflict("Again theirs")
+		captureStdout(t, func() error { _, err := applyEdit(sid, orig, mine); return err })
+		if got := p.Field(id, "System.Title"); got != "Mine" {
+			t.Fatalf("reapply title = %q", got)
+		}
+
+		resolveEdit = func([]editConflict) (editResolution, error) { return editResolution{action: "abort"}, nil }
+		orig = conflict("Theirs at last")
+		captureStdout(t, func() error {
+			if _, err := applyEdit(sid, orig, mine); err == nil || !strings.Contains(err.Error(), "aborted") {
+				t.Fatalf("abort: %v", err)
+			}
+			return nil
+		})
+		if got := p.Field(id, "System.Title"); got != "Theirs at last" {
+			t.Fatalf("abort changed the title: %q", got)
+		}
+	})
+}
@@ -1,12 +1,77 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create work-items",
+	Long: "Create work-items with the subcommands, or with -f from a Markdown file in the format of edit --editor " +
+		"whose front matter also gives the type (User Story, Task, Bug, Feature or Epic), parent and area.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(createFile) == "" {
+			return cmd.Help()
+		}
+		if err := resolveCreateFlags(); err != nil {
+			return err
+		}
+		return createFromFile(createFile)
+	},
+}
+
+// createFile is --file of create: the work item is described by a Markdown
+// file in the edit --editor format.
+var createFile string
+
+// createIteration and createArea are --iteration and --area of the create
+// commands; resolveCreateFlags resolves them into createIterationPath and
+// createAreaPath before any form opens.
+var (
+	createIteration     string
+	createIterationPath string
+	createArea          string
+	createAreaPath      string
+)
+
+func resolveCreateFlags() error {
+	createIterationPath, createAreaPath = "", ""
+	if strings.TrimSpace(createIteration) != "" {
+		p, err := iterationPath(createIteration)
+		if err != nil {
+			return err
+		}
+		createIterationPath = p
+	}
+	if strings.TrimSpace(createArea) != "" {
+		p, err := areaPath(createArea)
+		if err != nil {
+			return err
+		}
+		createAreaPath = p
+	}
+	return nil
+}
+
+// withCreateFlags sets the iteration and area chosen with --iteration and
+// --area on fields.
+func withCreateFlags(fields map[string]string) {
+	if createIterationPath != "" {
+		fields["System.IterationPath"] = createIterationPath
+	}
+	if createAreaPath != "" {
+		fields["System.AreaPath"] = createAreaPath
+	}
 }
 
 func init() {
+	createCmd.PersistentFlags().StringVarP(&createIteration, "iteration", "i", "", "Put the new item in `iteration` (name, path or @current)")
+	_ = createCmd.RegisterFlagCompletionFunc("iteration", completeIterations)
+	createCmd.PersistentFlags().StringVar(&createArea, "area", "", "Put the new item in `area` (path or its last segment); forms preselect it")
+	_ = createCmd.RegisterFlagCompletionFunc("area", completeAreas)
+	createCmd.Flags().StringVarP(&createFile, "file", "f", "", "Create the work-item described by Markdown `file` with YAML front matter")
 	rootCmd.AddCommand(createCmd)
 }
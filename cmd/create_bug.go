@@ -22,6 +22,10 @@ var createBugCmd = &cobra.Command{
 	Short: "Create a Bug under a User Story",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		withDefaultAssignee(&bugAssignee)
+		if err := resolveCreateFlags(); err != nil {
+			return err
+		}
 		if len(args) == 0 {
 			return interactiveCreateBug()
 		}
@@ -30,7 +34,7 @@ var createBugCmd = &cobra.Command{
 		pid := strings.TrimSpace(bugParentID)
 		if pid == "" {
 			// Query non-Closed User Stories; honor PO order
-			items, err := queryItemsWithOrder("User Story", false, poOrderGlobal)
+			items, err := pickerItems("User Story")
 			if err != nil {
 				return err
 			}
@@ -93,6 +97,7 @@ var createBugCmd = &cobra.Command{
 		} else {
 			fields["Microsoft.VSTS.Common.Severity"] = "3 - Medium"
 		}
+		withCreateFlags(fields)
 		raw, err := az.CreateWorkItem("Bug", title, fields, "")
 		if err != nil {
 			return err
@@ -120,7 +125,7 @@ func init() {
 func interactiveCreateBug() error {
 	pid := strings.TrimSpace(bugParentID)
 	if pid == "" {
-		items, err := queryItemsWithOrder("User Story", false, poOrderGlobal)
+		items, err := pickerItems("User Story")
 		if err != nil {
 			return err
 		}
@@ -165,6 +170,7 @@ func interactiveCreateBug() error {
 	heading := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12")).Render(fmt.Sprintf("Adding Bug to %s: %s", pid, parentTitleByID(pid)))
 	fmt.Fprintln(os.Stderr, heading)
 	fmt.Fprintln(os.Stderr)
+	area := createAreaPath
 	var proceed bool
 	f := huh.NewForm(huh.NewGroup(
 		huh.NewInput().Title("Title").Value(&title).Validate(func(s string) error {
@@ -184,6 +190,7 @@ func interactiveCreateBug() error {
 			huh.NewOption("New", "New"), huh.NewOption("Active", "Active"), huh.NewOption("Resolved", "Resolved"), huh.NewOption("Closed", "Closed"),
 		).Value(&state),
 		huh.NewInput().Title("Assignee (Name or email)").Value(&assignee),
+		areaField(&area),
 		huh.NewText().Title("Description (Markdown)").Lines(8).Value(&descMD),
 		huh.NewConfirm().Title("Create Bug?").Value(&proceed),
 	))
@@ -204,6 +211,10 @@ func interactiveCreateBug() error {
 	if strings.TrimSpace(descMD) != "" {
 		fields["System.Description"] = markdownToHTML(descMD)
 	}
+	withCreateFlags(fields)
+	if strings.TrimSpace(area) != "" {
+		fields["System.AreaPath"] = area
+	}
 	raw, err := az.CreateWorkItem("Bug", title, fields, "")
 	if err != nil {
 		return err
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/spf13/cobra"
+)
+
+// epicAssignee, featureAssignee and featureParent are the flags of create
+// epic and create feature.
+var (
+	epicAssignee    string
+	featureAssignee string
+	featureParent   string
+)
+
+var createEpicCmd = &cobra.Command{
+	Use:   "epic [\"Title...\"]",
+	Short: "Create an Epic",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createPortfolioItem("Epic", args, epicAssignee, "")
+	},
+}
+
+var createFeatureCmd = &cobra.Command{
+	Use:   "feature [\"Title...\"]",
+	Short: "Create a Feature, optionally under an Epic",
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createPortfolioItem("Feature", args, featureAssignee, featureParent)
+	},
+}
+
+func init() {
+	createCmd.AddCommand(createEpicCmd)
+	createEpicCmd.Flags().StringVarP(&epicAssignee, "assign", "a", "", "Assign to user (use @me for yourself)")
+	createCmd.AddCommand(createFeatureCmd)
+	createFeatureCmd.Flags().StringVarP(&featureAssignee, "assign", "a", "", "Assign to user (use @me for yourself)")
+	createFeatureCmd.Flags().StringVarP(&featureParent, "parent", "p", "", "Parent Epic ID. If omitted, the form offers a picker.")
+}
+
+// parentTypes are the work item types a new item of each type may be
+// created under; a Feature's parent is optional, as is a story's.
+var parentTypes = map[string][]string{
+	"Feature":    {"Epic"},
+	"User Story": {"Feature", "Epic"},
+}
+
+// createPortfolioItem creates an Epic or Feature titled args[0], or from a
+// form when there is no title.
+func createPortfolioItem(wtype string, args []string, assignee, parent string) error {
+	withDefaultAssignee(&assignee)
+	if err := resolveCreateFlags(); err != nil {
+		return err
+	}
+	n := newItem{wtype: wtype, fields: map[string]string{}}
+	if p := strings.TrimPrefix(strings.TrimSpace(parent), "AB#"); p != "" {
+		if err := validateParent(p, parentTypes[wtype]...); err != nil {
+			return err
+		}
+		n.parent = p
+	}
+	if len(args) == 0 {
+		return interactiveCreatePortfolioItem(n, assignee)
+	}
+	n.title = args[0]
+	if err := setNewItemFields(n.fields, assignee, nil); err != nil {
+		return err
+	}
+	withCreateFlags(n.fields)
+	return finishCreate(n)
+}
+
+// interactiveCreatePortfolioItem asks for the title, assignee, area,
+// description and, unless given, the parent of n.
+func interactiveCreatePortfolioItem(n newItem, assignee string) error {
+	if strings.TrimSpace(assignee) == "@me" {
+		if me, err := az.CurrentUserUPN(); err == nil {
+			assignee = me
+		}
+	}
+	var descMD string
+	area := createAreaPath
+	var proceed bool
+	fields := []huh.Field{
+		huh.NewInput().Title("Title").Value(&n.title).Validate(func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("title is required")
+			}
+			return nil
+		}),
+	}
+	if n.parent == "" && len(parentTypes[n.wtype]) > 0 {
+		field, err := parentField(&n.parent, parentTypes[n.wtype]...)
+		if err != nil {
+			return err
+		}
+		if field != nil {
+			fields = append(fields, field)
+		}
+	}
+	fields = append(fields,
+		huh.NewInput().Title("Assignee (Name or email)").Value(&assignee),
+		areaField(&area),
+		huh.NewText().Title("Description (Markdown)").Lines(8).Value(&descMD),
+		huh.NewConfirm().Title("Create "+n.wtype+"?").Value(&proceed),
+	)
+	if err := huh.NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return err
+	}
+	if !proceed {
+		return fmt.Errorf("cancelled")
+	}
+	if err := setNewItemFields(n.fields, assignee, nil); err != nil {
+		return err
+	}
+	if strings.TrimSpace(descMD) != "" {
+		n.fields["System.Description"] = markdownToHTML(descMD)
+	}
+	withCreateFlags(n.fields)
+	if strings.TrimSpace(area) != "" {
+		n.fields["System.AreaPath"] = area
+	}
+	return finishCreate(n)
+}
+
+// finishCreate creates n and renders it, noting the parent it was linked to.
+func finishCreate(n newItem) error {
+	wi, err := createItem(n)
+	if err != nil {
+		return err
+	}
+	if n.parent != "" {
+		fmt.Fprintf(os.Stderr, "Linked AB#%d as child of AB#%s\n", wi.ID, n.parent)
+	}
+	return renderWorkItem(n.wtype+" Created", wi)
+}
+
+// validateParent checks that work item id is one of types.
+func validateParent(id string, types ...string) error {
+	_, wi, err := az.ShowWorkItem(id)
+	if err != nil {
+		return fmt.Errorf("validate parent: %w", err)
+	}
+	if wi == nil {
+		return fmt.Errorf("unable to inspect parent %s", id)
+	}
+	if t := utilField(wi.Fields, "System.WorkItemType"); !contains(types, t) {
+		return fmt.Errorf("parent %s is a %s; want %s", id, t, strings.Join(types, " or "))
+	}
+	return nil
+}
+
+// parentField is an optional picker of the non-Closed items of types, in
+// that order, for value; nil when there are none.
+func parentField(value *string, types ...string) (huh.Field, error) {
+	var items []queryItem
+	for _, t := range types {
+		its, err := pickerItems(t)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, its...)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	options := append([]huh.Option[string]{huh.NewOption("(none)", "")}, itemOptions(items)...)
+	height := len(options) + 2
+	if height > 10 {
+		height = 10
+	}
+	return huh.NewSelect[string]().Title("Parent " + strings.Join(types, " or ")).Options(options...).Height(height).Value(value), nil
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_EpicsAndFeatures(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		defer func() { epicAssignee, featureParent, storyParent, formatFlag = "", "", "", "" }()
+		ids := func() []int { return p.IDs() }
+
+		epicAssignee = "@me"
+		captureStdout(t, func() error { return createEpicCmd.RunE(createEpicCmd, []string{"Shop"}) })
+		epic := ids()[len(ids())-1]
+		if got := p.Field(epic, "System.WorkItemType"); got != "Epic" || p.Field(epic, "System.AssignedTo") != "Me User" {
+			t.Fatalf("epic = %q assigned to %q", got, p.Field(epic, "System.AssignedTo"))
+		}
+
+		featureParent = "AB#" + strconv.Itoa(epic)
+		captureStdout(t, func() error { return createFeatureCmd.RunE(createFeatureCmd, []string{"Payments"}) })
+		feature := ids()[len(ids())-1]
+		if got := p.Field(feature, "System.Parent"); got != strconv.Itoa(epic) {
+			t.Fatalf("feature parent = %q", got)
+		}
+
+		storyParent = strconv.Itoa(feature)
+		captureStdout(t, func() error { return createStoryCmd.RunE(createStoryCmd, []string{"Checkout"}) })
+		story := ids()[len(ids())-1]
+		if got := p.Field(story, "System.Parent"); got != strconv.Itoa(feature) {
+			t.Fatalf("story parent = %q", got)
+		}
+		storyParent = strconv.Itoa(story)
+		if err := createStoryCmd.RunE(createStoryCmd, []string{"Nested"}); err == nil || !strings.Contains(err.Error(), "is a User Story; want Feature or Epic") {
+			t.Fatalf("story under a story: %v", err)
+		}
+		featureParent = strconv.Itoa(feature)
+		if err := createFeatureCmd.RunE(createFeatureCmd, []string{"Nested"}); err == nil || !strings.Contains(err.Error(), "is a Feature; want Epic") {
+			t.Fatalf("feature under a feature: %v", err)
+		}
+
+		done := p.Add("Task", "Card form", map[string]any{"System.State": "Closed"})
+		open := p.Add("Task", "Luhn check", nil)
+		for _, id := range []int{done, open} {
+			if err := p.SetParent(id, story); err != nil {
+				t.Fatal(err)
+			}
+		}
+		out := captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{strconv.Itoa(epic)}) })
+		for _, want := range []string{"Progress:", "1/4 done (25%)", "Payments", "1/3"} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("show epic lacks %q:\n%s", want, out)
+			}
+		}
+
+		formatFlag = output.JSON
+		out = captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{strconv.Itoa(feature)}) })
+		var rec output.Item
+		if err := json.Unmarshal([]byte(out), &rec); err != nil {
+			t.Fatalf("json: %v\n%s", err, out)
+		}
+		if len(rec.Children) != 1 || rec.Children[0].ID != story || len(rec.Children[0].Children) != 1 || rec.Children[0].Children[0].ID != open {
+			t.Fatalf("show feature json = %s", out)
+		}
+	})
+}
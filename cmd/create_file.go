@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/util"
+)
+
+// createTypes are the work item types that can be created from files.
+var createTypes = []string{"User Story", "Task", "Bug", "Feature", "Epic"}
+
+// createFront is the front matter of a file given to create -f: that of
+// edit --editor plus the type, parent and area of the new item.
+type createFront struct {
+	Type        string `yaml:"type"`
+	Parent      string `yaml:"parent,omitempty"`
+	Area        string `yaml:"area,omitempty"`
+	editorFront `yaml:",inline"`
+}
+
+// newItem is a work item to create: the fields set on creation, then the
+// parent link and the state or column, which cannot be set on creation.
+type newItem struct {
+	wtype, title  string
+	fields        map[string]string
+	state, column string
+	parent        string
+}
+
+// createFromFile creates the work item described by the Markdown file at
+// path.
+func createFromFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var front createFront
+	body, err := parseFrontMatter(string(raw), &front)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	sections, err := editorBody(body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	n, err := fileItem(front, sections)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	wi, err := createItem(n)
+	if err != nil {
+		return err
+	}
+	return renderWorkItem(n.wtype+" Created", wi)
+}
+
+// fileItem validates the front matter and sections of a file given to
+// create -f and converts them to the item to create.
+func fileItem(front createFront, sections map[string]string) (newItem, error) {
+	n := newItem{fields: map[string]string{}}
+	wtype, err := createType(front.Type)
+	if err != nil {
+		return n, err
+	}
+	n.wtype = wtype
+	n.title = strings.TrimSpace(front.Title)
+	if n.title == "" {
+		return n, fmt.Errorf("title is required")
+	}
+	if front.State != nil && strings.TrimSpace(*front.State) != "" {
+		if wtype == "User Story" {
+			return n, fmt.Errorf("a User Story moves by column, not state")
+		}
+		n.state = strings.TrimSpace(*front.State)
+		if states := editStates(wtype); !contains(states, n.state) {
+			return n, fmt.Errorf("state %q: want one of %s", n.state, strings.Join(states, ", "))
+		}
+	}
+	if front.Column != nil && strings.TrimSpace(*front.Column) != "" {
+		n.column = strings.TrimSpace(*front.Column)
+		if cols := board.ColumnsFor(wtype); !contains(cols, n.column) {
+			return n, fmt.Errorf("column %q: want one of %s", n.column, strings.Join(cols, ", "))
+		}
+	}
+	if front.Severity != nil && strings.TrimSpace(*front.Severity) != "" {
+		sev := strings.TrimSpace(*front.Severity)
+		if wtype != "Bug" {
+			return n, fmt.Errorf("only a Bug has a severity")
+		}
+		if !contains(bugSeverities, sev) {
+			return n, fmt.Errorf("severity %q: want one of %s", sev, strings.Join(bugSeverities, ", "))
+		}
+		n.fields["Microsoft.VSTS.Common.Severity"] = sev
+	}
+	if n.parent = strings.TrimPrefix(strings.TrimSpace(front.Parent), "AB#"); n.parent != "" {
+		if _, err := strconv.Atoi(n.parent); err != nil {
+			return n, fmt.Errorf("parent %q is not a work item id", front.Parent)
+		}
+	} else if wtype == "Task" {
+		return n, fmt.Errorf("a Task needs a parent")
+	}
+	var assignee string
+	var tags []string
+	if front.Assignee != nil {
+		assignee = *front.Assignee
+	}
+	if front.Tags != nil {
+		tags = *front.Tags
+	}
+	if err := setNewItemFields(n.fields, assignee, tags); err != nil {
+		return n, err
+	}
+	withCreateFlags(n.fields)
+	if it := strings.TrimSpace(front.Iteration); it != "" {
+		path, err := iterationPath(it)
+		if err != nil {
+			return n, fmt.Errorf("iteration: %w", err)
+		}
+		n.fields["System.IterationPath"] = path
+	}
+	if a := strings.TrimSpace(front.Area); a != "" {
+		path, err := areaPath(a)
+		if err != nil {
+			return n, fmt.Errorf("area: %w", err)
+		}
+		n.fields["System.AreaPath"] = path
+	}
+	for _, s := range editorSections {
+		md := strings.TrimSpace(sections[s.heading])
+		if md == "" {
+			continue
+		}
+		if s.ref == "Microsoft.VSTS.Common.AcceptanceCriteria" && wtype != "User Story" {
+			return n, fmt.Errorf("a %s has no %s", wtype, s.heading)
+		}
+		n.fields[s.ref] = markdownToHTML(md)
+	}
+	return n, nil
+}
+
+// createType resolves a type name, case-insensitively and with "story" for
+// User Story, to one of createTypes. Empty means User Story.
+func createType(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "story") {
+		return "User Story", nil
+	}
+	for _, t := range createTypes {
+		if strings.EqualFold(name, t) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("type %q: want one of %s", name, strings.Join(createTypes, ", "))
+}
+
+// setNewItemFields sets the assignee (the configured default when empty,
+// @me for yourself) and tags of a new item on fields.
+func setNewItemFields(fields map[string]string, assignee string, tags []string) error {
+	at := strings.TrimSpace(assignee)
+	withDefaultAssignee(&at)
+	if at == "@me" {
+		me, err := az.CurrentUserUPN()
+		if err != nil {
+			return fmt.Errorf("get current user: %w", err)
+		}
+		at = me
+	}
+	if at != "" {
+		fields["System.AssignedTo"] = at
+	}
+	if tags := applyTagChanges(nil, tags, nil); len(tags) > 0 {
+		fields["System.Tags"] = strings.Join(tags, "; ")
+	}
+	return nil
+}
+
+// createItem creates n, links it to its parent and moves it to its state or
+// column, returning the work item as it ends up.
+func createItem(n newItem) (*az.WorkItem, error) {
+	raw, err := az.CreateWorkItem(n.wtype, n.title, n.fields, "")
+	if err != nil {
+		return nil, err
+	}
+	var wi az.WorkItem
+	if err := json.Unmarshal(raw, &wi); err != nil {
+		return nil, fmt.Errorf("parse created work item: %w", err)
+	}
+	id := strconv.Itoa(wi.ID)
+	if n.parent != "" {
+		if _, err := az.AddWorkItemRelation(id, "parent", n.parent); err != nil {
+			return &wi, fmt.Errorf("created %s %d but failed to add parent relation to %s: %w", n.wtype, wi.ID, n.parent, err)
+		}
+	}
+	fields := map[string]string{}
+	if n.state != "" && n.state != util.FieldString(wi.Fields, "System.State") {
+		fields["System.State"] = n.state
+	}
+	if key, cur := util.FindKanbanColumn(wi.Fields); key != "" && n.column != "" && n.column != cur {
+		fields[key] = n.column
+	}
+	if len(fields) == 0 {
+		return &wi, nil
+	}
+	raw, err = az.UpdateWorkItemFields(id, fields)
+	if err != nil {
+		return &wi, fmt.Errorf("created %s %d but failed to update it: %w", n.wtype, wi.ID, err)
+	}
+	var updated az.WorkItem
+	if err := json.Unmarshal(raw, &updated); err != nil {
+		return &wi, nil
+	}
+	return &updated, nil
+}
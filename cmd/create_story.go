@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
@@ -15,11 +16,24 @@ import (
 
 var assignTo string
 
+// storyParent is --parent of create story: a Feature or Epic.
+var storyParent string
+
 var createStoryCmd = &cobra.Command{
 	Use:   "story [\"Title...\"]",
 	Short: "Create a User Story",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		withDefaultAssignee(&assignTo)
+		if err := resolveCreateFlags(); err != nil {
+			return err
+		}
+		storyParent = strings.TrimPrefix(strings.TrimSpace(storyParent), "AB#")
+		if storyParent != "" {
+			if err := validateParent(storyParent, parentTypes["User Story"]...); err != nil {
+				return err
+			}
+		}
 		if len(args) == 0 {
 			return interactiveCreateStory()
 		}
@@ -37,6 +51,7 @@ var createStoryCmd = &cobra.Command{
 				fields["System.AssignedTo"] = at
 			}
 		}
+		withCreateFlags(fields)
 		raw, err := az.CreateWorkItem("User Story", title, fields, "")
 		if err != nil {
 			return err
@@ -45,18 +60,35 @@ var createStoryCmd = &cobra.Command{
 		if err := json.Unmarshal(raw, &wi); err != nil {
 			return az.PrintJSON(raw)
 		}
+		if err := linkStoryParent(wi.ID, storyParent); err != nil {
+			return err
+		}
 		return renderWorkItem("User Story Created", &wi)
 	},
 }
 
+// linkStoryParent links a new story under parent, when there is one.
+func linkStoryParent(id int, parent string) error {
+	if parent == "" {
+		return nil
+	}
+	if _, err := az.AddWorkItemRelation(strconv.Itoa(id), "parent", parent); err != nil {
+		return fmt.Errorf("created story %d but failed to add parent relation to %s: %w", id, parent, err)
+	}
+	fmt.Fprintf(os.Stderr, "Linked AB#%d as child of AB#%s\n", id, parent)
+	return nil
+}
+
 func init() {
 	createCmd.AddCommand(createStoryCmd)
 	createStoryCmd.Flags().StringVarP(&assignTo, "assign", "a", "", "Assign to user (use @me for yourself)")
+	createStoryCmd.Flags().StringVarP(&storyParent, "parent", "p", "", "Parent Feature or Epic ID. If omitted, the form offers a picker.")
 }
 
 func interactiveCreateStory() error {
 	var title, assignee, col, descMD, acMD string
-	col = board.ColumnOrder[0]
+	cols := board.ColumnsFor("User Story")
+	col = cols[0]
 	// Prefill assignee if provided via -a, resolving @me to current UPN
 	if strings.TrimSpace(assignTo) == "@me" {
 		if me, err := az.CurrentUserUPN(); err == nil {
@@ -65,20 +97,35 @@ func interactiveCreateStory() error {
 	} else if strings.TrimSpace(assignTo) != "" {
 		assignee = strings.TrimSpace(assignTo)
 	}
+	area := createAreaPath
+	parent := storyParent
 	var proceed bool
-	form := huh.NewForm(huh.NewGroup(
+	group := []huh.Field{
 		huh.NewInput().Title("Title").Value(&title).Validate(func(s string) error {
 			if strings.TrimSpace(s) == "" {
 				return fmt.Errorf("title is required")
 			}
 			return nil
 		}),
-		huh.NewSelect[string]().Title("Kanban Column").Options(optsFrom(board.ColumnOrder)...).Value(&col),
+	}
+	if parent == "" {
+		field, err := parentField(&parent, parentTypes["User Story"]...)
+		if err != nil {
+			return err
+		}
+		if field != nil {
+			group = append(group, field)
+		}
+	}
+	group = append(group,
+		huh.NewSelect[string]().Title("Kanban Column").Options(optsFrom(cols)...).Value(&col),
 		huh.NewInput().Title("Assignee (Name or email)").Value(&assignee),
+		areaField(&area),
 		huh.NewText().Title("Description (Markdown)").Lines(8).Value(&descMD),
 		huh.NewText().Title("Acceptance Criteria (Markdown)").Lines(6).Value(&acMD),
 		huh.NewConfirm().Title("Create User Story?").Value(&proceed),
-	))
+	)
+	form := huh.NewForm(huh.NewGroup(group...))
 	if err := form.Run(); err != nil {
 		return err
 	}
@@ -95,12 +142,19 @@ func interactiveCreateStory() error {
 	if strings.TrimSpace(acMD) != "" {
 		fields["Microsoft.VSTS.Common.AcceptanceCriteria"] = markdownToHTML(acMD)
 	}
+	withCreateFlags(fields)
+	if strings.TrimSpace(area) != "" {
+		fields["System.AreaPath"] = area
+	}
 	raw, err := az.CreateWorkItem("User Story", title, fields, "")
 	if err != nil {
 		return err
 	}
 	var wi az.WorkItem
 	if err := json.Unmarshal(raw, &wi); err == nil {
+		if err := linkStoryParent(wi.ID, parent); err != nil {
+			return err
+		}
 		// If user selected a starting column, update and render the updated item
 		if key, _ := util.FindKanbanColumn(wi.Fields); key != "" && strings.TrimSpace(col) != "" {
 			if uraw, err := az.UpdateWorkItemFields(strconv.Itoa(wi.ID), map[string]string{key: col}); err == nil {
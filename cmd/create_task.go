@@ -21,6 +21,10 @@ var createTaskCmd = &cobra.Command{
 	Short: "Create a Task under a User Story",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		withDefaultAssignee(&taskAssignee)
+		if err := resolveCreateFlags(); err != nil {
+			return err
+		}
 		if len(args) == 0 {
 			return interactiveCreateTask()
 		}
@@ -29,7 +33,7 @@ var createTaskCmd = &cobra.Command{
 		pid := strings.TrimSpace(parentID)
 		if pid == "" {
 			// Query non-Closed User Stories via WIQL (single call)
-			items, err := queryItemsWithOrder("User Story", false, poOrderGlobal)
+			items, err := pickerItems("User Story")
 			if err != nil {
 				return err
 			}
@@ -88,6 +92,7 @@ var createTaskCmd = &cobra.Command{
 				fields["System.AssignedTo"] = at
 			}
 		}
+		withCreateFlags(fields)
 		raw, err := az.CreateWorkItem("Task", title, fields, "")
 		if err != nil {
 			return err
@@ -127,7 +132,7 @@ func init() {
 func interactiveCreateTask() error {
 	pid := strings.TrimSpace(parentID)
 	if pid == "" {
-		items, err := queryItemsWithOrder("User Story", false, poOrderGlobal)
+		items, err := pickerItems("User Story")
 		if err != nil {
 			return err
 		}
@@ -166,6 +171,7 @@ func interactiveCreateTask() error {
 	heading := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12")).Render(fmt.Sprintf("Adding Task to %s: %s", pid, parentTitleByID(pid)))
 	fmt.Fprintln(os.Stderr, heading)
 	fmt.Fprintln(os.Stderr)
+	area := createAreaPath
 	var proceed bool
 	f := huh.NewForm(huh.NewGroup(
 		huh.NewInput().Title("Title").Value(&title).Validate(func(s string) error {
@@ -178,6 +184,7 @@ func interactiveCreateTask() error {
 			huh.NewOption("New", "New"), huh.NewOption("Active", "Active"), huh.NewOption("Closed", "Closed"),
 		).Value(&state),
 		huh.NewInput().Title("Assignee (Name or email)").Value(&assignee),
+		areaField(&area),
 		huh.NewText().Title("Description (Markdown)").Lines(8).Value(&descMD),
 		huh.NewConfirm().Title("Create Task?").Value(&proceed),
 	))
@@ -195,6 +202,10 @@ func interactiveCreateTask() error {
 	if strings.TrimSpace(descMD) != "" {
 		fields["System.Description"] = markdownToHTML(descMD)
 	}
+	withCreateFlags(fields)
+	if strings.TrimSpace(area) != "" {
+		fields["System.AreaPath"] = area
+	}
 	raw, err := az.CreateWorkItem("Task", title, fields, "")
 	if err != nil {
 		return err
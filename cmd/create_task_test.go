@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_CreateTaskLinksParentAndLists(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Story", nil)
+		parentID, taskAssignee = "1", "@me"
+		defer func() { parentID, taskAssignee = "", "" }()
+		if err := createTaskCmd.RunE(createTaskCmd, []string{"Write tests"}); err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		task := story + 1
+		if got := p.Field(task, "System.AssignedTo"); got != "Me User" {
+			t.Fatalf("assignee = %q", got)
+		}
+		items, err := queryItemsByParent("1", false)
+		if err != nil {
+			t.Fatalf("queryItemsByParent: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != task {
+			t.Fatalf("children = %+v", items)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+// printPlan shows the mutations recorded in dry-run mode. The plan is
+// rendered on stdout, or written to stderr as plain Markdown when a
+// machine-readable format owns stdout.
+func printPlan() error {
+	md := planMarkdown(az.Plan())
+	if structured() {
+		_, err := fmt.Fprint(os.Stderr, md)
+		return err
+	}
+	return renderMarkdown(md)
+}
+
+// planMarkdown renders plan as one section per change: the az command
+// line and the fields it changes.
+func planMarkdown(plan []az.PlannedChange) string {
+	var b strings.Builder
+	b.WriteString("# Dry run\n\n")
+	switch len(plan) {
+	case 0:
+		b.WriteString("Nothing would be changed.\n")
+		return b.String()
+	case 1:
+		b.WriteString("1 change planned; nothing was changed.\n\n")
+	default:
+		fmt.Fprintf(&b, "%d changes planned; nothing was changed.\n\n", len(plan))
+	}
+	for i, c := range plan {
+		fmt.Fprintf(&b, "## %d. %s\n\n", i+1, changeHeading(c.Action, c.Item, c.Title, c.Detail))
+		b.WriteString("```sh\n" + c.Command + "\n```\n\n")
+		writeChange(&b, c.Action, c.Item, c.Detail, c.Fields)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeChange writes what a planned or journaled change does to b: the
+// comment, relation or file it adds and the fields it changes.
+func writeChange(b *strings.Builder, action string, item int, detail string, fields map[string]az.FieldChange) {
+	switch {
+	case action == "comment":
+		b.WriteString(quoteMarkdown(htmlToMarkdown(detail)) + "\n")
+	case detail != "" && item != 0:
+		fmt.Fprintf(b, "- **%s:** %s\n", planDetailLabel(action), historyInline(detail))
+	}
+	for _, f := range changeFields(fields) {
+		if f.diff {
+			fmt.Fprintf(b, "- **%s:**\n\n```diff\n", f.label)
+			for _, l := range lineDiff(f.old, f.new, 2) {
+				b.WriteString(l + "\n")
+			}
+			b.WriteString("```\n\n")
+			continue
+		}
+		if action == "create" {
+			fmt.Fprintf(b, "- **%s:** %s\n", f.label, historyInline(f.new))
+			continue
+		}
+		fmt.Fprintf(b, "- **%s:** %s → %s\n", f.label, historyInline(f.old), historyInline(f.new))
+	}
+}
+
+// changeHeading names a change and the work item or repository it is on.
+func changeHeading(action string, item int, title, detail string) string {
+	verb := map[string]string{
+		"update": "Update", "create": "Create", "relation": "Link", "unlink": "Unlink",
+		"delete": "Delete", "restore": "Restore", "comment": "Comment on", "upload": "Upload",
+		"attach": "Attach to", "repo create": "Create repository", "repo delete": "Delete repository",
+	}[action]
+	if verb == "" {
+		verb = action
+	}
+	if item == 0 {
+		return verb + " " + detail
+	}
+	ref := "AB#" + strconv.Itoa(item)
+	if item < 0 {
+		ref = az.NewItemLabel(item)
+	}
+	if title != "" {
+		return fmt.Sprintf("%s %s %s", verb, ref, title)
+	}
+	return verb + " " + ref
+}
+
+// planDetailLabel names the detail of a relation or attachment.
+func planDetailLabel(action string) string {
+	if action == "attach" {
+		return "File"
+	}
+	return "Link"
+}
+
+// changeFields lists changed fields in history order, skipping values
+// that stay the same.
+func changeFields(fields map[string]az.FieldChange) []historyChange {
+	var out []historyChange
+	for ref, fc := range fields {
+		label, _ := historyLabel(ref)
+		f := historyChange{ref: ref, label: label, old: historyValue(fc.OldValue), new: historyValue(fc.NewValue)}
+		if historyHTMLFields[ref] {
+			f.old, f.new, f.diff = htmlToMarkdown(f.old), htmlToMarkdown(f.new), true
+		}
+		if f.old == f.new {
+			continue
+		}
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		_, pi := historyLabel(out[i].ref)
+		_, pj := historyLabel(out[j].ref)
+		if pi != pj {
+			return pi < pj
+		}
+		return out[i].label < out[j].label
+	})
+	return out
+}
+
+// quoteMarkdown prefixes every line of s with "> ".
+func quoteMarkdown(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("> "+l, " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
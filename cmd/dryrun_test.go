@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_DryRunRecordsPlanWithoutChanges(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Checkout", map[string]any{"System.Description": "<p>one</p><p>two</p>"})
+		c := &countingBackend{Project: p}
+		defer azpkg.Use(c)()
+		azpkg.SetDryRun(true)
+		defer azpkg.SetDryRun(false)
+
+		parentID = strconv.Itoa(story)
+		formatFlag = output.JSON
+		defer func() { parentID, formatFlag = "", "" }()
+		captureStdout(t, func() error { return createTaskCmd.RunE(createTaskCmd, []string{"Write tests"}) })
+		captureStdout(t, func() error { return closeCmd.RunE(closeCmd, []string{strconv.Itoa(story)}) })
+		if _, err := azpkg.UpdateWorkItemFields(strconv.Itoa(story), map[string]string{"System.Description": "<p>one</p><p>three</p>"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := p.ShowWorkItem(strconv.Itoa(story + 1)); err == nil {
+			t.Fatal("dry run created a work item")
+		}
+		if got := p.Field(story, "System.State"); got != "New" {
+			t.Fatalf("dry run changed the state to %s", got)
+		}
+		if c.batches == 0 {
+			t.Fatal("reads were not made")
+		}
+		plan := azpkg.Plan()
+		var actions []string
+		for _, c := range plan {
+			actions = append(actions, c.Action)
+		}
+		if got := strings.Join(actions, ","); got != "create,relation,update,update" {
+			t.Fatalf("planned actions = %s", got)
+		}
+		md := planMarkdown(plan)
+		for _, want := range []string{
+			"4 changes planned",
+			"## 1. Create {new-1} Write tests",
+			"az boards work-item relation add --id '{new-1}' --relation-type parent --target-id " + strconv.Itoa(story),
+			"- **Link:** parent AB#" + strconv.Itoa(story),
+			"- **State:** New → Closed",
+			"- two\n+ three",
+		} {
+			if !strings.Contains(md, want) {
+				t.Fatalf("plan lacks %q:\n%s", want, md)
+			}
+		}
+
+		// A link given by its reference name is described like the journal does.
+		bug := p.Add("Bug", "Rounding", nil)
+		if _, err := azpkg.AddWorkItemRelation(strconv.Itoa(story), "System.LinkTypes.Related", strconv.Itoa(bug)); err != nil {
+			t.Fatal(err)
+		}
+		plan = azpkg.Plan()
+		if got, want := plan[len(plan)-1].Detail, "related AB#"+strconv.Itoa(bug); got != want {
+			t.Fatalf("relation detail = %q, want %q", got, want)
+		}
+	})
+}
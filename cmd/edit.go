@@ -16,9 +16,17 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	// editArea is --area of edit: the area is changed without opening the form.
+	editArea string
+	// editEditor is --editor of edit: the item is edited as a Markdown
+	// document in $VISUAL or $EDITOR instead of the form.
+	editEditor bool
+)
+
 var editCmd = &cobra.Command{
 	Use:   "edit [id]",
-	Short: "Edit a work-item (title, description, assignee, state, column)",
+	Short: "Edit a work-item (title, description, assignee, state, column, area)",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var id string
@@ -40,6 +48,32 @@ var editCmd = &cobra.Command{
 		if wi == nil {
 			return fmt.Errorf("unable to inspect work item %s", id)
 		}
+		curArea := util.FieldString(wi.Fields, "System.AreaPath")
+		if strings.TrimSpace(editArea) != "" {
+			path, err := areaPath(editArea)
+			if err != nil {
+				return err
+			}
+			if strings.EqualFold(path, curArea) {
+				return renderWorkItem("No changes", wi)
+			}
+			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.AreaPath": path})
+			if err != nil {
+				return err
+			}
+			var updated az.WorkItem
+			if err := json.Unmarshal(raw, &updated); err != nil {
+				return az.PrintJSON(raw)
+			}
+			return renderWorkItem("Edited", &updated)
+		}
+		if editEditor {
+			fields, err := editInEditor(wi)
+			if err != nil {
+				return err
+			}
+			return finishEdit(id, wi, fields)
+		}
 
 		wtype := util.FieldString(wi.Fields, "System.WorkItemType")
 		title := util.FieldString(wi.Fields, "System.Title")
@@ -67,44 +101,32 @@ var editCmd = &cobra.Command{
 		var severitySelect *huh.Select[string]
 		var severity string
 		if wtype != "User Story" {
-			var stateOptions []huh.Option[string]
-			if wtype == "Task" {
-				for _, s := range []string{"New", "Active", "Closed"} {
-					stateOptions = append(stateOptions, huh.NewOption(s, s))
-				}
-			} else {
-				for _, s := range []string{"New", "Active", "Resolved", "Closed"} {
-					stateOptions = append(stateOptions, huh.NewOption(s, s))
-				}
-			}
-			stateSelect = huh.NewSelect[string]().Title("State").Options(stateOptions...).Value(&state)
+			stateSelect = huh.NewSelect[string]().Title("State").Options(optsFrom(editStates(wtype))...).Value(&state)
 			if wtype == "Bug" {
 				// Preselect existing severity or default to Medium
 				severity = util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")
 				if strings.TrimSpace(severity) == "" {
 					severity = "3 - Medium"
 				}
-				severitySelect = huh.NewSelect[string]().Title("Severity").Options(
-					huh.NewOption("1 - Critical", "1 - Critical"),
-					huh.NewOption("2 - High", "2 - High"),
-					huh.NewOption("3 - Medium", "3 - Medium"),
-					huh.NewOption("4 - Low", "4 - Low"),
-				).Value(&severity)
+				severitySelect = huh.NewSelect[string]().Title("Severity").Options(optsFrom(bugSeverities)...).Value(&severity)
 			}
 		}
 
 		assigneeInput := huh.NewInput().Title("Assignee (Name Surname or email)").Description("Leave empty to unassign").Value(&assignee)
+		area := curArea
+		areaSelect := areaField(&area)
 
 		// Column only for User Stories
 		var colSelect *huh.Select[string]
 		var newCol string
 		if wtype == "User Story" {
+			cols := board.ColumnsFor(wtype)
 			def := curCol
-			if def == "" || !contains(board.ColumnOrder, def) {
-				def = board.ColumnOrder[0]
+			if def == "" || !contains(cols, def) {
+				def = cols[0]
 			}
 			newCol = def
-			colSelect = huh.NewSelect[string]().Title("Kanban Column").Options(optsFrom(board.ColumnOrder)...).Value(&newCol)
+			colSelect = huh.NewSelect[string]().Title("Kanban Column").Options(optsFrom(cols)...).Value(&newCol)
 		}
 
 		descArea := huh.NewText().Title("Description (Markdown)").Value(&descMD).Lines(10)
@@ -128,14 +150,14 @@ var editCmd = &cobra.Command{
 		var groups []*huh.Group
 		switch {
 		case wtype == "User Story":
-			groups = []*huh.Group{huh.NewGroup(titleInput, colSelect, assigneeInput, descArea, acArea, confirm)}
+			groups = []*huh.Group{huh.NewGroup(titleInput, colSelect, assigneeInput, areaSelect, descArea, acArea, confirm)}
 		case wtype == "Bug" && stateSelect != nil:
 			// For Bug: Title, Severity, State, Assignee, Description, Confirm
-			groups = []*huh.Group{huh.NewGroup(titleInput, severitySelect, stateSelect, assigneeInput, descArea, confirm)}
+			groups = []*huh.Group{huh.NewGroup(titleInput, severitySelect, stateSelect, assigneeInput, areaSelect, descArea, confirm)}
 		case stateSelect != nil:
-			groups = []*huh.Group{huh.NewGroup(titleInput, stateSelect, assigneeInput, descArea, confirm)}
+			groups = []*huh.Group{huh.NewGroup(titleInput, stateSelect, assigneeInput, areaSelect, descArea, confirm)}
 		default:
-			groups = []*huh.Group{huh.NewGroup(titleInput, assigneeInput, descArea, confirm)}
+			groups = []*huh.Group{huh.NewGroup(titleInput, assigneeInput, areaSelect, descArea, confirm)}
 		}
 		form := huh.NewForm(groups...)
 		if err := form.Run(); err != nil {
@@ -153,6 +175,9 @@ var editCmd = &cobra.Command{
 		if state != util.FieldString(wi.Fields, "System.State") {
 			fields["System.State"] = state
 		}
+		if strings.TrimSpace(area) != "" && area != curArea {
+			fields["System.AreaPath"] = area
+		}
 
 		// Column change
 		if wtype != "Task" && newCol != curCol && newCol != "" {
@@ -176,50 +201,56 @@ var editCmd = &cobra.Command{
 			fields["System.AssignedTo"] = ""
 		}
 
-		// Apply updates
-		var lastRaw []byte
-		if len(fields) > 0 {
-			raw, err := az.UpdateWorkItemFields(id, fields)
-			if err != nil {
-				return err
-			}
-			lastRaw = raw
-		}
-		// Severity change for Bug
+		// Severity (Bug) and assignee go into the same update, so that one
+		// revision test covers every change.
 		if wtype == "Bug" {
 			curSev := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")
 			if strings.TrimSpace(severity) == "" {
 				severity = "3 - Medium"
 			}
 			if strings.TrimSpace(severity) != strings.TrimSpace(curSev) {
-				raw, err := az.UpdateWorkItemFields(id, map[string]string{"Microsoft.VSTS.Common.Severity": severity})
-				if err != nil {
-					return err
-				}
-				lastRaw = raw
+				fields["Microsoft.VSTS.Common.Severity"] = severity
 			}
 		}
 		if assignee != assigneeDisplay(wi.Fields) && strings.TrimSpace(assignee) != "" {
-			raw, err := az.UpdateWorkItemAssignee(id, assignee)
-			if err != nil {
-				return err
-			}
-			lastRaw = raw
-		}
-		if len(lastRaw) == 0 {
-			// nothing changed
-			return renderWorkItem("No changes", wi)
-		}
-		var updated az.WorkItem
-		if err := json.Unmarshal(lastRaw, &updated); err == nil {
-			return renderWorkItem("Edited", &updated)
+			fields["System.AssignedTo"] = assignee
 		}
-		// fallback
-		return az.PrintJSON(lastRaw)
+		return finishEdit(id, wi, fields)
 	},
 }
 
-func init() { rootCmd.AddCommand(editCmd) }
+// finishEdit applies the fields changed in the form or editor, unless
+// someone changed the item while editing, and shows the result.
+func finishEdit(id string, wi *az.WorkItem, fields map[string]string) error {
+	if len(fields) == 0 {
+		return renderWorkItem("No changes", wi)
+	}
+	raw, err := applyEdit(id, wi, fields)
+	if err != nil {
+		return err
+	}
+	var updated az.WorkItem
+	if err := json.Unmarshal(raw, &updated); err == nil {
+		return renderWorkItem("Edited", &updated)
+	}
+	// fallback
+	return az.PrintJSON(raw)
+}
+
+// editStates are the states the edit form offers for wtype.
+func editStates(wtype string) []string {
+	if wtype == "Task" {
+		return []string{"New", "Active", "Closed"}
+	}
+	return []string{"New", "Active", "Resolved", "Closed"}
+}
+
+func init() {
+	editCmd.Flags().StringVar(&editArea, "area", "", "Move the item to `area` (path or its last segment) without opening the form")
+	_ = editCmd.RegisterFlagCompletionFunc("area", completeAreas)
+	editCmd.Flags().BoolVarP(&editEditor, "editor", "e", false, "Edit as Markdown with YAML front matter in $VISUAL or $EDITOR instead of the form")
+	rootCmd.AddCommand(editCmd)
+}
 
 func optsFrom(values []string) []huh.Option[string] {
 	out := make([]huh.Option[string], 0, len(values))
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// editorFront is the YAML front matter of edit --editor. State, column and
+// severity are present only for the types whose form shows them; a key of
+// those, the assignee or the tags removed from the document leaves the
+// field alone.
+type editorFront struct {
+	Title     string    `yaml:"title"`
+	State     *string   `yaml:"state,omitempty"`
+	Column    *string   `yaml:"column,omitempty"`
+	Assignee  *string   `yaml:"assignee,omitempty"`
+	Severity  *string   `yaml:"severity,omitempty"`
+	Tags      *[]string `yaml:"tags,omitempty"`
+	Iteration string    `yaml:"iteration"`
+}
+
+// editorSections are the Markdown sections of edit --editor, keyed by their
+// level one heading, with the field each one is stored in.
+var editorSections = []struct{ heading, ref string }{
+	{"Description", "System.Description"},
+	{"Acceptance Criteria", "Microsoft.VSTS.Common.AcceptanceCriteria"},
+}
+
+// bugSeverities are the severities a Bug can have.
+var bugSeverities = []string{"1 - Critical", "2 - High", "3 - Medium", "4 - Low"}
+
+// runEditor opens path in the user's editor; tests replace it.
+var runEditor = launchEditor
+
+// editInEditor edits wi as a Markdown document in $VISUAL or $EDITOR and
+// returns the fields that changed. A document that does not parse is opened
+// again with the error on top; saving it empty cancels.
+func editInEditor(wi *az.WorkItem) (map[string]string, error) {
+	f, err := os.CreateTemp("", fmt.Sprintf("ab-%d-*.md", wi.ID))
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	doc := editorDocument(wi)
+	if _, err := f.WriteString(doc); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	for {
+		if err := runEditor(path); err != nil {
+			return nil, err
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text := string(raw)
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("cancelled")
+		}
+		fields, err := editorFields(wi, text)
+		if err == nil {
+			return fields, nil
+		}
+		if err := os.WriteFile(path, []byte(withEditorError(text, err)), 0o600); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// launchEditor runs $VISUAL, else $EDITOR, else vi (notepad on Windows) on
+// path, attached to the terminal. The variable may carry arguments, e.g.
+// "code --wait".
+func launchEditor(path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// editorDocument renders wi as front matter followed by a section per
+// Markdown field of its type.
+func editorDocument(wi *az.WorkItem) string {
+	wtype := util.FieldString(wi.Fields, "System.WorkItemType")
+	assignee := assigneeDisplay(wi.Fields)
+	tags := output.SplitTags(util.FieldString(wi.Fields, "System.Tags"))
+	if tags == nil {
+		tags = []string{}
+	}
+	front := editorFront{
+		Title:     util.FieldString(wi.Fields, "System.Title"),
+		Assignee:  &assignee,
+		Tags:      &tags,
+		Iteration: util.FieldString(wi.Fields, "System.IterationPath"),
+	}
+	if wtype == "User Story" {
+		_, col := util.FindKanbanColumn(wi.Fields)
+		front.Column = &col
+	} else {
+		state := util.FieldString(wi.Fields, "System.State")
+		front.State = &state
+	}
+	if wtype == "Bug" {
+		sev := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")
+		if strings.TrimSpace(sev) == "" {
+			sev = "3 - Medium"
+		}
+		front.Severity = &sev
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	_ = enc.Encode(front)
+	_ = enc.Close()
+	b.WriteString("---\n")
+	for _, s := range editorSections {
+		if s.ref == "Microsoft.VSTS.Common.AcceptanceCriteria" && wtype != "User Story" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n# %s\n\n", s.heading)
+		if md := htmlToMarkdown(util.FieldString(wi.Fields, s.ref)); md != "" {
+			b.WriteString(md + "\n")
+		}
+	}
+	return b.String()
+}
+
+// editorFields parses an edited document and returns the fields whose values
+// differ from wi.
+func editorFields(wi *az.WorkItem, text string) (map[string]string, error) {
+	front, sections, err := parseEditorDocument(text)
+	if err != nil {
+		return nil, err
+	}
+	wtype := util.FieldString(wi.Fields, "System.WorkItemType")
+	fields := map[string]string{}
+	title := strings.TrimSpace(front.Title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	if title != util.FieldString(wi.Fields, "System.Title") {
+		fields["System.Title"] = title
+	}
+	if front.State != nil {
+		state := strings.TrimSpace(*front.State)
+		states := editStates(wtype)
+		if !contains(states, state) {
+			return nil, fmt.Errorf("state %q: want one of %s", state, strings.Join(states, ", "))
+		}
+		if state != util.FieldString(wi.Fields, "System.State") {
+			fields["System.State"] = state
+		}
+	}
+	if front.Column != nil {
+		col := strings.TrimSpace(*front.Column)
+		cols := board.ColumnsFor(wtype)
+		if !contains(cols, col) {
+			return nil, fmt.Errorf("column %q: want one of %s", col, strings.Join(cols, ", "))
+		}
+		key, cur := util.FindKanbanColumn(wi.Fields)
+		if key != "" && col != cur {
+			fields[key] = col
+		}
+	}
+	if front.Severity != nil {
+		sev := strings.TrimSpace(*front.Severity)
+		if !contains(bugSeverities, sev) {
+			return nil, fmt.Errorf("severity %q: want one of %s", sev, strings.Join(bugSeverities, ", "))
+		}
+		if sev != strings.TrimSpace(util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")) {
+			fields["Microsoft.VSTS.Common.Severity"] = sev
+		}
+	}
+	if front.Assignee != nil {
+		if assignee := strings.TrimSpace(*front.Assignee); assignee != assigneeDisplay(wi.Fields) {
+			fields["System.AssignedTo"] = assignee
+		}
+	}
+	if front.Tags != nil {
+		curTags := output.SplitTags(util.FieldString(wi.Fields, "System.Tags"))
+		tags := applyTagChanges(nil, *front.Tags, nil)
+		if !strings.EqualFold(strings.Join(tags, "; "), strings.Join(curTags, "; ")) {
+			fields["System.Tags"] = strings.Join(tags, "; ")
+		}
+	}
+	if it := strings.TrimSpace(front.Iteration); it != "" && it != util.FieldString(wi.Fields, "System.IterationPath") {
+		path, err := iterationPath(it)
+		if err != nil {
+			return nil, fmt.Errorf("iteration: %w", err)
+		}
+		if path != util.FieldString(wi.Fields, "System.IterationPath") {
+			fields["System.IterationPath"] = path
+		}
+	}
+	for _, s := range editorSections {
+		md, ok := sections[s.heading]
+		if !ok {
+			continue
+		}
+		if s.ref == "Microsoft.VSTS.Common.AcceptanceCriteria" && wtype != "User Story" {
+			return nil, fmt.Errorf("a %s has no %s", wtype, s.heading)
+		}
+		if strings.TrimSpace(md) != htmlToMarkdown(util.FieldString(wi.Fields, s.ref)) {
+			fields[s.ref] = markdownToHTML(md)
+		}
+	}
+	return fields, nil
+}
+
+// parseEditorDocument splits a document into its front matter and the
+// sections under the headings in editorSections.
+func parseEditorDocument(text string) (editorFront, map[string]string, error) {
+	var front editorFront
+	body, err := parseFrontMatter(text, &front)
+	if err != nil {
+		return front, nil, err
+	}
+	sections, err := editorBody(body)
+	return front, sections, err
+}
+
+// parseFrontMatter decodes the YAML front matter of text into v, rejecting
+// unknown keys, and returns the Markdown after it.
+func parseFrontMatter(text string, v any) (string, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(strings.TrimLeft(text, "\n"), "---\n")
+	if !ok {
+		return "", fmt.Errorf("the document must start with --- and YAML front matter")
+	}
+	yml, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		yml, ok = strings.CutSuffix(rest, "\n---")
+		if !ok {
+			return "", fmt.Errorf("the front matter is not closed with ---")
+		}
+	}
+	dec := yaml.NewDecoder(strings.NewReader(yml))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("front matter: %w", err)
+	}
+	return body, nil
+}
+
+// editorBody splits body into the sections under the headings in
+// editorSections. Other headings, and headings inside code fences, belong
+// to the section they appear in.
+func editorBody(body string) (map[string]string, error) {
+	sections := map[string]string{}
+	var cur string
+	var buf bytes.Buffer
+	flush := func() {
+		if cur != "" {
+			sections[cur] = strings.TrimSpace(buf.String())
+		}
+		buf.Reset()
+	}
+	fenced := false
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		}
+		if !fenced {
+			if h, ok := strings.CutPrefix(trimmed, "# "); ok {
+				if name, known := editorSection(h); known {
+					if _, dup := sections[name]; dup || name == cur {
+						return nil, fmt.Errorf("section # %s appears twice", name)
+					}
+					flush()
+					cur = name
+					continue
+				}
+			}
+		}
+		if cur == "" && trimmed != "" {
+			return nil, fmt.Errorf("text after the front matter must be under # Description or # Acceptance Criteria")
+		}
+		buf.WriteString(line + "\n")
+	}
+	flush()
+	return sections, nil
+}
+
+// editorSection returns the name in editorSections matching heading h.
+func editorSection(h string) (string, bool) {
+	for _, s := range editorSections {
+		if strings.EqualFold(strings.TrimSpace(h), s.heading) {
+			return s.heading, true
+		}
+	}
+	return "", false
+}
+
+// editorErrorPrefix starts the YAML comment lines reporting why a document
+// was opened again.
+const editorErrorPrefix = "# ab: "
+
+// withEditorError puts err as YAML comments at the top of the front matter
+// of text, replacing those of an earlier attempt.
+func withEditorError(text string, err error) string {
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, editorErrorPrefix) {
+			kept = append(kept, line)
+		}
+	}
+	banner := editorErrorPrefix + "error: " + strings.Join(strings.Fields(err.Error()), " ") + "\n" +
+		editorErrorPrefix + "fix it and save again, or save an empty file to cancel\n"
+	text = strings.Join(kept, "\n")
+	if rest, ok := strings.CutPrefix(strings.TrimLeft(text, "\n"), "---\n"); ok {
+		return "---\n" + banner + rest
+	}
+	return "---\n" + banner + "---\n" + text
+}
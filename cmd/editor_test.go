@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_EditInEditor(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		id := p.Add("Bug", "Crash", map[string]any{"System.Description": "<p>old</p>", "System.Tags": "mobile"})
+		editEditor = true
+		defer func() { editEditor = false; runEditor = launchEditor }()
+
+		var docs []string
+		edits := []func(string) string{
+			func(doc string) string { return strings.Replace(doc, "state: New", "state: Doing", 1) },
+			func(doc string) string {
+				doc = strings.Replace(doc, "state: Doing", "state: Active", 1)
+				doc = strings.Replace(doc, "title: Crash", "title: Crash on start", 1)
+				doc = strings.Replace(doc, "iteration: Fake", "iteration: Sprint 2", 1)
+				doc = strings.Replace(doc, "  - mobile", "  - mobile\n  - triage", 1)
+				return strings.Replace(doc, "old", "Steps:\n\n1. open\n2. crash", 1)
+			},
+		}
+		runEditor = func(path string) error {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, string(raw))
+			return os.WriteFile(path, []byte(edits[len(docs)-1](string(raw))), 0o600)
+		}
+		captureStdout(t, func() error { return editCmd.RunE(editCmd, []string{strconv.Itoa(id)}) })
+		if len(docs) != 2 {
+			t.Fatalf("editor opened %d times, want 2", len(docs))
+		}
+		for _, want := range []string{"---\ntitle: Crash\n", "severity: 3 - Medium", "\n# Description\n\nold\n"} {
+			if !strings.Contains(docs[0], want) {
+				t.Fatalf("document lacks %q:\n%s", want, docs[0])
+			}
+		}
+		if strings.Contains(docs[0], "Acceptance Criteria") {
+			t.Fatalf("bug document has acceptance criteria:\n%s", docs[0])
+		}
+		if !strings.HasPrefix(docs[1], "---\n# ab: error: state \"Doing\"") {
+			t.Fatalf("no error banner:\n%s", docs[1])
+		}
+		for ref, want := range map[string]string{
+			"System.Title":         "Crash on start",
+			"System.State":         "Active",
+			"System.IterationPath": `Fake\Sprint 2`,
+			"System.Tags":          "mobile; triage",
+		} {
+			if got := p.Field(id, ref); got != want {
+				t.Fatalf("%s = %q, want %q", ref, got, want)
+			}
+		}
+		if got := p.Field(id, "System.Description"); !strings.Contains(got, "<li>crash</li>") {
+			t.Fatalf("description = %q", got)
+		}
+
+		runEditor = func(path string) error { return os.WriteFile(path, nil, 0o600) }
+		if err := editCmd.RunE(editCmd, []string{strconv.Itoa(id)}); err == nil || err.Error() != "cancelled" {
+			t.Fatalf("empty document: %v", err)
+		}
+	})
+}
+
+func TestFake_EditInEditorKeepsRemovedKeys(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		id := p.Add("Task", "Wire up", map[string]any{"System.AssignedTo": "Alice", "System.Tags": "backend; api"})
+		editEditor = true
+		defer func() { editEditor = false; runEditor = launchEditor }()
+
+		runEditor = func(path string) error {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			var kept []string
+			for _, line := range strings.Split(string(raw), "\n") {
+				if strings.HasPrefix(line, "assignee:") || strings.HasPrefix(line, "tags:") || strings.HasPrefix(line, "  - ") {
+					continue
+				}
+				kept = append(kept, line)
+			}
+			doc := strings.Replace(strings.Join(kept, "\n"), "title: Wire up", "title: Wire up the API", 1)
+			return os.WriteFile(path, []byte(doc), 0o600)
+		}
+		captureStdout(t, func() error { return editCmd.RunE(editCmd, []string{strconv.Itoa(id)}) })
+		for ref, want := range map[string]string{
+			"System.Title":      "Wire up the API",
+			"System.AssignedTo": "Alice",
+			"System.Tags":       "backend; api",
+		} {
+			if got := p.Field(id, ref); got != want {
+				t.Fatalf("%s = %q, want %q", ref, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOutputPath string
+	exportRecursive  bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [id...]",
+	Short: "Export the backlog, or work-items and their children, as Markdown, CSV or JSON",
+	Long: "Export the non-Closed work-items (narrowed with the filters of list, or the given ids) with their descriptions and acceptance criteria. " +
+		"The default is one Markdown document built like show's, with each story's child table; --format csv, tsv, json or yaml " +
+		"writes records instead, and the extension of -o picks the format when --format is not given (backlog.csv). " +
+		"-r/--recursive adds the children, and theirs, of every exported item: sections in Markdown, nested under children in json " +
+		"and yaml, rows after their parent in csv and tsv.",
+	Example: "  ab export -o backlog.md\n  ab export --type 'User Story' -r -o backlog.csv\n  ab export 1234 -r --format json",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := formatFlag
+		path := strings.TrimSpace(exportOutputPath)
+		if xp, err := util.ExpandTilde(path); err == nil {
+			path = xp
+		}
+		if format == "" && path != "" {
+			if f, err := output.Normalize(strings.TrimPrefix(filepath.Ext(path), ".")); err == nil && f != output.IDs {
+				format = f
+			}
+		}
+		if err := resolveListFilters(); err != nil {
+			return err
+		}
+		roots, err := exportRoots(args)
+		if err != nil {
+			return err
+		}
+		nodes, err := exportTree(roots, format == "" || exportRecursive)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		switch {
+		case format != "":
+			recs := exportRecords(nodes, format == output.JSON || format == output.YAML)
+			if err := output.WriteRecords(&b, format, output.ExportColumns, recs); err != nil {
+				return err
+			}
+			if path == "" {
+				_, err := os.Stdout.Write(b.Bytes())
+				return err
+			}
+		case path == "":
+			return renderMarkdown(exportMarkdown(nodes, time.Now()))
+		default:
+			b.WriteString(exportMarkdown(nodes, time.Now()))
+			format = "markdown"
+		}
+		if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+			return fmt.Errorf("save export: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s to %s\n", format, path)
+		return nil
+	},
+}
+
+// exportNode is an exported work item with its children: those exported
+// as well with --recursive, else (for User Stories) those listed in its
+// child table.
+type exportNode struct {
+	wi       *az.WorkItem
+	children []*exportNode
+}
+
+// exportRoots fetches the items given by id, else those matching the list
+// filters in list order, with all fields and their relations.
+func exportRoots(args []string) ([]az.WorkItem, error) {
+	var ids []int
+	for _, a := range args {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(a)), "AB#"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid work item id %q", a)
+		}
+		ids = append(ids, n)
+	}
+	if len(ids) == 0 {
+		var items []queryItem
+		var err error
+		if poOrderGlobal {
+			items, err = queryPOOrdered(includeAll)
+		} else {
+			items, err = queryItems("")
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range items {
+			ids = append(ids, it.ID)
+		}
+	}
+	return az.WorkItemsBatch(ids, nil, az.ExpandRelations)
+}
+
+// exportTree links the roots to their children, a batch request per level.
+// Without recursive only the children of User Stories are fetched, for
+// their child tables. Roots that turn out to be children of other roots
+// are exported under their parent only.
+func exportTree(roots []az.WorkItem, withChildren bool) ([]*exportNode, error) {
+	byID := map[int]*exportNode{}
+	var top, level []*exportNode
+	for i := range roots {
+		n := &exportNode{wi: &roots[i]}
+		byID[n.wi.ID] = n
+		top = append(top, n)
+		level = append(level, n)
+	}
+	if !withChildren {
+		return top, nil
+	}
+	nested := map[int]bool{}
+	for len(level) > 0 {
+		var ids []int
+		wanted := map[int]bool{}
+		for _, n := range level {
+			if !exportRecursive && util.FieldString(n.wi.Fields, "System.WorkItemType") != "User Story" {
+				continue
+			}
+			for _, id := range childIDs(n.wi) {
+				if _, ok := byID[id]; !ok && !wanted[id] {
+					wanted[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+		fetched, err := az.WorkItemsBatch(ids, nil, az.ExpandRelations)
+		if err != nil {
+			return nil, fmt.Errorf("fetch children failed: %w", err)
+		}
+		for i := range fetched {
+			byID[fetched[i].ID] = &exportNode{wi: &fetched[i]}
+		}
+		var next []*exportNode
+		for _, n := range level {
+			if !exportRecursive && util.FieldString(n.wi.Fields, "System.WorkItemType") != "User Story" {
+				continue
+			}
+			for _, id := range childIDs(n.wi) {
+				c, ok := byID[id]
+				if !ok || nested[id] || c == n {
+					continue
+				}
+				if !includeAll && util.FieldString(c.wi.Fields, "System.State") == "Closed" {
+					continue
+				}
+				nested[id] = true
+				n.children = append(n.children, c)
+				if wanted[id] {
+					next = append(next, c)
+				}
+			}
+		}
+		if !exportRecursive {
+			break
+		}
+		level = next
+	}
+	out := top[:0]
+	for _, n := range top {
+		if !nested[n.wi.ID] {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// childIDs returns the ids of the children linked from wi.
+func childIDs(wi *az.WorkItem) []int {
+	var ids []int
+	for _, r := range wi.Relations {
+		if r.Rel != "System.LinkTypes.Hierarchy-Forward" {
+			continue
+		}
+		if n, err := strconv.Atoi(r.URL[strings.LastIndex(r.URL, "/")+1:]); err == nil {
+			ids = append(ids, n)
+		}
+	}
+	return ids
+}
+
+// exportRecords converts nodes to records, nesting the children when
+// nested and otherwise listing them after their parent.
+func exportRecords(nodes []*exportNode, nested bool) []output.Export {
+	var out []output.Export
+	for _, n := range nodes {
+		rec := exportRecord(n.wi)
+		kids := exportRecords(n.children, nested)
+		if nested {
+			rec.Children = kids
+			out = append(out, rec)
+			continue
+		}
+		out = append(out, rec)
+		out = append(out, kids...)
+	}
+	return out
+}
+
+// exportRecord builds the record of wi.
+func exportRecord(wi *az.WorkItem) output.Export {
+	it := output.FromWorkItem(wi)
+	rec := output.Export{
+		ID:          it.ID,
+		Type:        it.Type,
+		State:       it.State,
+		Column:      it.Column,
+		Assignee:    it.Assignee,
+		Title:       it.Title,
+		Parent:      it.Parent,
+		Tags:        it.Tags,
+		Area:        util.FieldString(wi.Fields, "System.AreaPath"),
+		Iteration:   util.FieldString(wi.Fields, "System.IterationPath"),
+		Description: htmlToMarkdown(util.FieldString(wi.Fields, "System.Description")),
+		URL:         it.URL,
+	}
+	if rec.Type == "User Story" {
+		rec.AcceptanceCriteria = htmlToMarkdown(util.FieldString(wi.Fields, "Microsoft.VSTS.Common.AcceptanceCriteria"))
+	}
+	return rec
+}
+
+// exportMarkdown renders nodes as one document: the show document of every
+// item, children (with --recursive) after their parent.
+func exportMarkdown(nodes []*exportNode, now time.Time) string {
+	var b strings.Builder
+	var count func([]*exportNode) int
+	count = func(ns []*exportNode) int {
+		n := len(ns)
+		if exportRecursive {
+			for _, c := range ns {
+				n += count(c.children)
+			}
+		}
+		return n
+	}
+	fmt.Fprintf(&b, "# Backlog\n\nExported %s: %d work-items.\n\n", now.Format("2006-01-02 15:04"), count(nodes))
+	var write func([]*exportNode)
+	write = func(ns []*exportNode) {
+		for _, n := range ns {
+			children := make([]queryItem, 0, len(n.children))
+			for _, c := range n.children {
+				children = append(children, queryItem{ID: c.wi.ID, Fields: c.wi.Fields, URL: c.wi.URL})
+			}
+			b.WriteString("---\n\n")
+			b.WriteString(showMarkdown(n.wi, leafNodes(children), nil, false))
+			b.WriteString("\n")
+			if exportRecursive {
+				write(n.children)
+			}
+		}
+	}
+	write(nodes)
+	return b.String()
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "Write the export to `file` (its extension picks the format unless --format is given)")
+	exportCmd.Flags().BoolVarP(&exportRecursive, "recursive", "r", false, "Include the children of every exported item, and theirs")
+	exportCmd.Flags().BoolVarP(&includeAll, "all", "a", false, "Include Closed items")
+	addListFilterFlags(exportCmd, exportCmd.Flags())
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_Export(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Checkout", map[string]any{
+			"System.Description":                       "<p>Pay for the <strong>cart</strong>.</p>",
+			"Microsoft.VSTS.Common.AcceptanceCriteria": "<ul><li>Card works</li></ul>",
+		})
+		task := p.Add("Task", "Card form", nil)
+		closed := p.Add("Task", "Spike", map[string]any{"System.State": "Closed"})
+		sub := p.Add("Task", "Luhn check", nil)
+		bug := p.Add("Bug", "Total is wrong", map[string]any{"System.Tags": "money"})
+		for child, parent := range map[int]int{task: story, closed: story, sub: task} {
+			if err := p.SetParent(child, parent); err != nil {
+				t.Fatal(err)
+			}
+		}
+		dir := t.TempDir()
+		defer func() { exportOutputPath, exportRecursive, formatFlag, listTypes = "", false, "", nil }()
+
+		exportOutputPath = filepath.Join(dir, "backlog.csv")
+		exportRecursive = true
+		captureStdout(t, func() error { return exportCmd.RunE(exportCmd, nil) })
+		raw, err := os.ReadFile(exportOutputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(bytes.NewReader(raw)).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range rows[1:] {
+			ids = append(ids, r[0])
+		}
+		idx := func(id int) int { return slices.Index(ids, strconv.Itoa(id)) }
+		if len(ids) != 4 || idx(closed) >= 0 || idx(task) != idx(story)+1 || idx(sub) != idx(task)+1 || idx(bug) < 0 {
+			t.Fatalf("csv rows = %v", ids)
+		}
+		if r := rows[idx(story)+1]; r[10] != "Pay for the **cart**." || r[11] != "- Card works" {
+			t.Fatalf("story row = %q", r)
+		}
+
+		exportOutputPath, formatFlag = "", output.JSON
+		out := captureStdout(t, func() error { return exportCmd.RunE(exportCmd, []string{strconv.Itoa(story)}) })
+		var recs []output.Export
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("json: %v\n%s", err, out)
+		}
+		if len(recs) != 1 || len(recs[0].Children) != 1 || recs[0].Children[0].ID != task || len(recs[0].Children[0].Children) != 1 {
+			t.Fatalf("json = %s", out)
+		}
+
+		formatFlag, exportRecursive = "", false
+		exportOutputPath = filepath.Join(dir, "backlog.md")
+		listTypes = []string{"User Story"}
+		captureStdout(t, func() error { return exportCmd.RunE(exportCmd, nil) })
+		raw, err = os.ReadFile(exportOutputPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := string(raw)
+		for _, want := range []string{"1 work-items", "# User Story AB#" + strconv.Itoa(story), "**Acceptance Criteria:**  \n- Card works", "| " + strconv.Itoa(task) + " | Task |"} {
+			if !strings.Contains(md, want) {
+				t.Fatalf("markdown lacks %q:\n%s", want, md)
+			}
+		}
+		if strings.Contains(md, "# Task AB#") || strings.Contains(md, "Total is wrong") {
+			t.Fatalf("markdown exports more than the filtered stories:\n%s", md)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/board"
+)
+
+// withFake runs test against an in-memory project with prompts disabled.
+func withFake(t *testing.T, test func(p *fake.Project)) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("AB_JOURNAL", filepath.Join(t.TempDir(), "journal.jsonl"))
+	board.ResetColumnOrder()
+	defer board.ResetColumnOrder()
+	_ = azpkg.SetConfirmMode("never")
+	azpkg.SetSilent(true)
+	defer azpkg.SetSilent(false)
+	azpkg.StartJournal([]string{t.Name()})
+	defer azpkg.StopJournal()
+	p := fake.New()
+	defer azpkg.Use(p)()
+	test(p)
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- b
+	}()
+	runErr := fn()
+	w.Close()
+	os.Stdout = orig
+	out := <-done
+	if runErr != nil {
+		t.Fatalf("command failed: %v", runErr)
+	}
+	return string(out)
+}
+
+// countingBackend counts the reads of a fake project.
+type countingBackend struct {
+	*fake.Project
+	shows, batches, queries, comments int
+}
+
+func (c *countingBackend) ShowWorkItem(id string) ([]byte, error) {
+	c.shows++
+	return c.Project.ShowWorkItem(id)
+}
+
+func (c *countingBackend) WorkItemsBatch(ids []int, fields []string, expand string) ([]azpkg.WorkItem, error) {
+	c.batches++
+	return c.Project.WorkItemsBatch(ids, fields, expand)
+}
+
+func (c *countingBackend) QueryWIQL(wiql string) ([]byte, error) {
+	c.queries++
+	return c.Project.QueryWIQL(wiql)
+}
+
+func (c *countingBackend) Comments(id string, top int) ([]azpkg.Comment, error) {
+	c.comments++
+	return c.Project.Comments(id, top)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+// formatFlag selects machine-readable output (see internal/output); empty
+// means rendered Markdown.
+var formatFlag string
+
+// structured reports whether a machine-readable format was requested.
+func structured() bool { return formatFlag != "" }
+
+// itemRecords converts query results to output records.
+func itemRecords(items []queryItem) []output.Item {
+	out := make([]output.Item, 0, len(items))
+	for _, it := range items {
+		rec := output.FromFields(it.ID, it.Fields)
+		rec.URL = it.URL
+		out = append(out, rec)
+	}
+	return out
+}
+
+// emitItems writes records to path when set, otherwise to stdout.
+func emitItems(items []output.Item, path string) error {
+	var b bytes.Buffer
+	if err := output.Write(&b, formatFlag, items); err != nil {
+		return err
+	}
+	return emitBytes(b.Bytes(), path)
+}
+
+// emitItem writes a single record to path when set, otherwise to stdout.
+func emitItem(item output.Item, path string) error {
+	var b bytes.Buffer
+	if err := output.WriteOne(&b, formatFlag, item); err != nil {
+		return err
+	}
+	return emitBytes(b.Bytes(), path)
+}
+
+// emitRecords writes non-work-item records to path when set, otherwise to
+// stdout.
+func emitRecords[T output.Record](header []string, recs []T, path string) error {
+	var b bytes.Buffer
+	if err := output.WriteRecords(&b, formatFlag, header, recs); err != nil {
+		return err
+	}
+	return emitBytes(b.Bytes(), path)
+}
+
+func emitBytes(data []byte, path string) error {
+	if strings.TrimSpace(path) == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %s to %s\n", formatFlag, path)
+	return nil
+}
+
+// formatExt is the file extension used for default save paths.
+func formatExt() string {
+	switch formatFlag {
+	case "":
+		return "md"
+	case output.IDs:
+		return "txt"
+	}
+	return formatFlag
+}
+
+// mutationRecord builds the summary record for the result of a mutation.
+// raw is the updated work item as returned by the backend.
+func mutationRecord(id string, raw []byte, action string) output.Item {
+	var wi az.WorkItem
+	rec := output.Item{Tags: []string{}}
+	if err := json.Unmarshal(raw, &wi); err == nil && wi.ID != 0 {
+		rec = output.FromWorkItem(&wi)
+	}
+	if rec.ID == 0 {
+		rec.ID, _ = strconv.Atoi(id)
+	}
+	rec.Action = action
+	return rec
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_FormatJSONListAndCloseSummary(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Story", map[string]any{"System.Tags": "ux; api"})
+		task := p.Add("Task", "Task", nil)
+		if err := p.SetParent(task, story); err != nil {
+			t.Fatal(err)
+		}
+		formatFlag = output.JSON
+		defer func() { formatFlag = "" }()
+
+		var items []output.Item
+		out := captureStdout(t, func() error { return listCmd.RunE(listCmd, nil) })
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatalf("list output is not JSON: %v\n%s", err, out)
+		}
+		if len(items) != 2 {
+			t.Fatalf("expected 2 items, got %s", out)
+		}
+		for _, it := range items {
+			switch it.ID {
+			case story:
+				if it.Column != "Backlog" || len(it.Tags) != 2 {
+					t.Fatalf("story record = %+v", it)
+				}
+			case task:
+				if it.Parent == nil || *it.Parent != story {
+					t.Fatalf("task record = %+v", it)
+				}
+			}
+		}
+
+		items = nil
+		out = captureStdout(t, func() error { return closeCmd.RunE(closeCmd, []string{"2"}) })
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatalf("close output is not JSON: %v\n%s", err, out)
+		}
+		if len(items) != 1 || items[0].ID != task || items[0].State != "Closed" || items[0].Action != "closed" {
+			t.Fatalf("close summary = %s", out)
+		}
+
+		formatFlag = output.IDs
+		out = captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{"1"}) })
+		if out != "1\n" {
+			t.Fatalf("show ids = %q (closed child should be hidden)", out)
+		}
+	})
+}
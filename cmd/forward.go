@@ -27,27 +27,7 @@ var forwardCmd = &cobra.Command{
 				return err
 			}
 		}
-		_, item, err := az.ShowWorkItem(id)
-		if err != nil {
-			return err
-		}
-		if item == nil {
-			return fmt.Errorf("unable to inspect work item %s", id)
-		}
-
-		// Determine dynamic Kanban column field and current column
-		colField, curCol := util.FindKanbanColumn(item.Fields)
-		if colField == "" || curCol == "" {
-			return fmt.Errorf("kanban column field not found on work item %s", id)
-		}
-		nextCol, err := board.NextColumn(curCol)
-		if err != nil {
-			return err
-		}
-
-		fields := map[string]string{colField: nextCol}
-
-		raw, err := az.UpdateWorkItemFields(id, fields)
+		curCol, nextCol, raw, err := moveColumn(id, 1)
 		if err != nil {
 			return err
 		}
@@ -63,6 +43,39 @@ var forwardCmd = &cobra.Command{
 
 func idString(wi *az.WorkItem) string { return strconv.Itoa(wi.ID) }
 
+// moveColumn moves a work item delta Kanban columns (1 forward, -1 back) on
+// the board of its type and returns the old and new column and the updated
+// item as returned by az.
+func moveColumn(id string, delta int) (from, to string, raw []byte, err error) {
+	_, item, err := az.ShowWorkItem(id)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if item == nil {
+		return "", "", nil, fmt.Errorf("unable to inspect work item %s", id)
+	}
+	az.NoteCurrent(*item)
+	// Determine dynamic Kanban column field and current column
+	colField, from := util.FindKanbanColumn(item.Fields)
+	if colField == "" || from == "" {
+		return "", "", nil, fmt.Errorf("kanban column field not found on work item %s", id)
+	}
+	wiType := util.FieldString(item.Fields, "System.WorkItemType")
+	if delta < 0 {
+		to, err = board.PrevColumnFor(wiType, from)
+	} else {
+		to, err = board.NextColumnFor(wiType, from)
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+	raw, err = az.UpdateWorkItemFields(id, map[string]string{colField: to})
+	if err != nil {
+		return "", "", nil, err
+	}
+	return from, to, raw, nil
+}
+
 // findKanbanColumn finds the dynamic WEF_*_Kanban.Column field and value.
 // no tag manipulation in forward
 
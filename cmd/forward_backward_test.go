@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/board"
 )
 
 // fake work item JSON builder
@@ -33,6 +35,7 @@ func wiJSON(id string, col string) []byte {
 }
 
 func TestForward_MovesToNextColumn(t *testing.T) {
+	withColumnOrder(t)
 	_ = azpkg.SetConfirmMode("never")
 	defer azpkg.SetExecutorForTest(nil)
 	called := 0
@@ -78,6 +81,7 @@ func TestForward_MovesToNextColumn(t *testing.T) {
 }
 
 func TestBackward_MovesToPrevColumn(t *testing.T) {
+	withColumnOrder(t)
 	_ = azpkg.SetConfirmMode("never")
 	defer azpkg.SetExecutorForTest(nil)
 	azpkg.SetExecutorForTest(func(args ...string) ([]byte, error) {
@@ -110,6 +114,7 @@ func TestBackward_MovesToPrevColumn(t *testing.T) {
 }
 
 func TestForward_AtDoneErrors(t *testing.T) {
+	withColumnOrder(t)
 	_ = azpkg.SetConfirmMode("never")
 	defer azpkg.SetExecutorForTest(nil)
 	azpkg.SetExecutorForTest(func(args ...string) ([]byte, error) {
@@ -125,6 +130,7 @@ func TestForward_AtDoneErrors(t *testing.T) {
 }
 
 func TestBackward_AtBacklogErrors(t *testing.T) {
+	withColumnOrder(t)
 	_ = azpkg.SetConfirmMode("never")
 	defer azpkg.SetExecutorForTest(nil)
 	azpkg.SetExecutorForTest(func(args ...string) ([]byte, error) {
@@ -139,6 +145,14 @@ func TestBackward_AtBacklogErrors(t *testing.T) {
 	}
 }
 
+// withColumnOrder pins the built-in column order as an explicit override
+// (like AB_COLUMNS) so the stubbed az is not asked for the team board.
+func withColumnOrder(t *testing.T) {
+	t.Helper()
+	_ = board.SetColumnOrderFromCSV(strings.Join(board.ColumnOrder, ","))
+	t.Cleanup(board.ResetColumnOrder)
+}
+
 func indexOf(s []string, v string) int {
 	for i, x := range s {
 		if x == v {
@@ -147,3 +161,73 @@ func indexOf(s []string, v string) int {
 	}
 	return -1
 }
+
+func TestFake_ForwardAndBackwardFollowBoard(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		id := p.Add("User Story", "Story", nil)
+		if err := forwardCmd.RunE(forwardCmd, []string{"1"}); err != nil {
+			t.Fatalf("forward: %v", err)
+		}
+		if got := p.Field(id, fake.KanbanField); got != "Ready for Development" {
+			t.Fatalf("column after forward = %q", got)
+		}
+		if got := p.Field(id, "System.State"); got != "Active" {
+			t.Fatalf("state after forward = %q", got)
+		}
+		if err := backwardCmd.RunE(backwardCmd, []string{"1"}); err != nil {
+			t.Fatalf("backward: %v", err)
+		}
+		if got := p.Field(id, fake.KanbanField); got != "Backlog" {
+			t.Fatalf("column after backward = %q", got)
+		}
+		if err := backwardCmd.RunE(backwardCmd, []string{"1"}); err == nil {
+			t.Fatal("expected error moving back from Backlog")
+		}
+	})
+}
+
+func TestFake_ForwardUsesLiveBoardColumns(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		p.Columns = []azpkg.BoardColumn{
+			{ID: "a", Name: "Todo", StateMapping: map[string]string{"User Story": "New"}},
+			{ID: "b", Name: "Doing", StateMapping: map[string]string{"User Story": "Active"}},
+			{ID: "c", Name: "Shipped", StateMapping: map[string]string{"User Story": "Closed"}},
+		}
+		id := p.Add("User Story", "Story", nil)
+		if err := forwardCmd.RunE(forwardCmd, []string{"1"}); err != nil {
+			t.Fatalf("forward: %v", err)
+		}
+		if got := p.Field(id, fake.KanbanField); got != "Doing" {
+			t.Fatalf("column after forward = %q", got)
+		}
+		// An explicit order (AB_COLUMNS) still wins over the board.
+		_ = board.SetColumnOrderFromCSV("Doing,Review,Shipped")
+		if err := forwardCmd.RunE(forwardCmd, []string{"1"}); err != nil {
+			t.Fatalf("forward with override: %v", err)
+		}
+		if got := p.Field(id, fake.KanbanField); got != "Review" {
+			t.Fatalf("column after overridden forward = %q", got)
+		}
+	})
+}
+
+func TestFake_ForwardOverREST(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	board.ResetColumnOrder()
+	defer board.ResetColumnOrder()
+	_ = azpkg.SetConfirmMode("never")
+	azpkg.SetSilent(true)
+	defer azpkg.SetSilent(false)
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+	p := fake.New()
+	id := p.Add("User Story", "Story", nil)
+	srv := p.NewServer()
+	defer srv.Close()
+	defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+	if err := forwardCmd.RunE(forwardCmd, []string{"1"}); err != nil {
+		t.Fatalf("forward: %v", err)
+	}
+	if got := p.Field(id, fake.KanbanField); got != "Ready for Development" {
+		t.Fatalf("column after forward = %q", got)
+	}
+}
@@ -0,0 +1,477 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historySince  string
+	historyFields []string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <id>",
+	Short: "Show who changed which field of a work-item when",
+	Long: "Show the revisions of a work-item as a timeline of field changes, oldest first. " +
+		"Description, Acceptance Criteria and Repro Steps are shown as Markdown diffs.\n\n" +
+		"--field limits the timeline to the given fields (state, column, assignee, title, description, " +
+		"ac, tags, area, iteration, reason, severity, priority or a reference name such as System.State); " +
+		"only then are the initial values of the first revision listed. " +
+		"--since takes a date (2006-01-02), an RFC 3339 time or a duration back from now (36h, 7d, 2w).",
+	Example: "  ab history 123 --field column --since 14d",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id := strings.TrimSpace(args[0])
+		var since time.Time
+		if strings.TrimSpace(historySince) != "" {
+			t, err := parseSince(historySince, time.Now())
+			if err != nil {
+				return err
+			}
+			since = t
+		}
+		want := make([]string, 0, len(historyFields))
+		for _, f := range historyFields {
+			for _, f := range strings.Split(f, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					want = append(want, historyFieldKey(f))
+				}
+			}
+		}
+		updates, err := az.WorkItemUpdates(id)
+		if err != nil {
+			return err
+		}
+		revs := historyRevisions(updates, since, want)
+		if structured() {
+			var recs []output.Change
+			for _, r := range revs {
+				for _, c := range r.changes {
+					recs = append(recs, output.Change{
+						Item:  r.item,
+						Rev:   r.rev,
+						Date:  r.date.UTC().Format(time.RFC3339),
+						By:    r.by,
+						Field: c.ref,
+						Old:   c.old,
+						New:   c.new,
+					})
+				}
+			}
+			return emitRecords(output.ChangeColumns, recs, "")
+		}
+		return renderMarkdown(historyMarkdown(id, historyTitle(updates), revs))
+	},
+}
+
+// historyRevision is an update reduced to the changes worth showing.
+type historyRevision struct {
+	item    int
+	rev     int
+	date    time.Time
+	by      string
+	created bool
+	changes []historyChange
+}
+
+// historyChange is one changed field; old and new are display values
+// (Markdown for HTML fields).
+type historyChange struct {
+	ref   string
+	label string
+	old   string
+	new   string
+	diff  bool
+}
+
+// historyLabels names the fields shown in the timeline, in display order.
+var historyLabels = []struct{ ref, key, label string }{
+	{"System.Title", "title", "Title"},
+	{"System.State", "state", "State"},
+	{"System.Reason", "reason", "Reason"},
+	{"column", "column", "Column"},
+	{"System.AssignedTo", "assignee", "Assignee"},
+	{"System.AreaPath", "area", "Area"},
+	{"System.IterationPath", "iteration", "Iteration"},
+	{"System.Tags", "tags", "Tags"},
+	{"System.Parent", "parent", "Parent"},
+	{"Microsoft.VSTS.Common.Priority", "priority", "Priority"},
+	{"Microsoft.VSTS.Common.Severity", "severity", "Severity"},
+	{"Microsoft.VSTS.Scheduling.StoryPoints", "points", "Story Points"},
+	{"System.Description", "description", "Description"},
+	{"Microsoft.VSTS.Common.AcceptanceCriteria", "ac", "Acceptance Criteria"},
+	{"Microsoft.VSTS.TCM.ReproSteps", "repro", "Repro Steps"},
+}
+
+// historyHTMLFields are shown as Markdown diffs.
+var historyHTMLFields = map[string]bool{
+	"System.Description":                       true,
+	"Microsoft.VSTS.Common.AcceptanceCriteria": true,
+	"Microsoft.VSTS.TCM.ReproSteps":            true,
+}
+
+// historyNoise are bookkeeping fields Azure DevOps changes on every
+// revision or as a side effect of another change.
+var historyNoise = map[string]bool{
+	"System.Id":                             true,
+	"System.Rev":                            true,
+	"System.AuthorizedDate":                 true,
+	"System.AuthorizedAs":                   true,
+	"System.RevisedDate":                    true,
+	"System.ChangedDate":                    true,
+	"System.ChangedBy":                      true,
+	"System.CreatedDate":                    true,
+	"System.CreatedBy":                      true,
+	"System.PersonId":                       true,
+	"System.Watermark":                      true,
+	"System.TeamProject":                    true,
+	"System.NodeName":                       true,
+	"System.AreaId":                         true,
+	"System.IterationId":                    true,
+	"System.BoardColumnDone":                true,
+	"System.History":                        true,
+	"System.CommentCount":                   true,
+	"System.AttachedFileCount":              true,
+	"System.RelatedLinkCount":               true,
+	"System.ExternalLinkCount":              true,
+	"System.HyperLinkCount":                 true,
+	"Microsoft.VSTS.Common.StateChangeDate": true,
+	"Microsoft.VSTS.Common.StackRank":       true,
+	"Microsoft.VSTS.Common.ActivatedDate":   true,
+	"Microsoft.VSTS.Common.ActivatedBy":     true,
+	"Microsoft.VSTS.Common.ResolvedDate":    true,
+	"Microsoft.VSTS.Common.ResolvedBy":      true,
+	"Microsoft.VSTS.Common.ClosedDate":      true,
+	"Microsoft.VSTS.Common.ClosedBy":        true,
+	"Microsoft.VSTS.Common.ResolvedReason":  true,
+}
+
+// historyColumnRef reports whether ref holds the board column: the
+// team's WEF_*_Kanban.Column field or System.BoardColumn.
+func historyColumnRef(ref string) bool {
+	return ref == "System.BoardColumn" || (strings.HasPrefix(ref, "WEF_") && strings.HasSuffix(ref, "_Kanban.Column"))
+}
+
+// historyFieldKey normalizes a --field value: a friendly name, a label or
+// a reference name.
+func historyFieldKey(f string) string {
+	k := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(f), " ", ""))
+	switch k {
+	case "assigned", "assignedto":
+		return "assignee"
+	case "acceptance", "acceptancecriteria", "criteria":
+		return "ac"
+	case "desc":
+		return "description"
+	case "sprint", "iterationpath":
+		return "iteration"
+	case "areapath":
+		return "area"
+	case "tag":
+		return "tags"
+	case "boardcolumn", "kanban":
+		return "column"
+	case "reprosteps":
+		return "repro"
+	case "storypoints":
+		return "points"
+	}
+	for _, l := range historyLabels {
+		if k == strings.ToLower(l.ref) || k == strings.ToLower(strings.ReplaceAll(l.label, " ", "")) {
+			return l.key
+		}
+	}
+	if historyColumnRef(f) {
+		return "column"
+	}
+	return k
+}
+
+// historyKey returns the key ref is matched against --field with.
+func historyKey(ref string) string {
+	if historyColumnRef(ref) {
+		return "column"
+	}
+	for _, l := range historyLabels {
+		if l.ref == ref {
+			return l.key
+		}
+	}
+	return strings.ToLower(ref)
+}
+
+// historyLabel returns the display name of ref and its sort position.
+func historyLabel(ref string) (string, int) {
+	if historyColumnRef(ref) {
+		ref = "column"
+	}
+	for i, l := range historyLabels {
+		if l.ref == ref {
+			return l.label, i
+		}
+	}
+	return ref, len(historyLabels)
+}
+
+// historyRevisions reduces updates to the revisions changing wanted fields
+// (all but noise when want is empty) made at or after since. The first
+// revision only lists initial values when fields are wanted.
+func historyRevisions(updates []az.WorkItemUpdate, since time.Time, want []string) []historyRevision {
+	var out []historyRevision
+	for i, u := range updates {
+		date := u.ChangedDate()
+		if !since.IsZero() && date.Before(since) {
+			continue
+		}
+		rev := historyRevision{item: u.WorkItemID, rev: u.Rev, date: date, by: historyAuthor(u), created: i == 0 && u.Rev <= 1}
+		if rev.created && len(want) == 0 {
+			out = append(out, rev)
+			continue
+		}
+		hasKanban := false
+		for ref := range u.Fields {
+			if ref != "System.BoardColumn" && historyColumnRef(ref) {
+				hasKanban = true
+			}
+		}
+		for ref, fc := range u.Fields {
+			if historyNoise[ref] || (ref == "System.BoardColumn" && hasKanban) {
+				continue
+			}
+			if strings.HasPrefix(ref, "System.AreaLevel") || strings.HasPrefix(ref, "System.IterationLevel") {
+				continue
+			}
+			if strings.HasPrefix(ref, "WEF_") && !historyColumnRef(ref) {
+				continue
+			}
+			if len(want) > 0 && !contains(want, historyKey(ref)) {
+				continue
+			}
+			label, _ := historyLabel(ref)
+			c := historyChange{ref: ref, label: label, old: historyValue(fc.OldValue), new: historyValue(fc.NewValue)}
+			if historyHTMLFields[ref] {
+				c.old, c.new, c.diff = htmlToMarkdown(c.old), htmlToMarkdown(c.new), true
+			}
+			if c.old == c.new {
+				continue
+			}
+			rev.changes = append(rev.changes, c)
+		}
+		if len(rev.changes) == 0 && !rev.created {
+			continue
+		}
+		sort.Slice(rev.changes, func(a, b int) bool {
+			la, ia := historyLabel(rev.changes[a].ref)
+			lb, ib := historyLabel(rev.changes[b].ref)
+			if ia != ib {
+				return ia < ib
+			}
+			return la < lb
+		})
+		out = append(out, rev)
+	}
+	return out
+}
+
+// historyAuthor prefers System.ChangedBy, which names the user on whose
+// behalf a service made the change.
+func historyAuthor(u az.WorkItemUpdate) string {
+	if fc, ok := u.Fields["System.ChangedBy"]; ok {
+		if s := historyValue(fc.NewValue); s != "" {
+			return s
+		}
+	}
+	if u.RevisedBy.DisplayName != "" {
+		return u.RevisedBy.DisplayName
+	}
+	if u.RevisedBy.UniqueName != "" {
+		return u.RevisedBy.UniqueName
+	}
+	return "(unknown)"
+}
+
+// historyValue formats a field value: identities by display name, numbers
+// without exponent.
+func historyValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case map[string]any:
+		if dn, ok := t["displayName"].(string); ok && dn != "" {
+			return dn
+		}
+		if un, ok := t["uniqueName"].(string); ok {
+			return un
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// historyTitle returns the latest title in updates.
+func historyTitle(updates []az.WorkItemUpdate) string {
+	for i := len(updates) - 1; i >= 0; i-- {
+		if fc, ok := updates[i].Fields["System.Title"]; ok {
+			return historyValue(fc.NewValue)
+		}
+	}
+	return ""
+}
+
+// historyMarkdown renders the timeline, one section per revision.
+func historyMarkdown(id, title string, revs []historyRevision) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# History AB#%s", id)
+	if title != "" {
+		fmt.Fprintf(&b, ": %s", title)
+	}
+	b.WriteString("\n\n")
+	if len(revs) == 0 {
+		b.WriteString("No matching changes.\n")
+		return b.String()
+	}
+	for _, r := range revs {
+		fmt.Fprintf(&b, "## %s · %s · rev %d\n\n", commentDate(r.date), r.by, r.rev)
+		if r.created {
+			b.WriteString("- Created\n")
+		}
+		for _, c := range r.changes {
+			if c.diff {
+				fmt.Fprintf(&b, "- **%s:**\n\n", c.label)
+				b.WriteString("```diff\n")
+				for _, l := range lineDiff(c.old, c.new, 2) {
+					b.WriteString(l + "\n")
+				}
+				b.WriteString("```\n\n")
+				continue
+			}
+			if r.created {
+				fmt.Fprintf(&b, "- **%s:** %s\n", c.label, historyInline(c.new))
+				continue
+			}
+			fmt.Fprintf(&b, "- **%s:** %s → %s\n", c.label, historyInline(c.old), historyInline(c.new))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func historyInline(s string) string {
+	if s == "" {
+		return "_(none)_"
+	}
+	return strings.ReplaceAll(s, `\`, `\\`)
+}
+
+// lineDiff returns a line diff of a and b with +/- prefixes, keeping ctx
+// unchanged lines around each change; skipped runs are marked @@.
+func lineDiff(a, b string, ctx int) []string {
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "\n")
+	}
+	x, y := split(a), split(b)
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, "  "+x[i])
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+x[i])
+			i++
+		default:
+			lines = append(lines, "+ "+y[j])
+			j++
+		}
+	}
+	// Keep only unchanged lines within ctx of a change.
+	keep := make([]bool, len(lines))
+	for k, l := range lines {
+		if l[0] == ' ' {
+			continue
+		}
+		for n := max(0, k-ctx); n <= min(len(lines)-1, k+ctx); n++ {
+			keep[n] = true
+		}
+	}
+	var out []string
+	skipped := false
+	for k, l := range lines {
+		if !keep[k] {
+			skipped = true
+			continue
+		}
+		if skipped {
+			out = append(out, "@@")
+			skipped = false
+		}
+		out = append(out, l)
+	}
+	if skipped {
+		out = append(out, "@@")
+	}
+	return out
+}
+
+// parseSince parses --since: a date, an RFC 3339 time or a duration back
+// from now where d (days) and w (weeks) are accepted besides Go units.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if len(s) > 1 {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			switch s[len(s)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use 2006-01-02, an RFC 3339 time or a duration like 36h, 7d, 2w)", s)
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historySince, "since", "", "Only changes at or after a date (2006-01-02) or a duration ago (36h, 7d, 2w)")
+	historyCmd.Flags().StringArrayVar(&historyFields, "field", nil, "Only changes to these fields (repeatable or comma separated)")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_HistoryOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		day := func(d int) func() time.Time {
+			return func() time.Time { return time.Date(2026, 1, d, 12, 0, 0, 0, time.UTC) }
+		}
+		p.SetClock(day(1))
+		id := p.Add("User Story", "Story", map[string]any{"System.Description": "<p>one</p><p>two</p>"})
+		sid := strconv.Itoa(id)
+		for d, fields := range []map[string]string{
+			{fake.KanbanField: "In Process"},
+			{fake.KanbanField: "Ready to Test"},
+			{fake.KanbanField: "In Process", "System.Description": "<p>one</p><p>three</p>"},
+		} {
+			p.SetClock(day(5 + d))
+			if _, err := p.UpdateWorkItemFields(sid, fields); err != nil {
+				t.Fatal(err)
+			}
+		}
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+
+		formatFlag = output.JSON
+		defer func() { formatFlag, historyFields, historySince = "", nil, "" }()
+		historyFields = []string{"column"}
+		var changes []output.Change
+		out := captureStdout(t, func() error { return historyCmd.RunE(historyCmd, []string{sid}) })
+		if err := json.Unmarshal([]byte(out), &changes); err != nil {
+			t.Fatalf("history output is not JSON: %v\n%s", err, out)
+		}
+		var cols []string
+		for _, c := range changes {
+			cols = append(cols, c.New)
+		}
+		if got := strings.Join(cols, ","); got != "Backlog,In Process,Ready to Test,In Process" {
+			t.Fatalf("column changes = %s", got)
+		}
+
+		historySince = "2026-01-06T00:00:00Z"
+		changes = nil
+		out = captureStdout(t, func() error { return historyCmd.RunE(historyCmd, []string{sid}) })
+		if err := json.Unmarshal([]byte(out), &changes); err != nil {
+			t.Fatal(err)
+		}
+		last := changes[len(changes)-1]
+		if len(changes) != 2 || last.Old != "Ready to Test" || last.New != "In Process" || last.By != p.Me.DisplayName || last.Rev != 4 {
+			t.Fatalf("changes since = %+v", changes)
+		}
+
+		formatFlag, historyFields, historySince = "", nil, ""
+		out = captureStdout(t, func() error { return historyCmd.RunE(historyCmd, []string{sid}) })
+		for _, want := range []string{"History AB#1", "Created", "Ready to Test", "- two", "+ three"} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("history missing %q:\n%s", want, out)
+			}
+		}
+		if strings.Contains(out, "System.Rev") || strings.Contains(out, "ChangedDate") {
+			t.Fatalf("history shows bookkeeping fields:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// importAssignee is --assign of import.
+var importAssignee string
+
+var importCmd = &cobra.Command{
+	Use:   "import <backlog.md>",
+	Short: "Create User Stories with their Tasks and Bugs from a Markdown backlog",
+	Long: "Create the work-items of a Markdown backlog document, in order: every ## heading is a User Story, " +
+		"the text under it its description, and every checklist item under it (- [ ] title) a Task of that story, " +
+		"or a Bug when the title starts with \"Bug:\". Checked items are created Closed. Lines indented under a " +
+		"checklist item, nested checklists included, are its description. # headings are titles and are skipped. " +
+		"Prints which work-item each heading and checklist item became; --dry-run previews it.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		stories, err := parseBacklog(string(raw))
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if len(stories) == 0 {
+			return fmt.Errorf("%s: no ## headings to import", args[0])
+		}
+		if err := resolveCreateFlags(); err != nil {
+			return err
+		}
+		rows, err := importBacklog(stories)
+		if structured() {
+			recs := make([]output.Item, 0, len(rows))
+			for _, r := range rows {
+				recs = append(recs, r.rec)
+			}
+			if eerr := emitItems(recs, ""); eerr != nil && err == nil {
+				err = eerr
+			}
+			return err
+		}
+		if rerr := renderMarkdown(importMarkdown(rows)); rerr != nil && err == nil {
+			err = rerr
+		}
+		return err
+	},
+}
+
+// backlogItem is a User Story of a backlog document, or a Task or Bug in
+// its checklist.
+type backlogItem struct {
+	wtype, title string
+	done         bool
+	desc         []string
+	line         int
+	children     []*backlogItem
+}
+
+// backlogChecklist matches a checklist item: its indentation, mark and
+// title.
+var backlogChecklist = regexp.MustCompile(`^(\s*)[-*+] \[([ xX])\]\s+(.*)$`)
+
+// parseBacklog reads the User Stories, with their Tasks and Bugs, of a
+// backlog document.
+func parseBacklog(text string) ([]*backlogItem, error) {
+	var stories []*backlogItem
+	var story, item *backlogItem
+	fenced := false
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		indented := trimmed != "" && (line[0] == ' ' || line[0] == '\t')
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+		} else if !fenced && !indented {
+			if h, ok := strings.CutPrefix(line, "## "); ok {
+				if strings.TrimSpace(h) == "" {
+					return nil, fmt.Errorf("line %d: empty heading", i+1)
+				}
+				story = &backlogItem{wtype: "User Story", title: strings.TrimSpace(h), line: i + 1}
+				stories = append(stories, story)
+				item = nil
+				continue
+			}
+			if strings.HasPrefix(line, "# ") {
+				story, item = nil, nil
+				continue
+			}
+			if m := backlogChecklist.FindStringSubmatch(line); m != nil {
+				if story == nil {
+					return nil, fmt.Errorf("line %d: checklist item %q is not under a ## heading", i+1, m[3])
+				}
+				item = &backlogItem{wtype: "Task", title: strings.TrimSpace(m[3]), done: m[2] != " ", line: i + 1}
+				if t, ok := cutPrefixFold(item.title, "Bug:"); ok {
+					item.wtype, item.title = "Bug", strings.TrimSpace(t)
+				}
+				if item.title == "" {
+					return nil, fmt.Errorf("line %d: checklist item without a title", i+1)
+				}
+				story.children = append(story.children, item)
+				continue
+			}
+		}
+		switch {
+		case item != nil && (indented || trimmed == ""):
+			item.desc = append(item.desc, line)
+		case story != nil:
+			item = nil
+			story.desc = append(story.desc, line)
+		}
+	}
+	return stories, nil
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// description is the Markdown of the lines under it, dedented.
+func (it *backlogItem) description() string {
+	indent := -1
+	for _, l := range it.desc {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	lines := make([]string, len(it.desc))
+	for i, l := range it.desc {
+		if len(l) >= indent && indent > 0 {
+			l = l[indent:]
+		}
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// importRow is a heading or checklist item of a backlog and the work item
+// created for it.
+type importRow struct {
+	item   *backlogItem
+	parent int
+	rec    output.Item
+}
+
+// importBacklog creates the stories and their children in document order,
+// each child linked to its story. It stops at the first failure, returning
+// what was created until then.
+func importBacklog(stories []*backlogItem) ([]importRow, error) {
+	var rows []importRow
+	create := func(it *backlogItem, parent int) (int, error) {
+		n := newItem{wtype: it.wtype, title: it.title, fields: map[string]string{}}
+		if parent != 0 {
+			n.parent = strconv.Itoa(parent)
+		}
+		if it.done {
+			n.state = "Closed"
+		}
+		if err := setNewItemFields(n.fields, importAssignee, nil); err != nil {
+			return 0, err
+		}
+		withCreateFlags(n.fields)
+		if md := it.description(); md != "" {
+			n.fields["System.Description"] = markdownToHTML(md)
+		}
+		wi, err := createItem(n)
+		if wi != nil {
+			rec := output.FromWorkItem(wi)
+			rec.Action = "created"
+			rows = append(rows, importRow{item: it, parent: parent, rec: rec})
+		}
+		if err != nil {
+			return 0, fmt.Errorf("line %d %q: %w", it.line, it.title, err)
+		}
+		return wi.ID, nil
+	}
+	for _, s := range stories {
+		id, err := create(s, 0)
+		if err != nil {
+			return rows, err
+		}
+		for _, c := range s.children {
+			if _, err := create(c, id); err != nil {
+				return rows, err
+			}
+		}
+	}
+	return rows, nil
+}
+
+// importMarkdown renders which work item each heading and checklist item
+// became.
+func importMarkdown(rows []importRow) string {
+	var b strings.Builder
+	b.WriteString("# Imported\n\n")
+	if len(rows) == 0 {
+		b.WriteString("No work-items were created.\n")
+		return b.String()
+	}
+	ref := func(id int) string {
+		if id < 0 {
+			return az.NewItemLabel(id)
+		}
+		return "AB#" + strconv.Itoa(id)
+	}
+	b.WriteString("| Line | Heading | Item | Type | State | Parent |\n")
+	b.WriteString("|-----:|:--------|:-----|:-----|:------|:-------|\n")
+	for _, r := range rows {
+		parent := ""
+		if r.parent != 0 {
+			parent = ref(r.parent)
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s |\n", r.item.line, escapePipes(r.item.title), ref(r.rec.ID), r.rec.Type, r.rec.State, parent)
+	}
+	return b.String()
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importAssignee, "assign", "a", "", "Assign the new items to user (use @me for yourself)")
+	importCmd.Flags().StringVarP(&createIteration, "iteration", "i", "", "Put the new items in `iteration` (name, path or @current)")
+	_ = importCmd.RegisterFlagCompletionFunc("iteration", completeIterations)
+	importCmd.Flags().StringVar(&createArea, "area", "", "Put the new items in `area` (path or its last segment)")
+	_ = importCmd.RegisterFlagCompletionFunc("area", completeAreas)
+	rootCmd.AddCommand(importCmd)
+}
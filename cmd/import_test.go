@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_CreateFromFileAndImport(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Checkout", nil)
+		dir := t.TempDir()
+		file := filepath.Join(dir, "bug.md")
+		doc := "---\ntype: bug\ntitle: Total is wrong\nparent: AB#" + strconv.Itoa(story) + "\nstate: Active\nseverity: 2 - High\n" +
+			"tags: [checkout, money]\niteration: Sprint 3\nassignee: \"\"\n---\n\n# Description\n\nAdds **VAT** twice.\n"
+		if err := os.WriteFile(file, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		createFile = file
+		defer func() { createFile = "" }()
+		captureStdout(t, func() error { return createCmd.RunE(createCmd, nil) })
+		bug := story + 1
+		for ref, want := range map[string]string{
+			"System.WorkItemType":            "Bug",
+			"System.State":                   "Active",
+			"System.Parent":                  strconv.Itoa(story),
+			"System.Tags":                    "checkout; money",
+			"System.IterationPath":           `Fake\Sprint 3`,
+			"Microsoft.VSTS.Common.Severity": "2 - High",
+		} {
+			if got := p.Field(bug, ref); got != want {
+				t.Fatalf("%s = %q, want %q", ref, got, want)
+			}
+		}
+		if got := p.Field(bug, "System.Description"); !strings.Contains(got, "<strong>VAT</strong>") {
+			t.Fatalf("description = %q", got)
+		}
+		if err := os.WriteFile(file, []byte("---\ntype: Task\ntitle: Orphan\n---\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := createCmd.RunE(createCmd, nil); err == nil || !strings.Contains(err.Error(), "needs a parent") {
+			t.Fatalf("task without parent: %v", err)
+		}
+
+		backlog := filepath.Join(dir, "backlog.md")
+		md := "# Release 2\n\nIntro is skipped.\n\n## Pay by card\n\nAs a buyer I pay by card.\n\n" +
+			"- [ ] Card form\n  Validate the number.\n\n  - [ ] Luhn check\n- [x] Bug: Declined cards hang\n\nMore about paying.\n\n" +
+			"## Receipts\n\n- [ ] Email receipt\n"
+		if err := os.WriteFile(backlog, []byte(md), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		azpkg.SetDryRun(true)
+		out := captureStdout(t, func() error { return importCmd.RunE(importCmd, []string{backlog}) })
+		azpkg.SetDryRun(false)
+		if n := len(p.IDs()); n != 2 {
+			t.Fatalf("dry run created items: %d", n)
+		}
+		if !strings.Contains(out, "{new-2}") || !strings.Contains(out, "{new-1}") {
+			t.Fatalf("dry run mapping:\n%s", out)
+		}
+
+		captureStdout(t, func() error { return importCmd.RunE(importCmd, []string{backlog}) })
+		pay, form, declined, receipts, email := bug+1, bug+2, bug+3, bug+4, bug+5
+		for _, c := range []struct {
+			id                int
+			wtype, title, par string
+		}{
+			{pay, "User Story", "Pay by card", ""},
+			{form, "Task", "Card form", strconv.Itoa(pay)},
+			{declined, "Bug", "Declined cards hang", strconv.Itoa(pay)},
+			{receipts, "User Story", "Receipts", ""},
+			{email, "Task", "Email receipt", strconv.Itoa(receipts)},
+		} {
+			if p.Field(c.id, "System.WorkItemType") != c.wtype || p.Field(c.id, "System.Title") != c.title || p.Field(c.id, "System.Parent") != c.par {
+				t.Fatalf("AB#%d = %s %q under %q, want %s %q under %q", c.id, p.Field(c.id, "System.WorkItemType"),
+					p.Field(c.id, "System.Title"), p.Field(c.id, "System.Parent"), c.wtype, c.title, c.par)
+			}
+		}
+		if got := p.Field(declined, "System.State"); got != "Closed" {
+			t.Fatalf("checked item state = %q", got)
+		}
+		if got := p.Field(pay, "System.Description"); !strings.Contains(got, "pay by card") || !strings.Contains(got, "More about paying") {
+			t.Fatalf("story description = %q", got)
+		}
+		if got := p.Field(form, "System.Description"); !strings.Contains(got, "Validate the number") || !strings.Contains(got, "Luhn check") {
+			t.Fatalf("task description = %q", got)
+		}
+	})
+}
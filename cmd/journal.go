@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	journalLimit int
+	undoForce    bool
+)
+
+var journalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "List the changes recent ab commands made",
+	Long: "ab journals every change it makes (fields before and after, relations added, work-items created and deleted) " +
+		"under $XDG_STATE_HOME/ab (e.g. ~/.local/state/ab/journal.jsonl; AB_JOURNAL overrides the file, AB_NO_JOURNAL=true turns it off). " +
+		"Commands are numbered from the latest; ab undo reverts them.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runs, err := az.Journal()
+		if err != nil {
+			return err
+		}
+		if journalLimit > 0 && len(runs) > journalLimit {
+			runs = runs[:journalLimit]
+		}
+		if structured() {
+			var recs []output.Operation
+			for i, r := range runs {
+				for _, e := range r.Entries {
+					recs = append(recs, operationRecord(i+1, e))
+				}
+			}
+			return emitRecords(output.OperationColumns, recs, "")
+		}
+		return renderMarkdown(journalMarkdown(runs))
+	},
+}
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Revert the changes of the last n ab commands (default 1)",
+	Long: "Revert the journaled changes of the latest ab commands that have not been undone: previous field values are restored, " +
+		"added links and attachments removed, created work-items deleted and deleted ones restored from the recycle bin. " +
+		"Fields changed by someone else since are left alone unless --force is given. Comments and repositories cannot be undone.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n := 1
+		if len(args) == 1 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil || v < 1 {
+				return fmt.Errorf("invalid count %q: want a positive number", args[0])
+			}
+			n = v
+		}
+		runs, err := az.Journal()
+		if err != nil {
+			return err
+		}
+		// picked holds the indexes in runs, which number the commands.
+		var picked []int
+		for i, r := range runs {
+			if len(picked) == n {
+				break
+			}
+			if undoable(r) {
+				picked = append(picked, i)
+			}
+		}
+		if len(picked) == 0 {
+			return fmt.Errorf("nothing to undo")
+		}
+		var recs []output.Operation
+		var b strings.Builder
+		b.WriteString("# Undo\n\n")
+		failed, total := 0, 0
+		for _, i := range picked {
+			r := runs[i]
+			fmt.Fprintf(&b, "## %d. %s\n\n", i+1, runHeading(r))
+			for _, e := range r.Pending() {
+				total++
+				err := az.Undo(e, undoForce)
+				if err != nil && !errors.Is(err, az.ErrIrreversible) {
+					failed++
+				}
+				rec := operationRecord(i+1, e)
+				rec.Undone = err == nil
+				if err != nil {
+					rec.Error = err.Error()
+				}
+				recs = append(recs, rec)
+				fmt.Fprintf(&b, "- %s: %s\n", changeHeading(e.Action, e.Item, e.Title, e.Detail), undoOutcome(err))
+			}
+			b.WriteString("\n")
+		}
+		if structured() {
+			if err := emitRecords(output.OperationColumns, recs, ""); err != nil {
+				return err
+			}
+		} else if err := renderMarkdown(b.String()); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d changes could not be undone", failed, total)
+		}
+		return nil
+	},
+}
+
+// undoable reports whether r is a command whose changes can still be
+// reverted: not itself an undo, with reversible changes not undone yet.
+func undoable(r az.JournalRun) bool {
+	if r.IsUndo() {
+		return false
+	}
+	for _, e := range r.Pending() {
+		if e.Reversible() {
+			return true
+		}
+	}
+	return false
+}
+
+// undoOutcome describes the result of undoing a change.
+func undoOutcome(err error) string {
+	switch {
+	case err == nil:
+		return "undone"
+	case errors.Is(err, az.ErrIrreversible):
+		return "skipped, cannot be undone"
+	}
+	return "failed: " + strings.Join(strings.Fields(err.Error()), " ")
+}
+
+// journalMarkdown renders runs, the latest first, with their changes.
+func journalMarkdown(runs []az.JournalRun) string {
+	var b strings.Builder
+	b.WriteString("# Journal\n\n")
+	if len(runs) == 0 {
+		b.WriteString("No changes recorded.\n")
+		return b.String()
+	}
+	for i, r := range runs {
+		fmt.Fprintf(&b, "## %d. %s\n\n", i+1, runHeading(r))
+		for _, e := range r.Entries {
+			heading := changeHeading(e.Action, e.Item, e.Title, e.Detail)
+			if e.Undone {
+				heading += " (undone)"
+			}
+			fmt.Fprintf(&b, "### %s\n\n", heading)
+			writeChange(&b, e.Action, e.Item, e.Detail, e.Fields)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// runHeading is the command line of r and when it ran.
+func runHeading(r az.JournalRun) string {
+	return fmt.Sprintf("`%s` (%s)", r.Command, r.Time.Local().Format("2006-01-02 15:04"))
+}
+
+// operationRecord converts a journal entry of the n:th latest command to
+// a record.
+func operationRecord(n int, e az.JournalEntry) output.Operation {
+	rec := output.Operation{
+		Run:     n,
+		Time:    e.Time.UTC().Format(time.RFC3339),
+		Command: e.Command,
+		Action:  e.Action,
+		Item:    e.Item,
+		Title:   e.Title,
+		Undone:  e.Undone,
+	}
+	var changes []string
+	for _, f := range changeFields(e.Fields) {
+		if f.diff {
+			changes = append(changes, f.ref+": changed")
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s → %s", f.ref, f.old, f.new))
+	}
+	if e.Detail != "" && e.Action != "comment" {
+		changes = append(changes, e.Detail)
+	}
+	rec.Changes = strings.Join(changes, "; ")
+	return rec
+}
+
+func init() {
+	journalCmd.Flags().IntVarP(&journalLimit, "limit", "n", 10, "Show the latest N commands (0 for all)")
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Restore fields even when they were changed since")
+	rootCmd.AddCommand(journalCmd, undoCmd)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_JournalAndUndoOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		story := p.Add("User Story", "Checkout", nil)
+		task := p.Add("Task", "Payment form", nil)
+		gone := p.Add("Bug", "Typo", nil)
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+
+		azpkg.StartJournal([]string{"close", strconv.Itoa(story)})
+		if err := closeCmd.RunE(closeCmd, []string{strconv.Itoa(story)}); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+		azpkg.StartJournal([]string{"link"})
+		if _, err := azpkg.AddWorkItemRelation(strconv.Itoa(task), "parent", strconv.Itoa(story)); err != nil {
+			t.Fatal(err)
+		}
+		azpkg.StartJournal([]string{"delete", strconv.Itoa(gone)})
+		if err := deleteCmd.RunE(deleteCmd, []string{strconv.Itoa(gone)}); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+
+		runs, err := azpkg.Journal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := journalMarkdown(runs)
+		for _, want := range []string{
+			"## 1. `ab delete " + strconv.Itoa(gone) + "`",
+			"### Delete AB#" + strconv.Itoa(gone) + " Typo",
+			"### Link AB#" + strconv.Itoa(task) + " Payment form",
+			"- **Link:** parent AB#" + strconv.Itoa(story),
+			"- **State:** New → Closed",
+		} {
+			if !strings.Contains(md, want) {
+				t.Fatalf("journal lacks %q:\n%s", want, md)
+			}
+		}
+
+		azpkg.StartJournal([]string{"undo", "3"})
+		if err := undoCmd.RunE(undoCmd, []string{"3"}); err != nil {
+			t.Fatalf("undo: %v", err)
+		}
+		if got := p.Field(story, "System.State"); got != "New" {
+			t.Fatalf("state after undo = %q", got)
+		}
+		if got := p.Field(story, fake.KanbanField); got != "Backlog" {
+			t.Fatalf("column after undo = %q", got)
+		}
+		if wi, _ := p.Get(task); len(wi.Relations) != 0 || wi.Fields["System.Parent"] != nil {
+			t.Fatalf("relation not removed: %+v", wi)
+		}
+		if _, ok := p.Get(gone); !ok {
+			t.Fatal("deleted item not restored")
+		}
+		if err := undoCmd.RunE(undoCmd, nil); err == nil || !strings.Contains(err.Error(), "nothing to undo") {
+			t.Fatalf("second undo: %v", err)
+		}
+
+		// A field changed by someone else since is only restored with --force.
+		azpkg.StartJournal([]string{"close", strconv.Itoa(story)})
+		if err := closeCmd.RunE(closeCmd, []string{strconv.Itoa(story)}); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+		if _, err := p.UpdateWorkItemFields(strconv.Itoa(story), map[string]string{"System.State": "Resolved"}); err != nil {
+			t.Fatal(err)
+		}
+		azpkg.StartJournal([]string{"undo"})
+		if err := undoCmd.RunE(undoCmd, nil); err == nil || !strings.Contains(err.Error(), "could not be undone") {
+			t.Fatalf("undo of a changed item: %v", err)
+		}
+		if got := p.Field(story, "System.State"); got != "Resolved" {
+			t.Fatalf("state overwritten without --force: %q", got)
+		}
+		undoForce = true
+		defer func() { undoForce = false }()
+		if err := undoCmd.RunE(undoCmd, nil); err != nil {
+			t.Fatalf("undo --force: %v", err)
+		}
+		if got := p.Field(story, "System.State"); got != "New" {
+			t.Fatalf("state after undo --force = %q", got)
+		}
+	})
+}
@@ -8,31 +8,82 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
 	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/output"
 	"github.com/sa6mwa/ab/internal/term"
 	"github.com/sa6mwa/ab/internal/util"
+	"github.com/sa6mwa/ab/internal/wiql"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var includeAll bool
 var listOutputPath string
 var listOutputPick bool
 
+// listTags restricts listings to items carrying every tag; listShowTags
+// adds a Tags column to the tables. listIteration restricts them to an
+// iteration and those below it; resolveListFilters resolves it into
+// listIterationPath.
+var (
+	listTags          []string
+	listShowTags      bool
+	listIteration     string
+	listIterationPath string
+)
+
+// listArea restricts listings to an area, with listUnder also to the areas
+// below it; resolveListFilters resolves it into listAreaPath. listShowArea
+// adds an Area column to the tables.
+var (
+	listArea     string
+	listAreaPath string
+	listUnder    bool
+	listShowArea bool
+)
+
+// Filter flags compiled by listConditions, stateCondition and
+// typeCondition; resolveListFilters validates --parent and --changed-since
+// into listParentID and listChangedAfter.
+var (
+	listAssignee      string
+	listStates        []string
+	listTypes         []string
+	listColumns       []string
+	listCreatedBy     string
+	listChangedSince  string
+	listChangedAfter  time.Time
+	listTitleContains string
+	listParent        string
+	listParentID      int
+)
+
 var listCmd = &cobra.Command{
 	Use:   "list [parentID]",
 	Short: "List work-items",
-	Long:  "List non-Closed work-items by default. Use -a/--all to include Closed. If a parent ID is provided, lists children of that work-item.",
-	Args:  cobra.RangeArgs(0, 1),
+	Long: "List non-Closed work-items by default. Use -a/--all to include Closed. If a parent ID is provided, lists children of that work-item.\n\n" +
+		"Filters combine (all must match); repeatable ones (--state, --type, --column, --tag) accept several values. " +
+		"--assignee and --created-by take @me, a name, an email or (for --assignee) none. --state replaces the default non-Closed filter.",
+	Example: "  ab list --assignee @me --state Active\n  ab list --type Bug --changed-since 2d --title-contains login",
+	Args:    cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveListFilters(); err != nil {
+			return err
+		}
 		if len(args) == 1 {
 			// list children of the given parent work-item
 			items, err := queryItemsByParent(args[0], includeAll)
 			if err != nil {
 				return err
 			}
+			if structured() {
+				return emitListItems(items, fmt.Sprintf("ab%s", args[0]))
+			}
 			// fetch parent for header
 			_, parent, err := az.ShowWorkItem(args[0])
 			if err != nil {
@@ -75,6 +126,9 @@ var listCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if structured() {
+			return emitListItems(items, "ab-list")
+		}
 		md, err := renderItems(items)
 		if err != nil {
 			return err
@@ -107,10 +161,16 @@ var tasksCmd = &cobra.Command{
 	Use:   "tasks",
 	Short: "List Tasks",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveListFilters(); err != nil {
+			return err
+		}
 		items, err := queryItems("Task")
 		if err != nil {
 			return err
 		}
+		if structured() {
+			return emitListItems(items, "ab-list")
+		}
 		_, err = renderItemsTypeLess(items, "Tasks")
 		return err
 	},
@@ -120,10 +180,16 @@ var storiesCmd = &cobra.Command{
 	Use:   "stories",
 	Short: "List User Stories",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := resolveListFilters(); err != nil {
+			return err
+		}
 		items, err := queryItems("User Story")
 		if err != nil {
 			return err
 		}
+		if structured() {
+			return emitListItems(items, "ab-list")
+		}
 		_, err = renderItemsTypeLess(items, "User Stories")
 		return err
 	},
@@ -132,17 +198,55 @@ var storiesCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.PersistentFlags().BoolVarP(&includeAll, "all", "a", false, "Include Closed items")
-	listCmd.PersistentFlags().StringVarP(&listOutputPath, "output", "o", "", "Write generated Markdown to file")
+	listCmd.PersistentFlags().StringVarP(&listOutputPath, "output", "o", "", "Write generated Markdown (or --format output) to file")
 	listCmd.PersistentFlags().BoolVarP(&listOutputPick, "output-pick", "O", false, "Pick output file path interactively")
+	listCmd.PersistentFlags().BoolVarP(&listShowTags, "show-tags", "T", false, "Add a Tags column to the tables")
+	listCmd.PersistentFlags().BoolVar(&listShowArea, "show-area", false, "Add an Area column to the tables")
+	addListFilterFlags(listCmd, listCmd.PersistentFlags())
 	listCmd.AddCommand(tasksCmd)
 	listCmd.AddCommand(storiesCmd)
 }
 
+// addListFilterFlags registers the filter flags of list, compiled by
+// listConditions, on fs of c.
+func addListFilterFlags(c *cobra.Command, fs *pflag.FlagSet) {
+	fs.StringArrayVarP(&listTags, "tag", "t", nil, "Only items tagged `tag` (repeat to require several)")
+	_ = c.RegisterFlagCompletionFunc("tag", completeTags(""))
+	fs.StringVarP(&listIteration, "iteration", "i", "", "Only items in `iteration` (name, path or @current) or below it")
+	_ = c.RegisterFlagCompletionFunc("iteration", completeIterations)
+	fs.StringVar(&listArea, "area", "", "Only items in `area` (path or its last segment)")
+	fs.BoolVar(&listUnder, "under", false, "With --area, include the areas below it")
+	fs.StringVar(&listAssignee, "assignee", "", "Only items assigned to `who` (@me, name, email or none)")
+	fs.StringArrayVar(&listStates, "state", nil, "Only items in `state` (repeatable; replaces the non-Closed default)")
+	fs.StringArrayVar(&listTypes, "type", nil, "Only items of `type`, e.g. Bug (repeatable)")
+	fs.StringArrayVar(&listColumns, "column", nil, "Only items in board `column` (repeatable)")
+	fs.StringVar(&listCreatedBy, "created-by", "", "Only items created by `who` (@me, name or email)")
+	fs.StringVar(&listChangedSince, "changed-since", "", "Only items changed since a date (2006-01-02) or `ago` (2d, 1w, 36h); whole days")
+	fs.StringVar(&listTitleContains, "title-contains", "", "Only items whose title contains `text`")
+	fs.StringVar(&listParent, "parent", "", "Only children of work-item `id`")
+	_ = c.RegisterFlagCompletionFunc("state", cobra.FixedCompletions([]string{"New", "Active", "Resolved", "Closed", "Removed"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = c.RegisterFlagCompletionFunc("type", cobra.FixedCompletions([]string{"User Story", "Bug", "Task", "Feature", "Epic"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = c.RegisterFlagCompletionFunc("column", completeColumns)
+	_ = c.RegisterFlagCompletionFunc("area", completeAreas)
+}
+
 type queryItem struct {
 	ID     int                    `json:"id"`
 	Fields map[string]interface{} `json:"fields"`
+	URL    string                 `json:"url,omitempty"`
 }
 
+// listFields are the fields selected by listing queries; they cover both
+// the Markdown tables and the machine-readable record (see internal/output).
+var listFields = []string{"System.Id", "System.Title", "System.State", "System.WorkItemType", "System.AssignedTo", "System.BoardColumn", "System.Tags", "System.Parent", "System.AreaPath"}
+
+// Sort keys of the listing queries.
+const (
+	orderChanged   = "[System.ChangedDate] DESC"
+	orderStackRank = "[Microsoft.VSTS.Common.StackRank] ASC"
+	stackRankField = "Microsoft.VSTS.Common.StackRank"
+)
+
 // queryItems runs a WIQL selecting needed fields and returns items directly.
 func queryItems(typeFilter string) ([]queryItem, error) {
 	wiql := baseListWIQL(typeFilter)
@@ -151,60 +255,56 @@ func queryItems(typeFilter string) ([]queryItem, error) {
 
 // queryItemsWithOrder returns items honoring PO order when requested.
 func queryItemsWithOrder(typeFilter string, includeClosed, usePO bool) ([]queryItem, error) {
+	return queryItemsWithOrderWith(typeFilter, includeClosed, usePO, az.QueryWIQL)
+}
+
+// queryItemsWithOrderWith is queryItemsWithOrder running its queries with
+// run.
+func queryItemsWithOrderWith(typeFilter string, includeClosed, usePO bool, run func(string) ([]byte, error)) ([]queryItem, error) {
 	if !usePO {
-		return queryItemsByWIQL(baseListWIQLWith(includeClosed, typeFilter))
+		return queryItemsByWIQLWith(baseListWIQLWith(includeClosed, typeFilter), run)
 	}
 	// PO order requested
 	// For specific types, if Story or Bug, order by StackRank; else by ChangedDate
 	if typeFilter == "User Story" || typeFilter == "Bug" {
-		where := []string{}
-		if !includeClosed {
-			where = append(where, "[System.State] <> 'Closed'")
-		}
-		where = append(where, fmt.Sprintf("[System.WorkItemType] = '%s'", typeFilter))
-		wiql := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo], [Microsoft.VSTS.Common.StackRank] FROM WorkItems"
-		wiql += " WHERE " + strings.Join(where, " AND ")
-		wiql += " ORDER BY [Microsoft.VSTS.Common.StackRank] ASC, [System.ChangedDate] DESC"
-		return queryItemsByWIQL(wiql)
+		q := wiql.Select(append(listFields, stackRankField)...).
+			Where(stateCondition(includeClosed), typeCondition(typeFilter)).
+			Where(listConditions()...).
+			OrderBy(orderStackRank, orderChanged)
+		return queryItemsByWIQLWith(q.String(), run)
 	}
 	// No type filter: combine Story+Bug by StackRank, others by date
 	if typeFilter == "" {
-		return queryPOOrdered(includeClosed)
+		return queryPOOrderedWith(includeClosed, run)
 	}
 	// Other single types: default ChangedDate ordering
-	return queryItemsByWIQL(baseListWIQLWith(includeClosed, typeFilter))
+	return queryItemsByWIQLWith(baseListWIQLWith(includeClosed, typeFilter), run)
 }
 
 // queryPOOrdered returns Stories/Bugs ordered by StackRank then others by ChangedDate.
 func queryPOOrdered(includeClosed bool) ([]queryItem, error) {
+	return queryPOOrderedWith(includeClosed, az.QueryWIQL)
+}
+
+// queryPOOrderedWith is queryPOOrdered running its queries with run.
+func queryPOOrderedWith(includeClosed bool, run func(string) ([]byte, error)) ([]queryItem, error) {
+	poTypes := []any{"User Story", "Bug"}
 	// First: User Story + Bug by StackRank ASC, then ChangedDate DESC
-	var where1 []string
-	if !includeClosed {
-		where1 = append(where1, "[System.State] <> 'Closed'")
-	}
-	where1 = append(where1, "[System.WorkItemType] IN ('User Story','Bug')")
-	wiql1 := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo], [Microsoft.VSTS.Common.StackRank] FROM WorkItems"
-	if len(where1) > 0 {
-		wiql1 += " WHERE " + strings.Join(where1, " AND ")
-	}
-	wiql1 += " ORDER BY [Microsoft.VSTS.Common.StackRank] ASC, [System.ChangedDate] DESC"
-	items1, err := queryItemsByWIQL(wiql1)
+	q1 := wiql.Select(append(listFields, stackRankField)...).
+		Where(stateCondition(includeClosed), wiql.In("System.WorkItemType", poTypes...), typeCondition("")).
+		Where(listConditions()...).
+		OrderBy(orderStackRank, orderChanged)
+	items1, err := queryItemsByWIQLWith(q1.String(), run)
 	if err != nil {
 		return nil, err
 	}
 
 	// Second: all other types by ChangedDate DESC
-	var where2 []string
-	if !includeClosed {
-		where2 = append(where2, "[System.State] <> 'Closed'")
-	}
-	where2 = append(where2, "[System.WorkItemType] NOT IN ('User Story','Bug')")
-	wiql2 := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo] FROM WorkItems"
-	if len(where2) > 0 {
-		wiql2 += " WHERE " + strings.Join(where2, " AND ")
-	}
-	wiql2 += " ORDER BY [System.ChangedDate] DESC"
-	items2, err := queryItemsByWIQL(wiql2)
+	q2 := wiql.Select(listFields...).
+		Where(stateCondition(includeClosed), wiql.NotIn("System.WorkItemType", poTypes...), typeCondition("")).
+		Where(listConditions()...).
+		OrderBy(orderChanged)
+	items2, err := queryItemsByWIQLWith(q2.String(), run)
 	if err != nil {
 		return nil, err
 	}
@@ -212,9 +312,21 @@ func queryPOOrdered(includeClosed bool) ([]queryItem, error) {
 	return append(items1, items2...), nil
 }
 
+// pickerItems returns the non-Closed items of typeFilter ("" for all) for a
+// picker. A recent result is served from the disk cache and refreshed in
+// the background so pickers open instantly.
+func pickerItems(typeFilter string) ([]queryItem, error) {
+	return queryItemsWithOrderWith(typeFilter, false, poOrderGlobal, az.CachedQueryWIQL)
+}
+
 // queryItemsByWIQL runs a WIQL and parses into []queryItem supporting several shapes.
 func queryItemsByWIQL(wiql string) ([]queryItem, error) {
-	raw, err := az.QueryWIQL(wiql)
+	return queryItemsByWIQLWith(wiql, az.QueryWIQL)
+}
+
+// queryItemsByWIQLWith is queryItemsByWIQL running the query with run.
+func queryItemsByWIQLWith(wiql string, run func(string) ([]byte, error)) ([]queryItem, error) {
+	raw, err := run(wiql)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -239,152 +351,87 @@ func queryItemsByWIQL(wiql string) ([]queryItem, error) {
 	return []queryItem{}, nil
 }
 
-// baseListWIQLWith builds WIQL using provided includeClosed and optional type filter.
+// baseListWIQLWith builds WIQL using provided includeClosed and optional
+// type filter, plus the list filter flags.
 func baseListWIQLWith(includeClosed bool, typeFilter string) string {
-	wiql := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo] FROM WorkItems"
-	var where string
-	if !includeClosed {
-		where = "[System.State] <> 'Closed'"
-	}
-	if strings.TrimSpace(typeFilter) != "" {
-		cond := fmt.Sprintf("[System.WorkItemType] = '%s'", typeFilter)
-		if where == "" {
-			where = cond
-		} else {
-			where = where + " AND " + cond
-		}
-	}
-	if where != "" {
-		wiql += " WHERE " + where
-	}
-	wiql += " ORDER BY [System.ChangedDate] DESC"
-	return wiql
+	return wiql.Select(listFields...).
+		Where(stateCondition(includeClosed), typeCondition(typeFilter)).
+		Where(listConditions()...).
+		OrderBy(orderChanged).
+		String()
 }
 
 // queryItemsByParent lists direct children using System.Parent
 func queryItemsByParent(parentID string, includeClosed bool) ([]queryItem, error) {
-	// Step 1: fetch child IDs via WorkItems WIQL with System.Parent
-	wiqlIDs := fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.Parent] = %s", parentID)
-	raw, err := az.QueryWIQL(wiqlIDs)
+	pid, err := strconv.Atoi(strings.TrimSpace(parentID))
+	if err != nil {
+		return nil, fmt.Errorf("invalid work item id %q", parentID)
+	}
+	// Step 1: the ids of the matching children, in list order
+	q := wiql.Select("System.Id").
+		Where(wiql.Eq("System.Parent", pid), stateCondition(includeClosed), typeCondition("")).
+		Where(listConditions()...).
+		OrderBy(orderChanged)
+	found, err := queryItemsByWIQL(q.String())
 	if err != nil {
 		return nil, fmt.Errorf("query child ids failed: %w", err)
 	}
-	// Try to parse multiple possible shapes
-	ids := make([]int, 0)
-	// shape A: {"workItems":[{"id":123},...]}
-	var resA struct {
-		WorkItems []struct {
-			ID     int            `json:"id"`
-			Fields map[string]any `json:"fields"`
-		} `json:"workItems"`
-	}
-	if err := json.Unmarshal(raw, &resA); err == nil && len(resA.WorkItems) > 0 {
-		for _, it := range resA.WorkItems {
-			if it.ID != 0 {
-				ids = append(ids, it.ID)
-				continue
-			}
-			if it.Fields != nil {
-				if v, ok := it.Fields["System.Id"]; ok {
-					switch n := v.(type) {
-					case float64:
-						ids = append(ids, int(n))
-					}
-				}
+	ids := make([]int, 0, len(found))
+	for _, it := range found {
+		if it.ID == 0 {
+			// Some shapes only carry the id as a field.
+			if n, ok := it.Fields["System.Id"].(float64); ok {
+				it.ID = int(n)
 			}
 		}
-	}
-	// shape B: array of objects with id/fields
-	if len(ids) == 0 {
-		var arr []map[string]any
-		if err := json.Unmarshal(raw, &arr); err == nil && len(arr) > 0 {
-			for _, obj := range arr {
-				if v, ok := obj["id"]; ok {
-					if f, ok := v.(float64); ok {
-						ids = append(ids, int(f))
-						continue
-					}
-				}
-				if flds, ok := obj["fields"].(map[string]any); ok {
-					if v, ok := flds["System.Id"]; ok {
-						if f, ok := v.(float64); ok {
-							ids = append(ids, int(f))
-						}
-					}
-				}
-			}
+		if it.ID != 0 {
+			ids = append(ids, it.ID)
 		}
 	}
-	if len(ids) == 0 {
-		return []queryItem{}, nil
-	}
-	// Step 2: fetch fields for those IDs in one shot
-	idStrs := make([]string, 0, len(ids))
-	for _, id := range ids {
-		idStrs = append(idStrs, strconv.Itoa(id))
-	}
-	wiql := fmt.Sprintf("SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo] FROM WorkItems WHERE [System.Id] IN (%s)", strings.Join(idStrs, ","))
-	if !includeClosed {
-		wiql += " AND [System.State] <> \"Closed\""
-	}
-	wiql += " ORDER BY [System.ChangedDate] DESC"
-	raw2, err := az.QueryWIQL(wiql)
+	// Step 2: their fields, a batch request per 200 children
+	items, err := batchItems(ids)
 	if err != nil {
 		return nil, fmt.Errorf("query children failed: %w", err)
 	}
-	// Try array shape first
-	var arr []queryItem
-	if err := json.Unmarshal(raw2, &arr); err == nil && len(arr) > 0 {
-		return arr, nil
-	}
-	var res struct {
-		WorkItems []queryItem `json:"workItems"`
-		Value     []queryItem `json:"value"`
-	}
-	if err := json.Unmarshal(raw2, &res); err != nil {
-		return nil, fmt.Errorf("parse children: %w", err)
+	return items, nil
+}
+
+// batchItems fetches the list fields of ids with the work items batch API,
+// keeping the order of ids.
+func batchItems(ids []int) ([]queryItem, error) {
+	wis, err := az.WorkItemsBatch(ids, listFields, az.ExpandNone)
+	if err != nil {
+		return nil, err
 	}
-	items := res.WorkItems
-	if len(items) == 0 {
-		items = res.Value
+	items := make([]queryItem, 0, len(wis))
+	for _, wi := range wis {
+		items = append(items, queryItem{ID: wi.ID, Fields: wi.Fields, URL: wi.URL})
 	}
 	return items, nil
 }
 
 // baseListWIQL builds a WIQL string returning all needed fields for the filters.
 func baseListWIQL(typeFilter string) string {
-	wiql := "SELECT [System.Id], [System.Title], [System.State], [System.WorkItemType], [System.AssignedTo] FROM WorkItems"
-	var where string
-	if !includeAll {
-		where = "[System.State] <> \"Closed\""
-	}
-	if typeFilter != "" {
-		cond := fmt.Sprintf("[System.WorkItemType] = \"%s\"", typeFilter)
-		if where == "" {
-			where = cond
-		} else {
-			where = where + " AND " + cond
-		}
-	}
-	if where != "" {
-		wiql += " WHERE " + where
-	}
-	wiql += " ORDER BY [System.ChangedDate] DESC"
-	return wiql
+	return baseListWIQLWith(includeAll, typeFilter)
 }
 
 // renderList fetches details and prints a glow-style rendered markdown table to stdout.
 func renderItems(items []queryItem) (string, error) {
+	return renderItemsHeading(items, "Work Items")
+}
+
+// renderItemsHeading is renderItems under a custom heading.
+func renderItemsHeading(items []queryItem, heading string) (string, error) {
 	if len(items) == 0 {
 		fmt.Println("No work-items found.")
-		return "# Work Items\n\nNo work-items found.\n", nil
+		return "# " + heading + "\n\nNo work-items found.\n", nil
 	}
 	// Preserve order from WIQL; do not resort here
 	// Build Markdown table
 	var b bytes.Buffer
-	b.WriteString("# Work Items\n\n")
-	b.WriteString("| ID | Type | State | Assignee | Title |\n")
-	b.WriteString("|---:|:-----|:------|:---------|:------|\n")
+	b.WriteString("# " + heading + "\n\n")
+	b.WriteString("| ID | Type | State | Assignee | Title |" + extraColumnsHeader() + "\n")
+	b.WriteString("|---:|:-----|:------|:---------|:------|" + extraColumnsRule() + "\n")
 	// Resolve current user's displayName for bolding
 	meDisplay, _ := az.CurrentUserDisplayName()
 	for _, wi := range items {
@@ -395,9 +442,9 @@ func renderItems(items []queryItem) (string, error) {
 		// Avoid breaking the table by escaping pipes
 		title = strings.ReplaceAll(title, "|", "\\|")
 		if s == "Active" && ass == meDisplay && meDisplay != "" {
-			fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |\n", wi.ID, t, s, ass, title)
+			fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |%s\n", wi.ID, t, s, ass, title, extraColumnsCells(wi.Fields))
 		} else {
-			fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n", wi.ID, t, s, ass, title)
+			fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |%s\n", wi.ID, t, s, ass, title, extraColumnsCells(wi.Fields))
 		}
 	}
 	md := b.String()
@@ -430,8 +477,8 @@ func renderItemsTypeLess(items []queryItem, heading string) (string, error) {
 		heading = "Work Items"
 	}
 	b.WriteString("# " + heading + "\n\n")
-	b.WriteString("| ID | State | Assignee | Title |\n")
-	b.WriteString("|---:|:------|:---------|:------|\n")
+	b.WriteString("| ID | State | Assignee | Title |" + extraColumnsHeader() + "\n")
+	b.WriteString("|---:|:------|:---------|:------|" + extraColumnsRule() + "\n")
 	// Resolve current user's displayName for bolding
 	meDisplay, _ := az.CurrentUserDisplayName()
 	for _, wi := range items {
@@ -440,9 +487,9 @@ func renderItemsTypeLess(items []queryItem, heading string) (string, error) {
 		title := util.FieldString(wi.Fields, "System.Title")
 		title = strings.ReplaceAll(title, "|", "\\|")
 		if s == "Active" && ass == meDisplay && meDisplay != "" {
-			fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** |\n", wi.ID, s, ass, title)
+			fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** |%s\n", wi.ID, s, ass, title, extraColumnsCells(wi.Fields))
 		} else {
-			fmt.Fprintf(&b, "| %d | %s | %s | %s |\n", wi.ID, s, ass, title)
+			fmt.Fprintf(&b, "| %d | %s | %s | %s |%s\n", wi.ID, s, ass, title, extraColumnsCells(wi.Fields))
 		}
 	}
 	md := b.String()
@@ -500,8 +547,8 @@ func renderChildren(parent *az.WorkItem, items []queryItem) (string, error) {
 	} else {
 		sort.Slice(items, func(i, j int) bool { return items[i].ID > items[j].ID })
 		b.WriteString("# Work Items\n\n")
-		b.WriteString("| ID | Type | State | Assignee | Title |\n")
-		b.WriteString("|---:|:-----|:------|:---------|:------|\n")
+		b.WriteString("| ID | Type | State | Assignee | Title |" + extraColumnsHeader() + "\n")
+		b.WriteString("|---:|:-----|:------|:---------|:------|" + extraColumnsRule() + "\n")
 		for _, wi := range items {
 			t := util.FieldString(wi.Fields, "System.WorkItemType")
 			s := util.FieldString(wi.Fields, "System.State")
@@ -509,9 +556,9 @@ func renderChildren(parent *az.WorkItem, items []queryItem) (string, error) {
 			ass := assigneeDisplay(wi.Fields)
 			title = strings.ReplaceAll(title, "|", "\\|")
 			if s == "Active" && ass == meDisplay && meDisplay != "" {
-				fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |\n", wi.ID, t, s, ass, title)
+				fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |%s\n", wi.ID, t, s, ass, title, extraColumnsCells(wi.Fields))
 			} else {
-				fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n", wi.ID, t, s, ass, title)
+				fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |%s\n", wi.ID, t, s, ass, title, extraColumnsCells(wi.Fields))
 			}
 		}
 	}
@@ -532,6 +579,195 @@ func renderChildren(parent *az.WorkItem, items []queryItem) (string, error) {
 	return md, nil
 }
 
+// emitListItems writes items in the requested machine-readable format,
+// honoring --output and --output-pick (base is the default file name).
+func emitListItems(items []queryItem, base string) error {
+	path := strings.TrimSpace(listOutputPath)
+	if listOutputPick {
+		path = base + "." + formatExt()
+		if err := huhSavePath(&path); err != nil {
+			return err
+		}
+	}
+	return emitItems(itemRecords(items), path)
+}
+
+// resolveListFilters resolves --iteration and --area to the paths used by
+// listConditions.
+func resolveListFilters() error {
+	listIterationPath, listAreaPath = "", ""
+	listParentID, listChangedAfter = 0, time.Time{}
+	if p := strings.TrimSpace(listParent); p != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(p), "AB#"))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid --parent %q: expected a work item id", listParent)
+		}
+		listParentID = n
+	}
+	if strings.TrimSpace(listChangedSince) != "" {
+		t, err := parseSince(listChangedSince, time.Now())
+		if err != nil {
+			return fmt.Errorf("--changed-since: %w", err)
+		}
+		listChangedAfter = t
+	}
+	if strings.TrimSpace(listIteration) != "" {
+		p, err := iterationPath(listIteration)
+		if err != nil {
+			return err
+		}
+		listIterationPath = p
+	}
+	if strings.TrimSpace(listArea) != "" {
+		p, err := areaPath(listArea)
+		if err != nil {
+			return err
+		}
+		listAreaPath = p
+	}
+	return nil
+}
+
+// listConditions compiles the filter flags into WIQL conditions: --tag
+// into CONTAINS clauses, --iteration into UNDER, --area into = (or UNDER
+// with --under) and the rest as documented on listCmd. --state and --type
+// are compiled by stateCondition and typeCondition.
+func listConditions() []string {
+	var out []string
+	for _, t := range listTags {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, wiql.Contains("System.Tags", t))
+		}
+	}
+	if listIterationPath != "" {
+		out = append(out, wiql.Under("System.IterationPath", listIterationPath))
+	}
+	if listAreaPath != "" {
+		if listUnder {
+			out = append(out, wiql.Under("System.AreaPath", listAreaPath))
+		} else {
+			out = append(out, wiql.Eq("System.AreaPath", listAreaPath))
+		}
+	}
+	if a := strings.TrimSpace(listAssignee); a != "" {
+		out = append(out, wiql.Eq("System.AssignedTo", identityValue(a)))
+	}
+	if cols := nonEmpty(listColumns); len(cols) > 0 {
+		out = append(out, wiql.In("System.BoardColumn", wiql.Strings(cols)...))
+	}
+	if c := strings.TrimSpace(listCreatedBy); c != "" {
+		out = append(out, wiql.Eq("System.CreatedBy", identityValue(c)))
+	}
+	if !listChangedAfter.IsZero() {
+		out = append(out, wiql.Ge("System.ChangedDate", listChangedAfter))
+	}
+	if t := strings.TrimSpace(listTitleContains); t != "" {
+		out = append(out, wiql.Contains("System.Title", t))
+	}
+	if listParentID > 0 {
+		out = append(out, wiql.Eq("System.Parent", listParentID))
+	}
+	return out
+}
+
+// stateCondition selects the --state values, else non-Closed items unless
+// includeClosed.
+func stateCondition(includeClosed bool) string {
+	if states := nonEmpty(listStates); len(states) > 0 {
+		return wiql.In("System.State", wiql.Strings(states)...)
+	}
+	if includeClosed {
+		return ""
+	}
+	return wiql.Ne("System.State", "Closed")
+}
+
+// typeCondition selects typeFilter (the tasks/stories subcommands) and
+// the --type values.
+func typeCondition(typeFilter string) string {
+	var conds []string
+	if strings.TrimSpace(typeFilter) != "" {
+		conds = append(conds, wiql.Eq("System.WorkItemType", typeFilter))
+	}
+	if types := nonEmpty(listTypes); len(types) > 0 {
+		conds = append(conds, wiql.In("System.WorkItemType", wiql.Strings(types)...))
+	}
+	return wiql.And(conds...)
+}
+
+// identityValue maps @me to the @Me macro and none to the empty identity;
+// names and emails are compared as given.
+func identityValue(who string) any {
+	switch strings.ToLower(who) {
+	case "@me":
+		return wiql.Me
+	case "none", "@none", "unassigned":
+		return ""
+	}
+	return who
+}
+
+// nonEmpty returns the trimmed values of flags, splitting commas.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, v := range strings.Split(v, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// completeColumns completes the board columns of User Stories.
+func completeColumns(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	defer quietCompletion()()
+	return board.ColumnsFor("User Story"), cobra.ShellCompDirectiveNoFileComp
+}
+
+// extraColumns are the optional columns of the list tables: Area
+// (--show-area) and Tags (--show-tags).
+func extraColumns() []string {
+	var cols []string
+	if listShowArea {
+		cols = append(cols, "Area")
+	}
+	if listShowTags {
+		cols = append(cols, "Tags")
+	}
+	return cols
+}
+
+// extraColumnsHeader, extraColumnsRule and extraColumnsCells append the
+// optional columns to a table's header, rule and rows.
+func extraColumnsHeader() string {
+	var b strings.Builder
+	for _, c := range extraColumns() {
+		b.WriteString(" " + c + " |")
+	}
+	return b.String()
+}
+
+func extraColumnsRule() string {
+	return strings.Repeat(":-----|", len(extraColumns()))
+}
+
+func extraColumnsCells(fields map[string]any) string {
+	var b strings.Builder
+	for _, c := range extraColumns() {
+		var v string
+		switch c {
+		case "Area":
+			v = strings.ReplaceAll(util.FieldString(fields, "System.AreaPath"), `\`, `\\`)
+		case "Tags":
+			v = strings.Join(output.SplitTags(util.FieldString(fields, "System.Tags")), ", ")
+		}
+		b.WriteString(" " + escapePipes(v) + " |")
+	}
+	return b.String()
+}
+
 // huhSavePath prompts for a file path using huh; path is prefilled and updated.
 func huhSavePath(path *string) error {
 	inp := huh.NewInput().Title("Save markdown as").Value(path)
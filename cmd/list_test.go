@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_ListFilterFlags(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		p.Users = append(p.Users, azpkg.Identity{ID: "ann", DisplayName: "Ann O'Neil", UniqueName: "ann@example.com"})
+		p.SetClock(func() time.Time { return time.Now().AddDate(0, 0, -10) })
+		story := p.Add("User Story", "Login page", map[string]any{"System.AssignedTo": "me@example.com"})
+		p.SetClock(time.Now)
+		bug := p.Add("Bug", "Login fails for O'Neil", map[string]any{"System.AssignedTo": "ann@example.com"})
+		task := p.Add("Task", "Write docs", nil)
+		if err := p.SetParent(task, story); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.UpdateWorkItemFields(strconv.Itoa(bug), map[string]string{"System.State": "Active"}); err != nil {
+			t.Fatal(err)
+		}
+
+		defer func() {
+			listAssignee, listStates, listTypes, listColumns = "", nil, nil, nil
+			listCreatedBy, listChangedSince, listTitleContains, listParent = "", "", "", ""
+		}()
+		ids := func() []int {
+			t.Helper()
+			if err := resolveListFilters(); err != nil {
+				t.Fatal(err)
+			}
+			items, err := queryItems("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := []int{}
+			for _, it := range items {
+				out = append(out, it.ID)
+			}
+			sort.Ints(out)
+			return out
+		}
+		check := func(name string, want ...int) {
+			t.Helper()
+			if got := ids(); fmt.Sprint(got) != fmt.Sprint(append([]int{}, want...)) {
+				t.Fatalf("%s: got %v, want %v", name, got, want)
+			}
+		}
+
+		listAssignee = "@me"
+		check("--assignee @me", story)
+		listAssignee = "Ann O'Neil"
+		check("--assignee with a quote", bug)
+		listAssignee = "none"
+		check("--assignee none", task)
+		listAssignee = ""
+
+		listStates = []string{"Active"}
+		check("--state Active", bug)
+		listStates = []string{"New,Active"}
+		check("--state New,Active", story, bug, task)
+		listStates = nil
+
+		listTypes = []string{"Bug", "Task"}
+		check("--type Bug --type Task", bug, task)
+		listTypes = nil
+
+		listColumns = []string{"Backlog"}
+		check("--column Backlog", story)
+		listColumns = nil
+
+		listTitleContains = "o'neil"
+		check("--title-contains", bug)
+		listTitleContains = ""
+
+		listChangedSince = "2d"
+		check("--changed-since 2d", bug, task)
+		listChangedSince = ""
+
+		listParent = strconv.Itoa(story)
+		check("--parent", task)
+		listParent = "x"
+		if err := resolveListFilters(); err == nil {
+			t.Fatal("expected an error for --parent x")
+		}
+		listParent = ""
+
+		listCreatedBy = "@me"
+		check("--created-by @me", story, bug, task)
+	})
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/wiql"
+	"github.com/spf13/cobra"
+)
+
+var (
+	queryWIQL string
+	queryPick bool
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Run saved queries or WIQL",
+	Long: "Run a WIQL statement (--wiql) or, without it, pick a saved query and run it. " +
+		"Results are shown in the list tables, whatever columns the query selects; " +
+		"link (tree and one-hop) queries list every item in the order the query returns them. " +
+		"See `ab query list` and `ab query run`.",
+	Example: "  ab query --wiql \"SELECT [System.Id] FROM WorkItems WHERE [System.State] = 'Active'\"\n" +
+		"  ab query run \"Shared Queries/Team/Open bugs\" --pick",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(queryWIQL) != "" {
+			return runQuery(queryWIQL, "Query")
+		}
+		return queryRunCmd.RunE(queryRunCmd, nil)
+	},
+}
+
+var queryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the saved queries in My Queries and Shared Queries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		qs, err := az.SavedQueries()
+		if err != nil {
+			return err
+		}
+		if structured() {
+			var recs []output.SavedQuery
+			walkSavedQueries(qs, func(q az.SavedQuery) {
+				recs = append(recs, output.SavedQuery{ID: q.ID, Path: q.Path, Type: q.QueryType, Public: q.IsPublic})
+			})
+			return emitRecords(output.SavedQueryColumns, recs, "")
+		}
+		return renderMarkdown(savedQueriesMarkdown(qs))
+	},
+}
+
+var queryRunCmd = &cobra.Command{
+	Use:               "run [path|id]",
+	Short:             "Run a saved query (picker without argument)",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeSavedQueries,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ref string
+		if len(args) == 1 {
+			ref = args[0]
+		} else {
+			var err error
+			if ref, err = pickSavedQuery(); err != nil {
+				return err
+			}
+		}
+		q, err := az.GetSavedQuery(ref)
+		if err != nil {
+			return err
+		}
+		if q.IsFolder {
+			return fmt.Errorf("%s is a folder, not a query (see ab query list)", q.Path)
+		}
+		if strings.TrimSpace(q.WIQL) == "" {
+			return fmt.Errorf("query %s has no WIQL", q.Path)
+		}
+		return runQuery(q.WIQL, q.Name)
+	},
+}
+
+// runQuery runs stmt with the list fields selected and renders the items
+// under heading; with --pick a follow-up action can be taken on one.
+func runQuery(stmt, heading string) error {
+	w, err := selectListFields(stmt)
+	if err != nil {
+		return err
+	}
+	items, err := queryItemsByWIQL(w)
+	if err != nil {
+		return err
+	}
+	if structured() {
+		return emitItems(itemRecords(items), "")
+	}
+	if _, err := renderItemsHeading(items, heading); err != nil {
+		return err
+	}
+	if queryPick && len(items) > 0 {
+		return followUp(items)
+	}
+	return nil
+}
+
+// selectClause matches the SELECT list of a WIQL statement.
+var selectClause = regexp.MustCompile(`(?is)^\s*SELECT\s.*?\sFROM\s`)
+
+// selectListFields replaces the SELECT list of stmt with listFields so
+// that any query fills the list tables and records.
+func selectListFields(stmt string) (string, error) {
+	loc := selectClause.FindStringIndex(stmt)
+	if loc == nil {
+		return "", fmt.Errorf("not a WIQL query (expected SELECT ... FROM ...): %q", stmt)
+	}
+	fields := make([]string, 0, len(listFields))
+	for _, f := range listFields {
+		fields = append(fields, wiql.Field(f))
+	}
+	return "SELECT " + strings.Join(fields, ", ") + " FROM " + stmt[loc[1]:], nil
+}
+
+// followUps are the actions offered on a picked query result.
+var followUps = []struct {
+	label string
+	cmd   *cobra.Command
+}{
+	{"Show", showCmd},
+	{"Edit", editCmd},
+	{"Work on", workonCmd},
+	{"Forward", forwardCmd},
+	{"Backward", backwardCmd},
+	{"Resolve", resolveCmd},
+	{"Close", closeCmd},
+	{"Comments", commentsCmd},
+	{"History", historyCmd},
+}
+
+// followUp picks one of items and runs an action on it.
+func followUp(items []queryItem) error {
+	var id, action string
+	actions := make([]huh.Option[string], 0, len(followUps)+1)
+	for _, f := range followUps {
+		actions = append(actions, huh.NewOption(f.label, f.label))
+	}
+	actions = append(actions, huh.NewOption("Cancel", ""))
+	form := huh.NewForm(
+		huh.NewGroup(huh.NewSelect[string]().Title("Pick work-item").Options(itemOptions(items)...).Value(&id)),
+		huh.NewGroup(huh.NewSelect[string]().Title("Action").Options(actions...).Value(&action)),
+	)
+	if err := form.Run(); err != nil {
+		return err
+	}
+	for _, f := range followUps {
+		if f.label == action {
+			return f.cmd.RunE(f.cmd, []string{id})
+		}
+	}
+	return nil
+}
+
+// walkSavedQueries calls fn for every query (not folder) in qs, depth first.
+func walkSavedQueries(qs []az.SavedQuery, fn func(q az.SavedQuery)) {
+	for _, q := range qs {
+		if !q.IsFolder {
+			fn(q)
+		}
+		walkSavedQueries(q.Children, fn)
+	}
+}
+
+// savedQueriesMarkdown renders the query hierarchy as nested lists,
+// folders in bold.
+func savedQueriesMarkdown(qs []az.SavedQuery) string {
+	var b strings.Builder
+	b.WriteString("# Queries\n\n")
+	var walk func(qs []az.SavedQuery, depth int)
+	walk = func(qs []az.SavedQuery, depth int) {
+		for _, q := range qs {
+			indent := strings.Repeat("  ", depth)
+			if q.IsFolder {
+				fmt.Fprintf(&b, "%s- **%s**\n", indent, q.Name)
+				if len(q.Children) == 0 {
+					fmt.Fprintf(&b, "%s  - _(empty)_\n", indent)
+				}
+				walk(q.Children, depth+1)
+				continue
+			}
+			typ := ""
+			if q.QueryType != "" && q.QueryType != "flat" {
+				typ = " (" + q.QueryType + ")"
+			}
+			fmt.Fprintf(&b, "%s- %s%s\n", indent, q.Name, typ)
+		}
+	}
+	walk(qs, 0)
+	return b.String()
+}
+
+// pickSavedQuery shows a picker of saved query paths.
+func pickSavedQuery() (string, error) {
+	qs, err := az.SavedQueries()
+	if err != nil {
+		return "", err
+	}
+	var options []huh.Option[string]
+	walkSavedQueries(qs, func(q az.SavedQuery) { options = append(options, huh.NewOption(q.Path, q.ID)) })
+	if len(options) == 0 {
+		return "", fmt.Errorf("no saved queries")
+	}
+	var chosen string
+	if err := huh.NewForm(huh.NewGroup(huh.NewSelect[string]().Title("Pick query").Options(options...).Value(&chosen))).Run(); err != nil {
+		return "", err
+	}
+	if chosen == "" {
+		return "", fmt.Errorf("no query selected")
+	}
+	return chosen, nil
+}
+
+// completeSavedQueries completes the paths of saved queries.
+func completeSavedQueries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	defer quietCompletion()()
+	qs, err := az.SavedQueries()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var out []string
+	walkSavedQueries(qs, func(q az.SavedQuery) { out = append(out, q.Path) })
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	queryCmd.Flags().StringVar(&queryWIQL, "wiql", "", "Run this WIQL statement")
+	queryCmd.PersistentFlags().BoolVar(&queryPick, "pick", false, "Pick a result afterwards and run an action on it")
+	queryCmd.AddCommand(queryListCmd, queryRunCmd)
+	rootCmd.AddCommand(queryCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_QueriesOverREST(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		story := p.Add("User Story", "Checkout", nil)
+		task1 := p.Add("Task", "Cart", nil)
+		task2 := p.Add("Task", "Payment", nil)
+		bug := p.Add("Bug", "Total is wrong", nil)
+		for _, c := range []int{task1, task2} {
+			if err := p.SetParent(c, story); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := p.AddQuery("Shared Queries/Team/Open bugs",
+			"select [System.Id]\nfrom WorkItems\nwhere [System.WorkItemType] = 'Bug'"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.AddQuery("My Queries/Story tree",
+			"SELECT [System.Id] FROM WorkItemLinks WHERE [Source].[System.WorkItemType] = 'User Story'"+
+				" AND [System.Links.LinkType] = 'System.LinkTypes.Hierarchy-Forward' MODE (Recursive)"); err != nil {
+			t.Fatal(err)
+		}
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+
+		formatFlag = output.JSON
+		defer func() { formatFlag, queryWIQL = "", "" }()
+
+		var saved []output.SavedQuery
+		out := captureStdout(t, func() error { return queryListCmd.RunE(queryListCmd, nil) })
+		if err := json.Unmarshal([]byte(out), &saved); err != nil {
+			t.Fatalf("query list output is not JSON: %v\n%s", err, out)
+		}
+		var paths []string
+		for _, q := range saved {
+			paths = append(paths, q.Path+":"+q.Type)
+		}
+		if got := strings.Join(paths, ","); got != "My Queries/Story tree:tree,Shared Queries/Team/Open bugs:flat" {
+			t.Fatalf("saved queries = %s", got)
+		}
+
+		ids := func(args ...string) string {
+			t.Helper()
+			out := captureStdout(t, func() error {
+				if len(args) == 0 {
+					return queryCmd.RunE(queryCmd, nil)
+				}
+				return queryRunCmd.RunE(queryRunCmd, args)
+			})
+			var recs []output.Item
+			if err := json.Unmarshal([]byte(out), &recs); err != nil {
+				t.Fatalf("query output is not JSON: %v\n%s", err, out)
+			}
+			var got []string
+			for _, r := range recs {
+				got = append(got, strconv.Itoa(r.ID))
+			}
+			return strings.Join(got, ",")
+		}
+		if got, want := ids("Shared Queries/Team/Open bugs"), strconv.Itoa(bug); got != want {
+			t.Fatalf("flat query = %s, want %s", got, want)
+		}
+		if got, want := ids("My Queries/Story tree"), fmt.Sprintf("%d,%d,%d", story, task1, task2); got != want {
+			t.Fatalf("tree query = %s, want %s", got, want)
+		}
+		queryWIQL = "SELECT [System.Title] FROM WorkItems WHERE [System.Title] CONTAINS 'a' ORDER BY [System.Id]"
+		if got, want := ids(), fmt.Sprintf("%d,%d,%d", task1, task2, bug); got != want {
+			t.Fatalf("--wiql = %s, want %s", got, want)
+		}
+		if _, err := selectListFields("DELETE everything"); err == nil {
+			t.Fatal("non-SELECT statement accepted")
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/glamour"
 	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
 	"github.com/sa6mwa/ab/internal/term"
 	"github.com/sa6mwa/ab/internal/util"
 )
@@ -14,6 +15,9 @@ func renderWorkItem(title string, wi *az.WorkItem) error {
 	if wi == nil {
 		return fmt.Errorf("no work-item to render")
 	}
+	if structured() {
+		return emitItem(output.FromWorkItem(wi), "")
+	}
 	typ := util.FieldString(wi.Fields, "System.WorkItemType")
 	state := util.FieldString(wi.Fields, "System.State")
 	assigned := util.FieldString(wi.Fields, "System.AssignedTo")
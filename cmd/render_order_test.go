@@ -14,7 +14,7 @@ func TestRenderItems_PreservesOrder(t *testing.T) {
     defer azpkg.SetExecutorForTest(nil)
     azpkg.SetExecutorForTest(func(args ...string) ([]byte, error) {
         if len(args) >= 3 && args[0] == "ad" && args[1] == "signed-in-user" && args[2] == "show" {
-            return []byte("Me User\n"), nil
+            return []byte(`{"displayName":"Me User","userPrincipalName":"me@example.com"}`), nil
         }
         return []byte("{}"), nil
     })
@@ -38,7 +38,7 @@ func TestRenderItemsTypeLess_PreservesOrder(t *testing.T) {
     defer azpkg.SetExecutorForTest(nil)
     azpkg.SetExecutorForTest(func(args ...string) ([]byte, error) {
         if len(args) >= 3 && args[0] == "ad" && args[1] == "signed-in-user" && args[2] == "show" {
-            return []byte("Me User\n"), nil
+            return []byte(`{"displayName":"Me User","userPrincipalName":"me@example.com"}`), nil
         }
         return []byte("{}"), nil
     })
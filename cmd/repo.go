@@ -219,7 +219,7 @@ var repoDeleteCmd = &cobra.Command{
 			return err
 		}
 		// Prevent az interactive prompt; we already confirmed above (or user passed --yes)
-		return az.DeleteRepo(r.ID, true)
+		return az.DeleteRepo(r.ID)
 	},
 }
 
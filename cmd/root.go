@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sa6mwa/ab/internal/az"
 	"github.com/sa6mwa/ab/internal/board"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/util"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +23,11 @@ var rootCmd = &cobra.Command{
 		"author": "Michel Blomgren <michel.blomgren@nionit.com>",
 	},
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if !skipsProfile(cmd) {
+			if err := applyProfile(cmd); err != nil {
+				return err
+			}
+		}
 		if yesFlag {
 			if err := az.SetConfirmMode("never"); err != nil {
 				return err
@@ -30,6 +38,28 @@ var rootCmd = &cobra.Command{
 			}
 		}
 		az.SetSilent(silentFlag)
+		az.SetDryRun(dryRunFlag)
+		if !dryRunFlag {
+			az.StartJournal(os.Args[1:])
+		}
+		format, err := output.Normalize(formatFlag)
+		if err != nil {
+			return err
+		}
+		formatFlag = format
+		if backendFlag != "" {
+			if err := az.SetBackend(backendFlag); err != nil {
+				return err
+			}
+		}
+		if authFlag != "" {
+			if err := az.SetAuthMode(authFlag); err != nil {
+				return err
+			}
+		}
+		if noCacheFlag {
+			az.SetCacheEnabled(false)
+		}
 		if defaultColumnsFlag {
 			// Explicit flag overrides any AB_COLUMNS env setting
 			board.SetDefaultAgileColumns()
@@ -40,7 +70,15 @@ var rootCmd = &cobra.Command{
 
 // Execute runs the root command.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if az.DryRun() {
+		if perr := printPlan(); perr != nil && err == nil {
+			err = perr
+		}
+	}
+	// Let background cache revalidation finish, but never hold up exit long.
+	az.WaitBackground(2 * time.Second)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -50,6 +88,10 @@ var confirmFlag string
 var yesFlag bool
 var silentFlag bool
 var defaultColumnsFlag bool
+var backendFlag string
+var authFlag string
+var noCacheFlag bool
+var dryRunFlag bool
 
 // Global PO order toggle, affects pickers and listings where applicable
 var poOrderGlobal bool
@@ -63,19 +105,15 @@ func init() {
 	rootCmd.SetHelpTemplate(helpTmpl)
 	rootCmd.SetVersionTemplate("{{.Name}} {{.Version}}\nAuthor: {{index .Annotations \"author\"}}\n")
 
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Configuration profile to use (overrides AB_PROFILE and the current profile; see ab profile)")
 	rootCmd.PersistentFlags().StringVar(&confirmFlag, "confirm", "", "Confirmation mode: always|mutations|never (overrides AB_CONFIRM)")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Do not prompt; equivalent to --confirm never")
 	rootCmd.PersistentFlags().BoolVarP(&silentFlag, "silent", "s", false, "Silent mode: do not print az commands, only outputs")
 	rootCmd.PersistentFlags().BoolVarP(&defaultColumnsFlag, "default-columns", "d", false, "Use default Agile columns: New,Active,Resolved,Closed (overrides AB_COLUMNS)")
-	rootCmd.PersistentFlags().BoolVarP(&poOrderGlobal, "po-order", "P", envTrue("AB_PO_ORDER") || envTrue("AB_STACKRANK"), "Order by PO priority where possible (StackRank for Stories/Bugs). Can be set via AB_PO_ORDER=true or AB_STACKRANK=true; flag overrides if provided")
-}
-
-func envTrue(name string) bool {
-	v := strings.TrimSpace(strings.ToLower(os.Getenv(name)))
-	switch v {
-	case "1", "true", "yes", "y", "on":
-		return true
-	default:
-		return false
-	}
+	rootCmd.PersistentFlags().StringVar(&backendFlag, "backend", "", "Azure DevOps backend: az|rest (overrides AB_BACKEND)")
+	rootCmd.PersistentFlags().StringVar(&authFlag, "auth", "", "REST backend authentication: pat|az (overrides AB_AUTH; pat reads AZURE_DEVOPS_EXT_PAT)")
+	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "Bypass the disk cache and always query Azure DevOps (env AB_NO_CACHE=true; see ab cache)")
+	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Show the changes a command would make without making them; reads still run")
+	rootCmd.PersistentFlags().StringVarP(&formatFlag, "format", "F", os.Getenv("AB_FORMAT"), "Machine-readable output: "+strings.Join(output.Formats, "|")+" (default rendered Markdown; env AB_FORMAT)")
+	rootCmd.PersistentFlags().BoolVarP(&poOrderGlobal, "po-order", "P", util.EnvTrue("AB_PO_ORDER") || util.EnvTrue("AB_STACKRANK"), "Order by PO priority where possible (StackRank for Stories/Bugs). Can be set via AB_PO_ORDER=true or AB_STACKRANK=true; flag overrides if provided")
 }
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
 	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
 	"github.com/sa6mwa/ab/internal/term"
 	"github.com/sa6mwa/ab/internal/util"
 	"github.com/spf13/cobra"
@@ -18,6 +20,7 @@ import (
 var showIncludeAll bool
 var showOutputPath string
 var showOutputPick bool
+var showComments int
 
 var showCmd = &cobra.Command{
 	Use:   "show [id]",
@@ -43,104 +46,37 @@ var showCmd = &cobra.Command{
 			return fmt.Errorf("unable to inspect work item %s", id)
 		}
 
-		// If User Story, prefetch children to include at bottom of single document
-		var children []queryItem
-		if util.FieldString(wi.Fields, "System.WorkItemType") == "User Story" {
-			children, err = queryItemsByParent(fmt.Sprintf("%d", wi.ID), showIncludeAll)
-			if err != nil {
-				return err
-			}
-		}
-
-		// Build Markdown document with compact pseudo-headings
-		var b bytes.Buffer
-		title := util.FieldString(wi.Fields, "System.Title")
-		wtype := util.FieldString(wi.Fields, "System.WorkItemType")
-		state := util.FieldString(wi.Fields, "System.State")
-
-		// Created By display name
-		createdBy := createdByDisplay(wi.Fields)
-		if createdBy == "" {
-			createdBy = "(unknown)"
-		}
-
-		// Assignee display with NIL if none
-		assignee := assigneeDisplay(wi.Fields)
-		if strings.TrimSpace(assignee) == "" {
-			assignee = "NIL"
-		}
-
-		// Column (User Story only)
-		_, col := util.FindKanbanColumn(wi.Fields)
-
-		// Description and Acceptance Criteria converted from HTML -> Markdown
-		descHTML := util.FieldString(wi.Fields, "System.Description")
-		descMD := htmlToMarkdown(descHTML)
-		acMD := ""
-		if wtype == "User Story" {
-			acHTML := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.AcceptanceCriteria")
-			acMD = htmlToMarkdown(acHTML)
+		// Prefetch children to include at bottom of single document
+		children, err := showChildren(wi, showIncludeAll)
+		if err != nil {
+			return err
 		}
 
-		// Compose markdown
-		fmt.Fprintf(&b, "# %s AB#%d\n\n", wtype, wi.ID)
-		fmt.Fprintf(&b, "**Title:**  \n%s\n\n", strings.TrimSpace(title))
-		if wtype == "Bug" {
-			sev := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")
-			if strings.TrimSpace(sev) == "" {
-				sev = "NIL"
+		if structured() {
+			rec := output.FromWorkItem(wi)
+			if children != nil {
+				rec.Children = treeRecords(children.children, 0, formatFlag == output.JSON || formatFlag == output.YAML)
 			}
-			fmt.Fprintf(&b, "**Severity:**  \n%s\n\n", sev)
-		}
-		fmt.Fprintf(&b, "**Created By:**  \n%s\n\n", createdBy)
-		fmt.Fprintf(&b, "**Assignee:**  \n%s\n\n", assignee)
-		if wtype == "User Story" {
-			if strings.TrimSpace(col) == "" {
-				fmt.Fprintf(&b, "**Column:**  \nNIL\n\n")
-			} else {
-				fmt.Fprintf(&b, "**Column:**  \n%s\n\n", col)
+			path := strings.TrimSpace(showOutputPath)
+			if showOutputPick {
+				path = fmt.Sprintf("ab%d.%s", wi.ID, formatExt())
+				if err := huhSavePath(&path); err != nil {
+					return err
+				}
 			}
-		}
-		fmt.Fprintf(&b, "**State:**  \n%s\n\n", state)
-		if strings.TrimSpace(descMD) == "" {
-			fmt.Fprintf(&b, "**Description:**  \nNIL\n\n")
-		} else {
-			fmt.Fprintf(&b, "**Description:**  \n%s\n\n", descMD)
-		}
-		if wtype == "User Story" {
-			if strings.TrimSpace(acMD) == "" {
-				fmt.Fprintf(&b, "**Acceptance Criteria:**  \nNIL\n\n")
-			} else {
-				fmt.Fprintf(&b, "**Acceptance Criteria:**  \n%s\n\n", acMD)
+			if xp, err := util.ExpandTilde(path); err == nil {
+				path = xp
 			}
+			return emitItem(rec, path)
 		}
 
-		// Children section (User Story only), appended within same document
-		if wtype == "User Story" {
-			fmt.Fprintf(&b, "# Children\n\n")
-			if len(children) == 0 {
-				b.WriteString("No work-items found.\n")
-			} else {
-				// Resolve current user's displayName for bolding
-				meDisplay, _ := az.CurrentUserDisplayName()
-				sort.Slice(children, func(i, j int) bool { return children[i].ID > children[j].ID })
-				b.WriteString("| ID | Type | State | Assignee | Title |\n")
-				b.WriteString("|---:|:-----|:------|:---------|:------|\n")
-				for _, c := range children {
-					t := util.FieldString(c.Fields, "System.WorkItemType")
-					s := util.FieldString(c.Fields, "System.State")
-					ass := assigneeDisplay(c.Fields)
-					title := util.FieldString(c.Fields, "System.Title")
-					title = strings.ReplaceAll(title, "|", "\\|")
-					if s == "Active" && ass == meDisplay && meDisplay != "" {
-						fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |\n", c.ID, t, s, ass, title)
-					} else {
-						fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n", c.ID, t, s, ass, title)
-					}
-				}
+		var comments []az.Comment
+		if showComments > 0 {
+			if comments, err = az.Comments(id, showComments); err != nil {
+				return err
 			}
-			b.WriteString("\n")
 		}
+		md := showMarkdown(wi, children, comments, showComments > 0)
 
 		// Render document
 		r, err := glamour.NewTermRenderer(
@@ -151,7 +87,6 @@ var showCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		md := b.String()
 		out, err := r.Render(md)
 		if err != nil {
 			return err
@@ -190,11 +125,211 @@ var showCmd = &cobra.Command{
 
 func init() { rootCmd.AddCommand(showCmd) }
 func init() {
-	showCmd.Flags().StringVarP(&showOutputPath, "output", "o", "", "Write generated Markdown to file")
+	showCmd.Flags().StringVarP(&showOutputPath, "output", "o", "", "Write generated Markdown (or --format output) to file")
 	showCmd.Flags().BoolVarP(&showOutputPick, "output-pick", "O", false, "Pick output file path interactively")
 }
 func init() {
 	showCmd.Flags().BoolVarP(&showIncludeAll, "all", "a", false, "Include Closed children in the list")
+	showCmd.Flags().IntVarP(&showComments, "comments", "c", 0, "Include the latest N comments of the Discussion")
+}
+
+// showChildren fetches what show lists below wi: the children of a User
+// Story, or the hierarchy below an Epic or Feature with its progress. It is
+// nil for other types.
+func showChildren(wi *az.WorkItem, includeClosed bool) (*treeNode, error) {
+	switch util.FieldString(wi.Fields, "System.WorkItemType") {
+	case "User Story":
+		items, err := childItems(wi, includeClosed)
+		if err != nil {
+			return nil, err
+		}
+		return leafNodes(items), nil
+	case "Epic", "Feature":
+		nodes, err := workItemTree(wi.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes {
+			if n.item.ID == wi.ID {
+				if !includeClosed {
+					n.children = keepOpen(n.children)
+				}
+				return n, nil
+			}
+		}
+		return &treeNode{}, nil
+	}
+	return nil, nil
+}
+
+// childItems fetches the children linked from wi in one batch request,
+// without the Closed ones unless includeClosed.
+func childItems(wi *az.WorkItem, includeClosed bool) ([]queryItem, error) {
+	var ids []int
+	for _, r := range wi.Relations {
+		if r.Rel != "System.LinkTypes.Hierarchy-Forward" {
+			continue
+		}
+		if n, err := strconv.Atoi(r.URL[strings.LastIndex(r.URL, "/")+1:]); err == nil {
+			ids = append(ids, n)
+		}
+	}
+	items, err := batchItems(ids)
+	if err != nil {
+		return nil, fmt.Errorf("fetch children failed: %w", err)
+	}
+	if includeClosed {
+		return items, nil
+	}
+	open := items[:0]
+	for _, it := range items {
+		if util.FieldString(it.Fields, "System.State") != "Closed" {
+			open = append(open, it)
+		}
+	}
+	return open, nil
+}
+
+// showMarkdown builds the Markdown document of `ab show`: the item's
+// details followed, for User Stories, Features and Epics, by a table of the
+// children below tree and, when withComments is set, by the given (latest)
+// comments. Features and Epics also show the progress rolled up in tree.
+func showMarkdown(wi *az.WorkItem, tree *treeNode, comments []az.Comment, withComments bool) string {
+	// Build Markdown document with compact pseudo-headings
+	var b bytes.Buffer
+	title := util.FieldString(wi.Fields, "System.Title")
+	wtype := util.FieldString(wi.Fields, "System.WorkItemType")
+	state := util.FieldString(wi.Fields, "System.State")
+
+	// Created By display name
+	createdBy := createdByDisplay(wi.Fields)
+	if createdBy == "" {
+		createdBy = "(unknown)"
+	}
+
+	// Assignee display with NIL if none
+	assignee := assigneeDisplay(wi.Fields)
+	if strings.TrimSpace(assignee) == "" {
+		assignee = "NIL"
+	}
+
+	// Column (User Story only)
+	_, col := util.FindKanbanColumn(wi.Fields)
+
+	// Description and Acceptance Criteria converted from HTML -> Markdown
+	descHTML := util.FieldString(wi.Fields, "System.Description")
+	descMD := htmlToMarkdown(descHTML)
+	acMD := ""
+	if wtype == "User Story" {
+		acHTML := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.AcceptanceCriteria")
+		acMD = htmlToMarkdown(acHTML)
+	}
+
+	// Compose markdown
+	fmt.Fprintf(&b, "# %s AB#%d\n\n", wtype, wi.ID)
+	fmt.Fprintf(&b, "**Title:**  \n%s\n\n", strings.TrimSpace(title))
+	if wtype == "Bug" {
+		sev := util.FieldString(wi.Fields, "Microsoft.VSTS.Common.Severity")
+		if strings.TrimSpace(sev) == "" {
+			sev = "NIL"
+		}
+		fmt.Fprintf(&b, "**Severity:**  \n%s\n\n", sev)
+	}
+	fmt.Fprintf(&b, "**Created By:**  \n%s\n\n", createdBy)
+	fmt.Fprintf(&b, "**Assignee:**  \n%s\n\n", assignee)
+	if wtype == "User Story" {
+		if strings.TrimSpace(col) == "" {
+			fmt.Fprintf(&b, "**Column:**  \nNIL\n\n")
+		} else {
+			fmt.Fprintf(&b, "**Column:**  \n%s\n\n", col)
+		}
+	}
+	fmt.Fprintf(&b, "**State:**  \n%s\n\n", state)
+	portfolio := wtype == "Epic" || wtype == "Feature"
+	if portfolio && tree != nil && tree.total > 0 {
+		fmt.Fprintf(&b, "**Progress:**  \n%d/%d done (%d%%)\n\n", tree.done, tree.total, tree.done*100/tree.total)
+	}
+	if strings.TrimSpace(descMD) == "" {
+		fmt.Fprintf(&b, "**Description:**  \nNIL\n\n")
+	} else {
+		fmt.Fprintf(&b, "**Description:**  \n%s\n\n", descMD)
+	}
+	if wtype == "User Story" {
+		if strings.TrimSpace(acMD) == "" {
+			fmt.Fprintf(&b, "**Acceptance Criteria:**  \nNIL\n\n")
+		} else {
+			fmt.Fprintf(&b, "**Acceptance Criteria:**  \n%s\n\n", acMD)
+		}
+	}
+
+	// Children section, appended within same document
+	if wtype == "User Story" || portfolio {
+		fmt.Fprintf(&b, "# Children\n\n")
+		var children []*treeNode
+		if tree != nil {
+			children = tree.children
+		}
+		if len(children) == 0 {
+			b.WriteString("No work-items found.\n")
+		} else {
+			// Resolve current user's displayName for bolding
+			meDisplay, _ := az.CurrentUserDisplayName()
+			if !portfolio {
+				sort.Slice(children, func(i, j int) bool { return children[i].item.ID > children[j].item.ID })
+			}
+			b.WriteString("| ID | Type | State | Assignee | Title |")
+			if portfolio {
+				b.WriteString(" Done |")
+			}
+			b.WriteString("\n|---:|:-----|:------|:---------|:------|")
+			if portfolio {
+				b.WriteString("-----:|")
+			}
+			b.WriteString("\n")
+			for _, n := range children {
+				c := n.item
+				t := util.FieldString(c.Fields, "System.WorkItemType")
+				s := util.FieldString(c.Fields, "System.State")
+				ass := assigneeDisplay(c.Fields)
+				title := util.FieldString(c.Fields, "System.Title")
+				title = strings.ReplaceAll(title, "|", "\\|")
+				if s == "Active" && ass == meDisplay && meDisplay != "" {
+					fmt.Fprintf(&b, "| **%d** | **%s** | **%s** | **%s** | **%s** |", c.ID, t, s, ass, title)
+				} else {
+					fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |", c.ID, t, s, ass, title)
+				}
+				if portfolio {
+					done := ""
+					if n.total > 0 {
+						done = fmt.Sprintf("%d/%d", n.done, n.total)
+					}
+					fmt.Fprintf(&b, " %s |", done)
+				}
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if withComments {
+		fmt.Fprintf(&b, "# Discussion\n\n")
+		if n := commentCount(wi.Fields); n > len(comments) {
+			fmt.Fprintf(&b, "Latest %d of %d comments; see `ab comments %d`.\n\n", len(comments), n, wi.ID)
+		}
+		b.WriteString(commentsMarkdown(comments))
+	}
+	return b.String()
+}
+
+// commentCount returns System.CommentCount, or 0 when absent.
+func commentCount(fields map[string]interface{}) int {
+	switch v := fields["System.CommentCount"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
 }
 
 // createdByDisplay extracts System.CreatedBy.displayName when present
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/term"
+	"github.com/sa6mwa/ab/internal/wiql"
+	"github.com/spf13/cobra"
+)
+
+var sprintShowAll bool
+
+var sprintCmd = &cobra.Command{
+	Use:   "sprint",
+	Short: "List the team's iterations (sprints)",
+	Long:  "List the iterations of the team with their dates; the current one is highlighted. See `ab sprint show` and `ab sprint move`.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		its, err := az.TeamIterations()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if structured() {
+			recs := make([]output.Iteration, 0, len(its))
+			for _, it := range its {
+				recs = append(recs, iterationRecord(it, now))
+			}
+			return emitRecords(output.IterationColumns, recs, "")
+		}
+		return renderMarkdown(sprintsMarkdown(its, now))
+	},
+}
+
+var sprintShowCmd = &cobra.Command{
+	Use:               "show [name|@current]",
+	Short:             "List the work-items of a sprint (default @current)",
+	Args:              cobra.RangeArgs(0, 1),
+	ValidArgsFunction: completeIterations,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := az.CurrentIterationMacro
+		if len(args) == 1 {
+			name = args[0]
+		}
+		it, err := az.ResolveIteration(name)
+		if err != nil {
+			return err
+		}
+		items, err := queryItemsByWIQL(sprintWIQL(it.Path, sprintShowAll))
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return emitItems(itemRecords(items), "")
+		}
+		_, err = renderItemsHeading(items, strings.TrimSpace(it.Name+" "+iterationDates(*it)))
+		return err
+	},
+}
+
+var sprintMoveCmd = &cobra.Command{
+	Use:   "move <id...> <iteration>",
+	Short: "Move work-items to an iteration (name, path or @current)",
+	Args:  cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeIterations(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, name := args[:len(args)-1], args[len(args)-1]
+		path, err := iterationPath(name)
+		if err != nil {
+			return err
+		}
+		var recs []output.Item
+		for _, id := range ids {
+			id = strings.TrimSpace(id)
+			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.IterationPath": path})
+			if err != nil {
+				return err
+			}
+			if structured() {
+				recs = append(recs, mutationRecord(id, raw, "moved"))
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Moved %s to %s\n", id, path)
+		}
+		if structured() {
+			return emitItems(recs, "")
+		}
+		return nil
+	},
+}
+
+// sprintWIQL selects the items of the iteration at path, Closed ones only
+// with includeClosed.
+func sprintWIQL(path string, includeClosed bool) string {
+	q := wiql.Select(listFields...).Where(wiql.Eq("System.IterationPath", path))
+	if !includeClosed {
+		q.Where(wiql.Ne("System.State", "Closed"))
+	}
+	if poOrderGlobal {
+		return q.OrderBy(orderStackRank, orderChanged).String()
+	}
+	return q.OrderBy(orderChanged).String()
+}
+
+// iterationPath resolves an iteration name, path or @current to the path
+// stored in System.IterationPath. Paths (containing a backslash) are used as
+// given so that iterations outside the team's selection can be targeted.
+func iterationPath(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, `\`) {
+		return strings.Trim(name, `\`), nil
+	}
+	it, err := az.ResolveIteration(name)
+	if err != nil {
+		return "", err
+	}
+	return it.Path, nil
+}
+
+// sprintsMarkdown renders the iterations as a table, the current one in bold.
+func sprintsMarkdown(its []az.Iteration, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("# Sprints\n\n")
+	if len(its) == 0 {
+		b.WriteString("The team has no iterations selected.\n")
+		return b.String()
+	}
+	b.WriteString("| Sprint | Start | Finish | Path |\n")
+	b.WriteString("|:-------|:------|:-------|:-----|\n")
+	for _, it := range its {
+		start, finish := iterationDay(it.Attributes.StartDate), iterationDay(it.Attributes.FinishDate)
+		path := escapePipes(strings.ReplaceAll(it.Path, `\`, `\\`))
+		if it.IsCurrent(now) {
+			fmt.Fprintf(&b, "| **%s** (current) | **%s** | **%s** | **%s** |\n", escapePipes(it.Name), start, finish, path)
+		} else {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", escapePipes(it.Name), start, finish, path)
+		}
+	}
+	return b.String()
+}
+
+// iterationDates formats the date range of it for headings.
+func iterationDates(it az.Iteration) string {
+	if it.Attributes.StartDate == nil || it.Attributes.FinishDate == nil {
+		return ""
+	}
+	return fmt.Sprintf("(%s – %s)", iterationDay(it.Attributes.StartDate), iterationDay(it.Attributes.FinishDate))
+}
+
+func iterationDay(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func iterationRecord(it az.Iteration, now time.Time) output.Iteration {
+	rec := output.Iteration{Name: it.Name, Path: it.Path, TimeFrame: it.Attributes.TimeFrame, Current: it.IsCurrent(now)}
+	if it.Attributes.StartDate != nil {
+		rec.Start = iterationDay(it.Attributes.StartDate)
+	}
+	if it.Attributes.FinishDate != nil {
+		rec.Finish = iterationDay(it.Attributes.FinishDate)
+	}
+	return rec
+}
+
+// completeIterations completes iteration names and @current.
+func completeIterations(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	defer quietCompletion()()
+	its, err := az.TeamIterations()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	out := []string{az.CurrentIterationMacro}
+	for _, it := range its {
+		out = append(out, it.Name)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// renderMarkdown prints md rendered for the terminal.
+func renderMarkdown(md string) error {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(term.DetectWidth()),
+		glamour.WithPreservedNewLines(),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := r.Render(md)
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
+
+func init() {
+	sprintShowCmd.Flags().BoolVarP(&sprintShowAll, "all", "a", false, "Include Closed items")
+	sprintCmd.AddCommand(sprintShowCmd, sprintMoveCmd)
+	rootCmd.AddCommand(sprintCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_Sprints(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		a := p.Add("User Story", "In sprint", map[string]any{"System.IterationPath": `Fake\Sprint 2`})
+		b := p.Add("Bug", "Unplanned", nil)
+
+		formatFlag = output.JSON
+		defer func() { formatFlag = "" }()
+		var its []output.Iteration
+		out := captureStdout(t, func() error { return sprintCmd.RunE(sprintCmd, nil) })
+		if err := json.Unmarshal([]byte(out), &its); err != nil {
+			t.Fatalf("sprint output is not JSON: %v\n%s", err, out)
+		}
+		if len(its) != 3 || its[0].Current || !its[1].Current || its[1].Path != `Fake\Sprint 2` {
+			t.Fatalf("sprints = %+v", its)
+		}
+
+		var items []output.Item
+		out = captureStdout(t, func() error { return sprintShowCmd.RunE(sprintShowCmd, nil) })
+		if err := json.Unmarshal([]byte(out), &items); err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].ID != a {
+			t.Fatalf("@current items = %+v", items)
+		}
+
+		captureStdout(t, func() error { return sprintMoveCmd.RunE(sprintMoveCmd, []string{"2", "sprint 3"}) })
+		if got := p.Field(b, "System.IterationPath"); got != `Fake\Sprint 3` {
+			t.Fatalf("moved to %q", got)
+		}
+		if err := sprintMoveCmd.RunE(sprintMoveCmd, []string{"2", "Sprint 9"}); err == nil {
+			t.Fatal("expected an error for an unknown sprint")
+		}
+
+		createIteration = "@current"
+		defer func() { createIteration, createIterationPath = "", "" }()
+		captureStdout(t, func() error { return createStoryCmd.RunE(createStoryCmd, []string{"Planned"}) })
+		if got := p.Field(3, "System.IterationPath"); got != `Fake\Sprint 2` {
+			t.Fatalf("created in %q", got)
+		}
+
+		listIteration = "Sprint 2"
+		defer func() { listIteration, listIterationPath = "", "" }()
+		if err := resolveListFilters(); err != nil {
+			t.Fatal(err)
+		}
+		list, err := queryItems("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 {
+			t.Fatalf("list --iteration matched %+v", list)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/sa6mwa/ab/internal/az"
 	"github.com/sa6mwa/ab/internal/util"
@@ -14,44 +16,22 @@ var resolveCmd = &cobra.Command{
 	Short: "Set work-item state to Resolved",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var ids []string
-		if len(args) == 1 {
-			ids = []string{args[0]}
-		} else {
-			var err error
-			ids, err = pickNonClosedIDs()
-			if err != nil {
-				return err
-			}
+		ids, err := bulkIDs(args)
+		if err != nil {
+			return err
 		}
-		for _, id := range ids {
-			_, cur, err := az.ShowWorkItem(id)
-			if err != nil {
-				return err
-			}
-			if cur == nil {
-				return fmt.Errorf("unable to inspect work item %s", id)
-			}
+		cur, err := fetchItems(ids)
+		if err != nil {
+			return err
+		}
+		results := runBulk("Resolving", ids, func(id string) ([]byte, error) {
 			target := "Resolved"
-			if util.FieldString(cur.Fields, "System.WorkItemType") == "Task" {
+			if util.FieldString(cur[id].Fields, "System.WorkItemType") == "Task" {
 				target = "Closed"
 			}
-			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.State": target})
-			if err != nil {
-				return err
-			}
-			var wi az.WorkItem
-			if err := json.Unmarshal(raw, &wi); err == nil {
-				if err := renderWorkItem("Resolved", &wi); err != nil {
-					return err
-				}
-			} else {
-				if err := az.PrintJSON(raw); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+			return az.UpdateWorkItemFields(id, map[string]string{"System.State": target})
+		})
+		return reportBulk(results, cur, "resolved", renderUpdated("Resolved"))
 	},
 }
 
@@ -60,33 +40,7 @@ var renewCmd = &cobra.Command{
 	Short: "Set work-item state to New",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var ids []string
-		if len(args) == 1 {
-			ids = []string{args[0]}
-		} else {
-			var err error
-			ids, err = pickNonClosedIDs()
-			if err != nil {
-				return err
-			}
-		}
-		for _, id := range ids {
-			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.State": "New"})
-			if err != nil {
-				return err
-			}
-			var wi az.WorkItem
-			if err := json.Unmarshal(raw, &wi); err == nil {
-				if err := renderWorkItem("Renewed", &wi); err != nil {
-					return err
-				}
-			} else {
-				if err := az.PrintJSON(raw); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+		return setStateBulk(args, "New", "Renewing", "renewed", "Renewed")
 	},
 }
 
@@ -95,33 +49,7 @@ var closeCmd = &cobra.Command{
 	Short: "Set work-item state to Closed",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var ids []string
-		if len(args) == 1 {
-			ids = []string{args[0]}
-		} else {
-			var err error
-			ids, err = pickNonClosedIDs()
-			if err != nil {
-				return err
-			}
-		}
-		for _, id := range ids {
-			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.State": "Closed"})
-			if err != nil {
-				return err
-			}
-			var wi az.WorkItem
-			if err := json.Unmarshal(raw, &wi); err == nil {
-				if err := renderWorkItem("Closed", &wi); err != nil {
-					return err
-				}
-			} else {
-				if err := az.PrintJSON(raw); err != nil {
-					return err
-				}
-			}
-		}
-		return nil
+		return setStateBulk(args, "Closed", "Closing", "closed", "Closed")
 	},
 }
 
@@ -130,33 +58,86 @@ var deleteCmd = &cobra.Command{
 	Short: "Delete a work-item",
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var ids []string
-		if len(args) == 1 {
-			ids = []string{args[0]}
-		} else {
-			var err error
-			ids, err = pickNonClosedIDs()
-			if err != nil {
-				return err
-			}
+		ids, err := bulkIDs(args)
+		if err != nil {
+			return err
 		}
-		for _, id := range ids {
-			raw, err := az.DeleteWorkItem(id)
-			if err != nil {
-				return err
-			}
-			fmt.Fprintf(cmd.ErrOrStderr(), "Deleted AB#%s\n", id)
-			if err := az.PrintJSON(raw); err != nil {
-				return err
-			}
+		cur, err := fetchItems(ids)
+		if err != nil {
+			return err
 		}
-		return nil
+		results := runBulk("Deleting", ids, az.DeleteWorkItem)
+		return reportBulk(results, cur, "deleted", func(id string, raw []byte) error {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Deleted AB#%s %s\n", id, util.FieldString(cur[id].Fields, "System.Title"))
+			return az.PrintJSON(raw)
+		})
 	},
 }
 
+// setStateBulk sets state on the id argument or the picked ids; verb is
+// the progress label, action the record action and title the heading of
+// each rendered item.
+func setStateBulk(args []string, state, verb, action, title string) error {
+	ids, err := bulkIDs(args)
+	if err != nil {
+		return err
+	}
+	cur, err := fetchItems(ids)
+	if err != nil {
+		return err
+	}
+	results := runBulk(verb, ids, func(id string) ([]byte, error) {
+		return az.UpdateWorkItemFields(id, map[string]string{"System.State": state})
+	})
+	return reportBulk(results, cur, action, renderUpdated(title))
+}
+
+// renderUpdated renders an updated work-item under title, or its raw JSON
+// when it cannot be decoded.
+func renderUpdated(title string) func(id string, raw []byte) error {
+	return func(id string, raw []byte) error {
+		var wi az.WorkItem
+		if err := json.Unmarshal(raw, &wi); err != nil {
+			return az.PrintJSON(raw)
+		}
+		return renderWorkItem(title, &wi)
+	}
+}
+
+// fetchItems reads ids with one batch request per 200 before a bulk command
+// changes them, so that an unknown id stops it before the first change. All
+// fields are read, the Kanban column among them, and noted for the journal
+// so that it need not read each item again.
+func fetchItems(ids []string) (map[string]az.WorkItem, error) {
+	nums := make([]int, 0, len(ids))
+	for _, id := range ids {
+		n, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil {
+			return nil, fmt.Errorf("invalid work item id %q", id)
+		}
+		nums = append(nums, n)
+	}
+	wis, err := az.WorkItemsBatch(nums, nil, az.ExpandNone)
+	if err != nil {
+		return nil, err
+	}
+	az.NoteCurrent(wis...)
+	byID := make(map[string]az.WorkItem, len(wis))
+	for _, wi := range wis {
+		byID[strconv.Itoa(wi.ID)] = wi
+	}
+	for i, id := range ids {
+		if _, ok := byID[strconv.Itoa(nums[i])]; !ok {
+			return nil, fmt.Errorf("work item %s not found", id)
+		}
+		byID[id] = byID[strconv.Itoa(nums[i])]
+	}
+	return byID, nil
+}
+
 func init() {
-	rootCmd.AddCommand(resolveCmd)
-	rootCmd.AddCommand(renewCmd)
-	rootCmd.AddCommand(closeCmd)
-	rootCmd.AddCommand(deleteCmd)
+	for _, c := range []*cobra.Command{resolveCmd, renewCmd, closeCmd, deleteCmd} {
+		addBulkFlags(c)
+		rootCmd.AddCommand(c)
+	}
 }
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_ResolveAndCloseByType(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Story", nil)
+		task := p.Add("Task", "Task", nil)
+		if err := resolveCmd.RunE(resolveCmd, []string{"1"}); err != nil {
+			t.Fatalf("resolve story: %v", err)
+		}
+		if err := resolveCmd.RunE(resolveCmd, []string{"2"}); err != nil {
+			t.Fatalf("resolve task: %v", err)
+		}
+		if got := p.Field(story, "System.State"); got != "Resolved" {
+			t.Fatalf("story state = %q", got)
+		}
+		if got := p.Field(task, "System.State"); got != "Closed" {
+			t.Fatalf("task state = %q", got)
+		}
+		items, err := queryItems("")
+		if err != nil {
+			t.Fatalf("queryItems: %v", err)
+		}
+		if len(items) != 1 || items[0].ID != story {
+			t.Fatalf("expected only the open story, got %+v", items)
+		}
+	})
+}
+
+func TestFake_BatchReads(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		story := p.Add("User Story", "Story", nil)
+		var tasks []int
+		for i := range 30 {
+			id := p.Add("Task", fmt.Sprintf("Task %d", i), nil)
+			if err := p.SetParent(id, story); err != nil {
+				t.Fatal(err)
+			}
+			tasks = append(tasks, id)
+		}
+		if _, err := p.UpdateWorkItemFields(strconv.Itoa(tasks[0]), map[string]string{"System.State": "Closed"}); err != nil {
+			t.Fatal(err)
+		}
+		c := &countingBackend{Project: p}
+		defer azpkg.Use(c)()
+
+		cur, err := fetchItems([]string{strconv.Itoa(tasks[1]), strconv.Itoa(story)})
+		if err != nil || c.batches != 1 || c.shows != 0 {
+			t.Fatalf("fetchItems: %d batches, %d shows, %v", c.batches, c.shows, err)
+		}
+		if got := cur[strconv.Itoa(story)].Fields["System.WorkItemType"]; got != "User Story" {
+			t.Fatalf("type = %v", got)
+		}
+		if _, err := fetchItems([]string{strconv.Itoa(tasks[1]), "9999"}); err == nil || !strings.Contains(err.Error(), "9999") {
+			t.Fatalf("unknown id: %v", err)
+		}
+		formatFlag = output.JSON
+		defer func() { formatFlag, showIncludeAll = "", false }()
+		c.batches = 0
+		captureStdout(t, func() error { return resolveCmd.RunE(resolveCmd, []string{strconv.Itoa(tasks[1])}) })
+		if c.batches != 1 || c.shows != 0 || p.Field(tasks[1], "System.State") != "Closed" {
+			t.Fatalf("resolve: %d batches, %d shows, state %s", c.batches, c.shows, p.Field(tasks[1], "System.State"))
+		}
+		c.shows = 0
+		captureStdout(t, func() error { return forwardCmd.RunE(forwardCmd, []string{strconv.Itoa(story)}) })
+		if c.shows != 1 || p.Field(story, fake.KanbanField) != "Ready for Development" {
+			t.Fatalf("forward: %d shows, column %s", c.shows, p.Field(story, fake.KanbanField))
+		}
+
+		c.batches, c.queries = 0, 0
+		children, err := queryItemsByParent(strconv.Itoa(story), false)
+		if err != nil || len(children) != 28 || c.queries != 1 || c.batches != 1 {
+			t.Fatalf("queryItemsByParent: %d children, %d queries, %d batches, %v", len(children), c.queries, c.batches, err)
+		}
+
+		c.batches, c.queries, c.shows = 0, 0, 0
+		var rec output.Item
+		out := captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{strconv.Itoa(story)}) })
+		if err := json.Unmarshal([]byte(out), &rec); err != nil {
+			t.Fatalf("show output is not JSON: %v\n%s", err, out)
+		}
+		if len(rec.Children) != 28 || c.shows != 1 || c.batches != 1 || c.queries != 0 {
+			t.Fatalf("show: %d children, %d shows, %d batches, %d queries", len(rec.Children), c.shows, c.batches, c.queries)
+		}
+		formatFlag = ""
+		captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{strconv.Itoa(story)}) })
+		if c.comments != 0 {
+			t.Fatalf("show without -c read the comments %d times", c.comments)
+		}
+		formatFlag = output.JSON
+		showIncludeAll = true
+		out = captureStdout(t, func() error { return showCmd.RunE(showCmd, []string{strconv.Itoa(story)}) })
+		rec = output.Item{}
+		if err := json.Unmarshal([]byte(out), &rec); err != nil || len(rec.Children) != 30 {
+			t.Fatalf("show --all: %d children, %v", len(rec.Children), err)
+		}
+	})
+}
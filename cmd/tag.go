@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <id...> +tag... -tag...",
+	Short: "Add (+tag) or remove (-tag) tags on work-items",
+	Long: "Add and remove tags on one or more work-items, e.g. `ab tag 12 34 +backend -triage`. " +
+		"IDs come first; everything after them is read as tag changes, so global flags must precede the IDs.",
+	Args: cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if strings.HasPrefix(toComplete, "+") || strings.HasPrefix(toComplete, "-") {
+			return completeTags(toComplete[:1])(cmd, args, toComplete[1:])
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids, add, remove, err := parseTagArgs(args)
+		if err != nil {
+			return err
+		}
+		var recs []output.Item
+		for _, id := range ids {
+			_, wi, err := az.ShowWorkItem(id)
+			if err != nil {
+				return err
+			}
+			before := util.FieldString(wi.Fields, "System.Tags")
+			tags := applyTagChanges(output.SplitTags(before), add, remove)
+			after := strings.Join(tags, "; ")
+			if strings.EqualFold(after, strings.Join(output.SplitTags(before), "; ")) {
+				if structured() {
+					rec := output.FromWorkItem(wi)
+					rec.Action = "unchanged"
+					recs = append(recs, rec)
+				} else {
+					fmt.Fprintf(os.Stderr, "%s unchanged: %s\n", id, formatTags(before))
+				}
+				continue
+			}
+			raw, err := az.UpdateWorkItemFields(id, map[string]string{"System.Tags": after})
+			if err != nil {
+				return err
+			}
+			if structured() {
+				recs = append(recs, mutationRecord(id, raw, "tagged"))
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "Tagged %s: %s\n", id, formatTags(after))
+		}
+		if structured() {
+			return emitItems(recs, "")
+		}
+		return nil
+	},
+}
+
+// parseTagArgs splits tag arguments into work-item IDs, followed by tags to
+// add (+tag) and remove (-tag).
+func parseTagArgs(args []string) (ids, add, remove []string, err error) {
+	for _, a := range args {
+		a = strings.TrimSpace(a)
+		switch {
+		case a == "":
+		case strings.HasPrefix(a, "+") && len(a) > 1:
+			add = append(add, strings.TrimSpace(a[1:]))
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+			remove = append(remove, strings.TrimSpace(a[1:]))
+		default:
+			if _, convErr := strconv.Atoi(a); convErr != nil {
+				return nil, nil, nil, fmt.Errorf("%q is neither an ID nor a tag change (+tag or -tag)", a)
+			}
+			if len(add)+len(remove) > 0 {
+				return nil, nil, nil, fmt.Errorf("ID %s after tag changes; put IDs first", a)
+			}
+			ids = append(ids, a)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, nil, fmt.Errorf("no work-item ID given")
+	}
+	if len(add)+len(remove) == 0 {
+		return nil, nil, nil, fmt.Errorf("no tag changes given (+tag or -tag)")
+	}
+	return ids, add, remove, nil
+}
+
+// applyTagChanges removes and then adds tags, comparing case-insensitively
+// like Azure DevOps. Existing tags keep their order; new ones are appended.
+func applyTagChanges(tags, add, remove []string) []string {
+	out := make([]string, 0, len(tags)+len(add))
+	for _, t := range tags {
+		if !containsFold(remove, t) {
+			out = append(out, t)
+		}
+	}
+	for _, t := range add {
+		if t != "" && !containsFold(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// quietCompletion keeps a completion function from prompting or printing,
+// as it runs under the shell; defer the restore function it returns.
+func quietCompletion() (restore func()) {
+	az.SetSilent(true)
+	return az.WithConfirmMode(az.ConfirmNever)
+}
+
+// completeTags completes tag names from the project's tags, prefixed with
+// prefix ("+", "-" or none).
+func completeTags(prefix string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		defer quietCompletion()()
+		tags, err := az.Tags()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var out []string
+		for _, t := range tags {
+			if strings.HasPrefix(strings.ToLower(t), strings.ToLower(toComplete)) {
+				out = append(out, prefix+t)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func init() {
+	// Stop flag parsing at the first ID so that -tag reaches RunE.
+	tagCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(tagCmd)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestFake_TagsAddRemoveAndFilter(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		a := p.Add("User Story", "Alpha", map[string]any{"System.Tags": "triage; ui"})
+		b := p.Add("Bug", "Beta", nil)
+		p.Add("User Story", "Gamma", map[string]any{"System.Tags": "backend"})
+
+		if err := tagCmd.RunE(tagCmd, []string{"1", "2", "+Backend", "-TRIAGE"}); err != nil {
+			t.Fatalf("tag: %v", err)
+		}
+		if got := p.Field(a, "System.Tags"); got != "ui; Backend" {
+			t.Fatalf("tags of %d = %q", a, got)
+		}
+		if got := p.Field(b, "System.Tags"); got != "Backend" {
+			t.Fatalf("tags of %d = %q", b, got)
+		}
+		if err := tagCmd.RunE(tagCmd, []string{"+x", "1"}); err == nil {
+			t.Fatal("expected an error for an ID after tag changes")
+		}
+
+		listTags = []string{"backend", "ui"}
+		listShowTags = true
+		defer func() { listTags, listShowTags = nil, false }()
+		items, err := queryItems("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 1 || items[0].ID != a {
+			t.Fatalf("--tag backend --tag ui matched %+v", items)
+		}
+		var md string
+		captureStdout(t, func() error { md, err = renderItems(items); return err })
+		if !strings.Contains(md, "| Title | Tags |") || !strings.Contains(md, "| ui, Backend |") {
+			t.Fatalf("tags column missing:\n%s", md)
+		}
+
+		got, _ := completeTags("+")(tagCmd, []string{"1"}, "b")
+		if len(got) != 1 || got[0] != "+backend" && got[0] != "+Backend" {
+			t.Fatalf("completion = %v", got)
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/output"
+	"github.com/sa6mwa/ab/internal/util"
+	"github.com/sa6mwa/ab/internal/wiql"
+	"github.com/spf13/cobra"
+)
+
+var (
+	treeAll   bool
+	treeDepth int
+	treeMine  bool
+)
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [id]",
+	Short: "Show the hierarchy of Epics, Features, Stories, Tasks and Bugs as a tree",
+	Long: "Show the work-items below id, or every non-Closed hierarchy of the project, as an indented tree with type, " +
+		"state (column for User Stories), assignee and how many of the items below each one are done (Resolved or Closed). " +
+		"Closed items are counted but only shown with -a/--all. --depth limits the levels shown below the top; " +
+		"--mine keeps only your items and the items above them.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := 0
+		if len(args) == 1 {
+			n, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(args[0])), "AB#"))
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid work item id %q", args[0])
+			}
+			root = n
+		}
+		nodes, err := workItemTree(root)
+		if err != nil {
+			return err
+		}
+		if treeMine {
+			me, err := az.CurrentUser()
+			if err != nil {
+				return fmt.Errorf("get current user: %w", err)
+			}
+			nodes = keepTree(nodes, func(n *treeNode) bool { return assignedTo(n.item.Fields, me) })
+		}
+		if !treeAll {
+			nodes = keepOpen(nodes)
+		}
+		if structured() {
+			return emitItems(treeRecords(nodes, 0, formatFlag == output.JSON || formatFlag == output.YAML), "")
+		}
+		return renderMarkdown(treeMarkdown(root, nodes))
+	},
+}
+
+// treeNode is a work item in a hierarchy with the items below it; done and
+// total count all of those, Closed ones included.
+type treeNode struct {
+	item        queryItem
+	children    []*treeNode
+	done, total int
+}
+
+// workItemTree fetches the hierarchy below root, or every hierarchy whose
+// top item is not Closed when root is 0, with a recursive WorkItemLinks
+// query and a batch request for the fields.
+func workItemTree(root int) ([]*treeNode, error) {
+	q := wiql.SelectLinks("System.Id").Where(wiql.Eq(wiql.LinkType, wiql.Hierarchy))
+	if root != 0 {
+		q.Where(wiql.Eq(wiql.Source("System.Id"), root))
+	} else if !treeAll {
+		q.Where(wiql.Ne(wiql.Source("System.State"), "Closed"))
+	}
+	links, err := az.QueryLinks(q.Mode(wiql.Recursive).String())
+	if err != nil {
+		return nil, fmt.Errorf("query hierarchy failed: %w", err)
+	}
+	var ids []int
+	seen := map[int]bool{}
+	for _, l := range links {
+		if !seen[l.Target] {
+			seen[l.Target] = true
+			ids = append(ids, l.Target)
+		}
+	}
+	items, err := batchItems(ids)
+	if err != nil {
+		return nil, fmt.Errorf("fetch hierarchy failed: %w", err)
+	}
+	byID := make(map[int]*treeNode, len(items))
+	for _, it := range items {
+		byID[it.ID] = &treeNode{item: it}
+	}
+	// A top-level row of an item that is also linked below another one is
+	// only shown there.
+	nested := map[int]bool{}
+	for _, l := range links {
+		parent, child := byID[l.Source], byID[l.Target]
+		if l.Source == 0 || parent == nil || child == nil || nested[l.Target] || l.Source == l.Target {
+			continue
+		}
+		nested[l.Target] = true
+		parent.children = append(parent.children, child)
+	}
+	var out []*treeNode
+	for _, l := range links {
+		if n := byID[l.Target]; l.Source == 0 && n != nil && !nested[l.Target] {
+			nested[l.Target] = true
+			out = append(out, n)
+		}
+	}
+	for _, n := range out {
+		rollup(n)
+	}
+	return out, nil
+}
+
+// leafNodes wraps items as the children of a node without rollups.
+func leafNodes(items []queryItem) *treeNode {
+	n := &treeNode{children: make([]*treeNode, 0, len(items))}
+	for _, it := range items {
+		n.children = append(n.children, &treeNode{item: it})
+	}
+	return n
+}
+
+// rollup counts the items below n and how many of them are done.
+func rollup(n *treeNode) {
+	n.done, n.total = 0, 0
+	for _, c := range n.children {
+		rollup(c)
+		n.total += 1 + c.total
+		n.done += c.done
+		if isDone(c.item.Fields) {
+			n.done++
+		}
+	}
+}
+
+// isDone reports whether an item is Resolved or Closed.
+func isDone(fields map[string]any) bool {
+	switch util.FieldString(fields, "System.State") {
+	case "Resolved", "Closed", "Done":
+		return true
+	}
+	return false
+}
+
+// assignedTo reports whether fields are assigned to me, by display or
+// unique name.
+func assignedTo(fields map[string]any, me *az.Identity) bool {
+	a := output.FromFields(0, fields).Assignee
+	return a != "" && (strings.EqualFold(a, me.DisplayName) || strings.EqualFold(a, me.UniqueName))
+}
+
+// keepTree keeps the nodes for which keep holds and the nodes above them.
+// The counts stay those of the whole hierarchy.
+func keepTree(nodes []*treeNode, keep func(*treeNode) bool) []*treeNode {
+	var out []*treeNode
+	for _, n := range nodes {
+		kids := keepTree(n.children, keep)
+		if len(kids) > 0 || keep(n) {
+			cp := *n
+			cp.children = kids
+			out = append(out, &cp)
+		}
+	}
+	return out
+}
+
+// keepOpen drops the Closed nodes, and what is below them.
+func keepOpen(nodes []*treeNode) []*treeNode {
+	var out []*treeNode
+	for _, n := range nodes {
+		if util.FieldString(n.item.Fields, "System.State") == "Closed" {
+			continue
+		}
+		cp := *n
+		cp.children = keepOpen(n.children)
+		out = append(out, &cp)
+	}
+	return out
+}
+
+// treeMarkdown renders nodes as nested lists, treeDepth levels below the
+// top (all when 0).
+func treeMarkdown(root int, nodes []*treeNode) string {
+	var b strings.Builder
+	if root != 0 {
+		fmt.Fprintf(&b, "# Tree of AB#%d\n\n", root)
+	} else {
+		b.WriteString("# Tree\n\n")
+	}
+	if len(nodes) == 0 {
+		b.WriteString("No work-items found.\n")
+		return b.String()
+	}
+	var write func(ns []*treeNode, depth int)
+	write = func(ns []*treeNode, depth int) {
+		for _, n := range ns {
+			fmt.Fprintf(&b, "%s- %s\n", strings.Repeat("  ", depth), treeLine(n))
+			if treeDepth == 0 || depth < treeDepth {
+				write(n.children, depth+1)
+			}
+		}
+	}
+	write(nodes, 0)
+	return b.String()
+}
+
+// treeLine describes a node: type, id, title, state or column, assignee
+// and the rolled-up progress.
+func treeLine(n *treeNode) string {
+	rec := output.FromFields(n.item.ID, n.item.Fields)
+	state := rec.State
+	if rec.Type == "User Story" && rec.Column != "" {
+		state = rec.Column
+	}
+	parts := []string{fmt.Sprintf("**%s** AB#%d %s", rec.Type, rec.ID, escapeMarkdown(rec.Title)), state}
+	if rec.Assignee != "" {
+		parts = append(parts, rec.Assignee)
+	}
+	if n.total > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d done", n.done, n.total))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// escapeMarkdown escapes the characters that would format a title.
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`).Replace(s)
+}
+
+// treeRecords converts nodes, treeDepth levels below the top, to records:
+// nested as children, or listed after their parent.
+func treeRecords(nodes []*treeNode, depth int, nested bool) []output.Item {
+	out := []output.Item{}
+	for _, n := range nodes {
+		rec := output.FromFields(n.item.ID, n.item.Fields)
+		rec.URL = n.item.URL
+		var kids []output.Item
+		if treeDepth == 0 || depth < treeDepth {
+			kids = treeRecords(n.children, depth+1, nested)
+		}
+		if nested {
+			rec.Children = kids
+			out = append(out, rec)
+			continue
+		}
+		out = append(out, rec)
+		out = append(out, kids...)
+	}
+	return out
+}
+
+func init() {
+	treeCmd.Flags().BoolVarP(&treeAll, "all", "a", false, "Show Closed items too")
+	treeCmd.Flags().IntVarP(&treeDepth, "depth", "L", 0, "Show `n` levels below the top (0 for all)")
+	treeCmd.Flags().BoolVar(&treeMine, "mine", false, "Only your items and the items above them")
+	rootCmd.AddCommand(treeCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	azpkg "github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+	"github.com/sa6mwa/ab/internal/output"
+)
+
+func TestFake_Tree(t *testing.T) {
+	withFake(t, func(p *fake.Project) {
+		epic := p.Add("Epic", "Shop", nil)
+		feature := p.Add("Feature", "Payments", nil)
+		story := p.Add("User Story", "Checkout", nil)
+		mine := p.Add("Task", "Card form", map[string]any{"System.AssignedTo": "me@example.com"})
+		closed := p.Add("Task", "Spike", map[string]any{"System.State": "Closed"})
+		other := p.Add("User Story", "Refunds", nil)
+		lone := p.Add("Bug", "Logo is blurry", nil)
+		for child, parent := range map[int]int{feature: epic, story: feature, mine: story, closed: story, other: feature} {
+			if err := p.SetParent(child, parent); err != nil {
+				t.Fatal(err)
+			}
+		}
+		defer func() { treeAll, treeDepth, treeMine, formatFlag = false, 0, false, "" }()
+		ref := func(id int) string { return "AB#" + strconv.Itoa(id) + " " }
+
+		out := captureStdout(t, func() error { return treeCmd.RunE(treeCmd, nil) })
+		for _, want := range []string{"**Epic** " + ref(epic) + "Shop · New · 1/5 done", "**Feature** " + ref(feature) + "Payments · New · 1/4 done", "**User Story** " + ref(story) + "Checkout · Backlog · 1/2 done", "**Task** " + ref(mine) + "Card form · New · Me User", "**Bug** " + ref(lone)} {
+			if !strings.Contains(out, want) {
+				t.Fatalf("tree lacks %q:\n%s", want, out)
+			}
+		}
+		if strings.Index(out, ref(epic)) > strings.Index(out, ref(feature)) || strings.Index(out, ref(story)) > strings.Index(out, ref(mine)) {
+			t.Fatalf("tree order:\n%s", out)
+		}
+		if strings.Contains(out, ref(closed)) || strings.Count(out, ref(story)) != 1 {
+			t.Fatalf("tree shows Closed or repeated items:\n%s", out)
+		}
+
+		treeDepth = 1
+		out = captureStdout(t, func() error { return treeCmd.RunE(treeCmd, []string{strconv.Itoa(epic)}) })
+		if !strings.Contains(out, ref(feature)) || strings.Contains(out, ref(story)) || strings.Contains(out, ref(lone)) {
+			t.Fatalf("tree --depth 1 of the epic:\n%s", out)
+		}
+
+		treeDepth, treeMine, treeAll = 0, true, true
+		formatFlag = output.JSON
+		out = captureStdout(t, func() error { return treeCmd.RunE(treeCmd, nil) })
+		var recs []output.Item
+		if err := json.Unmarshal([]byte(out), &recs); err != nil {
+			t.Fatalf("json: %v\n%s", err, out)
+		}
+		ids := func(items []output.Item) (ids []int) {
+			for _, it := range items {
+				ids = append(ids, it.ID)
+			}
+			return ids
+		}
+		if len(recs) != 1 || recs[0].ID != epic || !slices.Equal(ids(recs[0].Children[0].Children), []int{story}) ||
+			!slices.Equal(ids(recs[0].Children[0].Children[0].Children), []int{mine}) {
+			t.Fatalf("tree --mine = %s", out)
+		}
+
+		t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+		srv := p.NewServer()
+		defer srv.Close()
+		defer azpkg.Use(azpkg.NewRESTBackend(p.BaseURL, p.Name))()
+		treeMine, treeAll, formatFlag = false, false, output.IDs
+		out = captureStdout(t, func() error { return treeCmd.RunE(treeCmd, []string{strconv.Itoa(feature)}) })
+		want := []string{strconv.Itoa(feature), strconv.Itoa(story), strconv.Itoa(mine), strconv.Itoa(other)}
+		if got := strings.Fields(out); len(got) != 4 || got[0] != want[0] || !slices.Equal(slices.Sorted(slices.Values(got)), slices.Sorted(slices.Values(want))) {
+			t.Fatalf("tree of the feature over REST = %v", got)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// classificationNode is a node of the area (or iteration) tree.
+type classificationNode struct {
+	Name     string               `json:"name"`
+	Children []classificationNode `json:"children"`
+}
+
+// flatten returns the paths of n and its descendants in tree order, in the
+// form used by System.AreaPath (Project\Component\Sub).
+func (n classificationNode) flatten(prefix string) []string {
+	path := n.Name
+	if prefix != "" {
+		path = prefix + `\` + n.Name
+	}
+	out := []string{path}
+	for _, c := range n.Children {
+		out = append(out, c.flatten(path)...)
+	}
+	return out
+}
+
+// AreaPaths returns the area paths of the project, the project root first.
+func AreaPaths() ([]string, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.Areas()
+}
+
+// FindAreaPath picks name from paths: an exact path (case-insensitive) or
+// the unique path ending in name, e.g. "Mobile" for Project\Frontend\Mobile.
+func FindAreaPath(paths []string, name string) (string, error) {
+	name = strings.Trim(strings.TrimSpace(name), `\`)
+	if name == "" {
+		return "", fmt.Errorf("no area given")
+	}
+	var matches []string
+	for _, p := range paths {
+		if strings.EqualFold(p, name) {
+			return p, nil
+		}
+		if strings.HasSuffix(strings.ToLower(p), `\`+strings.ToLower(name)) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("area %q not found (known: %s)", name, strings.Join(paths, ", "))
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("area %q is ambiguous: %s", name, strings.Join(matches, ", "))
+}
+
+func areasURL(projectURL string) string {
+	return withVersion(projectURL + "/_apis/wit/classificationnodes/Areas?$depth=20")
+}
+
+func decodeAreas(raw []byte) ([]string, error) {
+	var root classificationNode
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, err
+	}
+	if root.Name == "" {
+		return nil, fmt.Errorf("no area tree in response")
+	}
+	return root.flatten(""), nil
+}
+
+func (cliBackend) Areas() ([]string, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRestGET(areasURL(base))
+	if err != nil {
+		return nil, err
+	}
+	return decodeAreas(raw)
+}
+
+func (c *restClient) Areas() ([]string, error) {
+	raw, err := c.getJSON(areasURL(c.projectURL()))
+	if err != nil {
+		return nil, err
+	}
+	return decodeAreas(raw)
+}
@@ -0,0 +1,192 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// AttachedFileRel is the relation type linking an attachment to a work item.
+const AttachedFileRel = "AttachedFile"
+
+// AttachmentRef is an uploaded attachment, not yet linked to a work item.
+type AttachmentRef struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// Attachment is a file attached to a work item.
+type Attachment struct {
+	ID      string
+	Name    string
+	Size    int64
+	Created time.Time
+	Comment string
+	URL     string
+}
+
+// UploadAttachment uploads the file at path under name and returns the
+// reference to link with LinkAttachment.
+func UploadAttachment(path, name string) (*AttachmentRef, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.UploadAttachment(path, name)
+}
+
+// LinkAttachment attaches an uploaded attachment to a work item and returns
+// the updated work item.
+func LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.LinkAttachment(id, attachmentURL, comment)
+}
+
+// DownloadAttachment saves the content of an attachment to dest.
+func DownloadAttachment(attachmentURL, dest string) error {
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	return b.DownloadAttachment(attachmentURL, dest)
+}
+
+// Attachments lists the files attached to wi in relation order.
+func Attachments(wi *WorkItem) []Attachment {
+	var out []Attachment
+	for _, r := range wi.Relations {
+		if r.Rel != AttachedFileRel {
+			continue
+		}
+		a := Attachment{URL: r.URL, ID: attachmentID(r.URL)}
+		if v, ok := r.Attributes["name"].(string); ok {
+			a.Name = v
+		}
+		if v, ok := r.Attributes["comment"].(string); ok {
+			a.Comment = v
+		}
+		if v, ok := r.Attributes["resourceSize"].(float64); ok {
+			a.Size = int64(v)
+		}
+		for _, k := range []string{"resourceCreatedDate", "authorizedDate"} {
+			if v, ok := r.Attributes[k].(string); ok {
+				if t, err := time.Parse(time.RFC3339, v); err == nil {
+					a.Created = t
+					break
+				}
+			}
+		}
+		if a.Name == "" {
+			a.Name = a.ID
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// attachmentID is the last path segment of an attachment URL.
+func attachmentID(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	return u[strings.LastIndex(u, "/")+1:]
+}
+
+func attachmentsURL(projectURL, name string) string {
+	return withVersion(projectURL + "/_apis/wit/attachments?fileName=" + url.QueryEscape(name))
+}
+
+// attachmentOp is the JSON Patch operation linking an attachment.
+func attachmentOp(attachmentURL, comment string) patchOp {
+	rel := map[string]any{"rel": AttachedFileRel, "url": attachmentURL}
+	if comment != "" {
+		rel["attributes"] = map[string]any{"comment": comment}
+	}
+	return patchOp{Op: "add", Path: "/relations/-", Value: rel}
+}
+
+// transferClient returns the native client used by the CLI backend for
+// attachment content: az rest reads @file bodies as text and writes
+// responses through its JSON output, so binary files cannot pass through it.
+// The client authenticates with the az login (or AZURE_DEVOPS_EXT_PAT).
+func transferClient() (*restClient, error) {
+	org, project, err := azDevOpsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newRESTClient(org, project), nil
+}
+
+func (cliBackend) UploadAttachment(path, name string) (*AttachmentRef, error) {
+	c, err := transferClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadAttachment(path, name)
+}
+
+func (cliBackend) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal([]patchOp{attachmentOp(attachmentURL, comment)})
+	if err != nil {
+		return nil, err
+	}
+	u := withVersion(base + "/_apis/wit/workitems/" + url.PathEscape(id))
+	return runAz(restArgs("patch", u, "application/json-patch+json", body)...)
+}
+
+func (cliBackend) DownloadAttachment(attachmentURL, dest string) error {
+	c, err := transferClient()
+	if err != nil {
+		return err
+	}
+	return c.DownloadAttachment(attachmentURL, dest)
+}
+
+func (c *restClient) UploadAttachment(path, name string) (*AttachmentRef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := c.do(http.MethodPost, attachmentsURL(c.projectURL(), name), data, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+	var ref AttachmentRef
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		return nil, fmt.Errorf("decode attachment: %w", err)
+	}
+	if ref.URL == "" {
+		return nil, fmt.Errorf("upload of %s returned no attachment url", name)
+	}
+	return &ref, nil
+}
+
+func (c *restClient) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	return c.patchJSON(http.MethodPatch, withVersion(c.projectURL()+"/_apis/wit/workitems/"+url.PathEscape(id)), []patchOp{attachmentOp(attachmentURL, comment)})
+}
+
+func (c *restClient) DownloadAttachment(attachmentURL, dest string) error {
+	u := attachmentURL
+	if !strings.Contains(u, "api-version=") {
+		u = withVersion(u)
+	}
+	if !strings.Contains(u, "download=") {
+		u += "&download=true"
+	}
+	data, err := c.request(http.MethodGet, u, nil, "", "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
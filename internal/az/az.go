@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 
 	shellescape "al.essio.dev/pkg/shellescape"
 	"github.com/charmbracelet/huh"
@@ -24,9 +25,11 @@ var silent bool
 // SetSilent controls whether to print the az command lines.
 func SetSilent(s bool) { silent = s }
 
-// SetExecutorForTest overrides the az executor. Intended for tests.
+// SetExecutorForTest overrides the az executor and disables the disk cache
+// so stubbed calls are not answered from earlier runs. Intended for tests.
 func SetExecutorForTest(exec func(args ...string) ([]byte, error)) {
 	azExec = exec
+	SetCacheEnabled(false)
 }
 
 // Confirmation modes
@@ -48,7 +51,7 @@ func init() {
 
 // SetConfirmMode sets the current confirmation policy (always|mutations|never).
 func SetConfirmMode(mode string) error {
-	m, err := parseConfirmMode(mode)
+	m, err := ParseConfirmMode(mode)
 	if err != nil {
 		return err
 	}
@@ -56,7 +59,20 @@ func SetConfirmMode(mode string) error {
 	return nil
 }
 
-func parseConfirmMode(mode string) (ConfirmMode, error) {
+// CurrentConfirmMode returns the confirmation policy in effect.
+func CurrentConfirmMode() ConfirmMode { return confirmMode }
+
+// WithConfirmMode sets the confirmation policy and returns a function
+// restoring the previous one.
+func WithConfirmMode(m ConfirmMode) (restore func()) {
+	prev := confirmMode
+	confirmMode = m
+	return func() { confirmMode = prev }
+}
+
+// ParseConfirmMode parses a confirmation policy without setting it; empty
+// means the current one.
+func ParseConfirmMode(mode string) (ConfirmMode, error) {
 	v := strings.ToLower(strings.TrimSpace(mode))
 	switch v {
 	case "always", "all", "true", "on", "1", "yes", "y":
@@ -73,26 +89,48 @@ func parseConfirmMode(mode string) (ConfirmMode, error) {
 }
 
 // runAz prints and confirms the az command before execution, then returns stdout or error.
-func runAz(args ...string) ([]byte, error) {
+func runAz(args ...string) ([]byte, error) { return runAzQuiet(false, args...) }
+
+// runAzQuiet is runAz that, when quiet, does not print the command line;
+// background revalidation uses it while a picker is on screen.
+func runAzQuiet(quiet bool, args ...string) ([]byte, error) {
 	// Print a safe-to-shell-copy command line using shellescape
 	cmdline := shellescape.QuoteCommand(append([]string{"az"}, args...))
-	if !silent {
-		fmt.Fprintln(os.Stderr, cmdline)
+	if DryRun() && isMutation(args) {
+		return nil, errDryRun(cmdline)
 	}
-	if shouldConfirm(args) {
-		var proceed bool
-		confirm := huh.NewConfirm().Title("Run this command?").Description(cmdline).Affirmative("Yes").Negative("No").Value(&proceed)
-		form := huh.NewForm(huh.NewGroup(confirm))
-		if err := form.Run(); err != nil {
-			return nil, err
-		}
-		if !proceed {
-			return nil, ErrCancelled
-		}
+	if err := announce(cmdline, shouldConfirm(args), quiet); err != nil {
+		return nil, err
 	}
 	return azExec(args...)
 }
 
+// announceMu keeps concurrent calls (bulk commands) from interleaving
+// their command lines and prompts.
+var announceMu sync.Mutex
+
+// announce prints cmdline unless silent or quiet and, when confirm is true,
+// asks the user to approve it. Returns ErrCancelled if the user declines.
+func announce(cmdline string, confirm, quiet bool) error {
+	announceMu.Lock()
+	defer announceMu.Unlock()
+	if !silent && !quiet {
+		fmt.Fprintln(os.Stderr, cmdline)
+	}
+	if !confirm {
+		return nil
+	}
+	var proceed bool
+	c := huh.NewConfirm().Title("Run this command?").Description(cmdline).Affirmative("Yes").Negative("No").Value(&proceed)
+	if err := huh.NewForm(huh.NewGroup(c)).Run(); err != nil {
+		return err
+	}
+	if !proceed {
+		return ErrCancelled
+	}
+	return nil
+}
+
 // realAzExec executes the az command and returns stdout or error with stderr context.
 func realAzExec(args ...string) ([]byte, error) {
 	cmd := exec.Command("az", args...)
@@ -112,80 +150,137 @@ func shouldConfirm(args []string) bool {
 	case ConfirmAlways:
 		return true
 	case ConfirmMutations:
-		if len(args) == 0 {
-			return false
-		}
-		// az rest --method <m>
-		if args[0] == "rest" {
-			for i := 1; i < len(args); i++ {
-				if args[i] == "--method" && i+1 < len(args) {
-					m := strings.ToLower(args[i+1])
-					return m != "get"
+		return isMutation(args)
+	default:
+		return true
+	}
+}
+
+// isMutation reports whether an az command line changes something.
+func isMutation(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	// az rest --method <m>
+	if args[0] == "rest" {
+		for i := 1; i < len(args); i++ {
+			if args[i] == "--method" && i+1 < len(args) {
+				m := strings.ToLower(args[i+1])
+				if m == "post" && (readOnlyPOST(args) || attachmentUpload(args)) {
+					return false
 				}
+				return m != "get"
 			}
-			// No explicit method: be conservative
-			return true
 		}
-		// az boards work-item <create|update|delete>
-		for i := 0; i+2 < len(args); i++ {
-			if args[i] == "boards" && args[i+1] == "work-item" {
-				a := strings.ToLower(args[i+2])
-				if a == "create" || a == "update" || a == "delete" {
-					return true
-				}
-				if a == "relation" {
-					// relation add/remove/delete are mutations
-					if i+3 < len(args) {
-						op := strings.ToLower(args[i+3])
-						return op == "add" || op == "remove" || op == "delete"
-					}
+		// No explicit method: be conservative
+		return true
+	}
+	// az boards work-item <create|update|delete>
+	for i := 0; i+2 < len(args); i++ {
+		if args[i] == "boards" && args[i+1] == "work-item" {
+			a := strings.ToLower(args[i+2])
+			if a == "create" || a == "update" || a == "delete" {
+				return true
+			}
+			if a == "relation" {
+				// relation add/remove/delete are mutations
+				if i+3 < len(args) {
+					op := strings.ToLower(args[i+3])
+					return op == "add" || op == "remove" || op == "delete"
 				}
-				return false
 			}
+			return false
 		}
-		// Other commands are treated as reads by default
-		return false
-	default:
-		return true
 	}
+	// Other commands are treated as reads by default
+	return false
+}
+
+// readOnlyPOST reports whether an az rest call targets an endpoint that uses
+// POST only to carry a query (WIQL, batch reads).
+func readOnlyPOST(args []string) bool {
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--url" && i+1 < len(args) {
+			u := strings.ToLower(args[i+1])
+			return strings.Contains(u, "/_apis/wit/wiql") || strings.Contains(u, "/_apis/wit/workitemsbatch")
+		}
+	}
+	return false
+}
+
+// attachmentUpload reports whether an az rest call uploads an attachment.
+// An upload changes no work item by itself; linking it is the mutation that
+// is confirmed.
+func attachmentUpload(args []string) bool {
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--url" && i+1 < len(args) {
+			return strings.Contains(strings.ToLower(args[i+1]), "/_apis/wit/attachments")
+		}
+	}
+	return false
 }
 
 func formatAz(args []string) string { return shellescape.QuoteCommand(append([]string{"az"}, args...)) }
 
-// CurrentUserUPN returns the signed-in user's principal name (email) via az ad.
+// CurrentUserUPN returns the signed-in user's principal name (email).
 func CurrentUserUPN() (string, error) {
-	out, err := runAz("ad", "signed-in-user", "show", "--query", "userPrincipalName", "-o", "tsv")
+	me, err := CurrentUser()
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(out)), nil
+	return me.UniqueName, nil
 }
 
-// CurrentUserDisplayName returns the signed-in user's display name via az ad.
+// CurrentUserDisplayName returns the signed-in user's display name.
 func CurrentUserDisplayName() (string, error) {
-	out, err := runAz("ad", "signed-in-user", "show", "--query", "displayName", "-o", "tsv")
+	me, err := CurrentUser()
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(out)), nil
+	return me.DisplayName, nil
+}
+
+// CurrentUser returns the signed-in identity from the active backend.
+func CurrentUser() (*Identity, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.CurrentUser()
 }
 
-// QueryWIQL returns the raw JSON output from az boards query for the provided WIQL string.
+// QueryWIQL returns the raw JSON output (an array of work items with the selected fields) for the provided WIQL string.
 func QueryWIQL(wiql string) ([]byte, error) {
-	return runAz("boards", "query", "--wiql", wiql, "-o", "json")
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.QueryWIQL(wiql)
 }
 
 // WorkItem is a minimal shape for az boards work-item show output.
 type WorkItem struct {
-	ID     int                    `json:"id"`
-	Rev    int                    `json:"rev"`
-	Fields map[string]interface{} `json:"fields"`
-	URL    string                 `json:"url"`
+	ID        int                    `json:"id"`
+	Rev       int                    `json:"rev"`
+	Fields    map[string]interface{} `json:"fields"`
+	Relations []Relation             `json:"relations,omitempty"`
+	URL       string                 `json:"url"`
+}
+
+// Relation is a link from a work item to another work item or artifact.
+type Relation struct {
+	Rel        string         `json:"rel"`
+	URL        string         `json:"url"`
+	Attributes map[string]any `json:"attributes,omitempty"`
 }
 
 // ShowWorkItem gets a work item as JSON bytes and optionally decodes it.
 func ShowWorkItem(id string) ([]byte, *WorkItem, error) {
-	raw, err := runAz("boards", "work-item", "show", "--id", id, "-o", "json")
+	b, err := current()
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := b.ShowWorkItem(id)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -199,47 +294,47 @@ func ShowWorkItem(id string) ([]byte, *WorkItem, error) {
 
 // UpdateWorkItemFields updates fields on a work item and returns raw JSON output.
 func UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
-	args := []string{"boards", "work-item", "update", "--id", id}
-	// Build --fields list as repeated args: --fields "A=B" "C=D"
-	if len(fields) > 0 {
-		args = append(args, "--fields")
-		for k, v := range fields {
-			args = append(args, fmt.Sprintf("%s=%s", k, v))
-		}
+	b, err := current()
+	if err != nil {
+		return nil, err
 	}
-	args = append(args, "-o", "json")
-	return runAz(args...)
+	return b.UpdateWorkItemFields(id, fields)
 }
 
 // CreateWorkItem creates a new work item of a specific type with fields and optional relation.
 func CreateWorkItem(wiType, title string, fields map[string]string, relation string) ([]byte, error) {
-	args := []string{"boards", "work-item", "create", "--type", wiType, "--title", title}
-	if len(fields) > 0 {
-		args = append(args, "--fields")
-		for k, v := range fields {
-			args = append(args, fmt.Sprintf("%s=%s", k, v))
-		}
+	b, err := current()
+	if err != nil {
+		return nil, err
 	}
-	args = append(args, "-o", "json")
-	return runAz(args...)
+	return b.CreateWorkItem(wiType, title, fields)
 }
 
 // AddWorkItemRelation adds a relation from a work item to a target work item.
 func AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
-	args := []string{"boards", "work-item", "relation", "add", "--id", id, "--relation-type", relationType, "--target-id", targetID, "-o", "json"}
-	return runAz(args...)
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.AddWorkItemRelation(id, relationType, targetID)
 }
 
 // DeleteWorkItem deletes a work item by ID.
 func DeleteWorkItem(id string) ([]byte, error) {
-	args := []string{"boards", "work-item", "delete", "--id", id, "--yes", "-o", "json"}
-	return runAz(args...)
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.DeleteWorkItem(id)
 }
 
 // UpdateWorkItemAssignee updates the assigned-to field using the dedicated flag.
 func UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
-	args := []string{"boards", "work-item", "update", "--id", id, "--assigned-to", assignee, "-o", "json"}
-	return runAz(args...)
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.UpdateWorkItemAssignee(id, assignee)
 }
 
 // PrintJSON writes raw JSON bytes to stdout without modification.
@@ -261,38 +356,22 @@ type DevOpsDefaults struct {
 
 // GetDevOpsDefaults retrieves the configured default organization and project, and resolves the project's default team.
 func GetDevOpsDefaults() (*DevOpsDefaults, error) {
-	out, err := runAz("devops", "configure", "-l", "-o", "json")
+	b, err := current()
 	if err != nil {
 		return nil, err
 	}
-	var cfg struct {
-		Defaults map[string]string `json:"defaults"`
-	}
-	_ = json.Unmarshal(out, &cfg) // best-effort
-	org := cfg.Defaults["organization"]
-	proj := cfg.Defaults["project"]
-	if proj == "" {
-		return nil, fmt.Errorf("az devops default project not set; run 'az devops configure --defaults project=<name> organization=<url>'")
-	}
-	// Resolve default team name
-	pjson, err := runAz("devops", "project", "show", "--project", proj, "-o", "json")
+	defs, err := b.Defaults()
 	if err != nil {
 		return nil, err
 	}
-	var p struct {
-		DefaultTeam struct {
-			Name string `json:"name"`
-		} `json:"defaultTeam"`
+	if team != "" {
+		d := *defs
+		d.Team = team
+		defs = &d
 	}
-	if err := json.Unmarshal(pjson, &p); err != nil || p.DefaultTeam.Name == "" {
-		return nil, fmt.Errorf("unable to resolve default team for project %q", proj)
-	}
-	return &DevOpsDefaults{Organization: org, Project: proj, Team: p.DefaultTeam.Name}, nil
+	return defs, nil
 }
 
-// azRestGET performs an authenticated GET using az rest and returns raw json bytes.
-func azRestGET(url string) ([]byte, error) { return runAz("rest", "--method", "get", "--url", url) }
-
 // Board and Column shapes for Azure Boards REST
 type Board struct {
 	ID   string `json:"id"`
@@ -312,35 +391,29 @@ type ColumnsList struct {
 	Value []BoardColumn `json:"value"`
 }
 
-// BoardColumnsForType returns ordered column names and split flags for the default team's board that supports the given work item type.
+// BoardColumnsForType returns ordered column names and split flags for the team board (default team unless SetTeam) that supports the given work item type.
 func BoardColumnsForType(wiType string) (columns []BoardColumn, err error) {
-	defs, err := GetDevOpsDefaults()
+	b, err := current()
 	if err != nil {
 		return nil, err
 	}
-	base := strings.TrimRight(defs.Organization, "/")
-	// List boards for team
-	blRaw, err := azRestGET(fmt.Sprintf("%s/%s/%s/_apis/work/boards?api-version=7.0", base, defs.Project, defs.Team))
+	defs, err := GetDevOpsDefaults()
 	if err != nil {
 		return nil, err
 	}
-	var bl BoardsList
-	if err := json.Unmarshal(blRaw, &bl); err != nil {
+	boards, err := b.Boards(defs.Team)
+	if err != nil {
 		return nil, err
 	}
 	// Find a board whose columns have stateMappings for our type
-	for _, b := range bl.Value {
-		colsRaw, err := azRestGET(fmt.Sprintf("%s/%s/%s/_apis/work/boards/%s/columns?api-version=7.0", base, defs.Project, defs.Team, b.ID))
+	for _, bd := range boards {
+		cols, err := b.BoardColumns(defs.Team, bd.ID)
 		if err != nil {
 			continue
 		}
-		var cl ColumnsList
-		if err := json.Unmarshal(colsRaw, &cl); err != nil {
-			continue
-		}
-		for _, c := range cl.Value {
+		for _, c := range cols {
 			if _, ok := c.StateMapping[wiType]; ok {
-				return cl.Value, nil
+				return cols, nil
 			}
 		}
 	}
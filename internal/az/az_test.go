@@ -10,9 +10,10 @@ import (
 // helper to stub executor
 func withStubExec(t *testing.T, f func(args ...string) ([]byte, error), test func()) {
 	t.Helper()
-	prev := azExec
+	prev, prevCache := azExec, cacheEnabled
 	azExec = f
-	defer func() { azExec = prev }()
+	cacheEnabled = false
+	defer func() { azExec, cacheEnabled = prev, prevCache }()
 	test()
 }
 
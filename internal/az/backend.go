@@ -0,0 +1,230 @@
+package az
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Backend is the set of Azure DevOps operations ab is built on. Work item
+// methods return raw JSON in the shapes produced by the az CLI so callers
+// can decode them uniformly regardless of implementation.
+type Backend interface {
+	// QueryWIQL runs a WIQL query and returns a JSON array of work items
+	// carrying the selected fields.
+	QueryWIQL(wiql string) ([]byte, error)
+	// QueryLinks runs a WorkItemLinks query and returns its rows.
+	QueryLinks(wiql string) ([]WorkItemLink, error)
+	ShowWorkItem(id string) ([]byte, error)
+	// WorkItemsBatch fetches many work items per request (see the package
+	// function of the same name).
+	WorkItemsBatch(ids []int, fields []string, expand string) ([]WorkItem, error)
+	UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error)
+	// UpdateWorkItemFieldsAt updates fields only while the work item is at
+	// revision rev, failing with ErrConflict otherwise.
+	UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error)
+	UpdateWorkItemAssignee(id, assignee string) ([]byte, error)
+	CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error)
+	AddWorkItemRelation(id, relationType, targetID string) ([]byte, error)
+	DeleteWorkItem(id string) ([]byte, error)
+	// RemoveWorkItemRelation removes a relation added with
+	// AddWorkItemRelation or LinkAttachment; RestoreWorkItem brings a
+	// deleted work item back from the recycle bin.
+	RemoveWorkItemRelation(id, rel, target string) ([]byte, error)
+	RestoreWorkItem(id string) ([]byte, error)
+	// WorkItemUpdates returns the revisions of a work item as field
+	// changes, oldest first.
+	WorkItemUpdates(id string) ([]WorkItemUpdate, error)
+
+	// SavedQueries returns the project's query folders and queries;
+	// SavedQuery one query, by id or path, with its WIQL.
+	SavedQueries() ([]SavedQuery, error)
+	SavedQuery(idOrPath string) (*SavedQuery, error)
+
+	// Comments returns the Discussion of a work item, oldest first; with
+	// top > 0 only the latest top comments.
+	Comments(id string, top int) ([]Comment, error)
+	AddComment(id, html string) (*Comment, error)
+
+	// UploadAttachment uploads a file; LinkAttachment attaches the upload
+	// to a work item.
+	UploadAttachment(path, name string) (*AttachmentRef, error)
+	LinkAttachment(id, attachmentURL, comment string) ([]byte, error)
+	DownloadAttachment(attachmentURL, dest string) error
+
+	// Tags returns the names of the work item tags in the project.
+	Tags() ([]string, error)
+	// Areas returns the area paths of the project, the root first.
+	Areas() ([]string, error)
+
+	// CurrentUser returns the signed-in identity.
+	CurrentUser() (*Identity, error)
+	// Defaults returns the organization, project and team in use.
+	Defaults() (*DevOpsDefaults, error)
+
+	Boards(team string) ([]Board, error)
+	BoardColumns(team, boardID string) ([]BoardColumn, error)
+	// Iterations returns the iterations (sprints) of the team.
+	Iterations(team string) ([]Iteration, error)
+
+	ListRepos() ([]Repo, error)
+	CreateRepo(name string) ([]byte, error)
+	DeleteRepo(id string) error
+}
+
+var (
+	_ Backend = cliBackend{}
+	_ Backend = (*restClient)(nil)
+)
+
+// Identity is an Azure DevOps user.
+type Identity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+// Backend names accepted by SetBackend.
+const (
+	BackendCLI  = "az"
+	BackendREST = "rest"
+)
+
+// useREST routes the exported functions through the native HTTP client
+// instead of spawning az for every call.
+var useREST bool
+
+// override, when set, replaces the configured backend (see Use).
+var (
+	overrideMu sync.Mutex
+	override   Backend
+)
+
+func init() {
+	if v := strings.TrimSpace(os.Getenv("AB_BACKEND")); v != "" {
+		_ = SetBackend(v)
+	}
+}
+
+// SetBackend selects the Azure DevOps backend (az|rest).
+func SetBackend(name string) error {
+	b, err := ParseBackend(name)
+	if err != nil {
+		return err
+	}
+	switch b {
+	case "az":
+		useREST = false
+	case "rest":
+		useREST = true
+	}
+	resetCachedBase()
+	return nil
+}
+
+// ParseBackend normalizes a backend name to "az" or "rest" without selecting
+// it; empty stays empty.
+func ParseBackend(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "az", "cli", "azcli":
+		return "az", nil
+	case "rest", "http", "native":
+		return "rest", nil
+	case "":
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid backend: %q (valid: az|rest)", name)
+}
+
+// CurrentBackend names the selected backend, "az" or "rest".
+func CurrentBackend() string {
+	if useREST {
+		return "rest"
+	}
+	return "az"
+}
+
+// Use installs b as the backend for all package functions and returns a
+// function restoring the previous one. Intended for tests and for tools
+// embedding ab against a fake project.
+func Use(b Backend) (restore func()) {
+	overrideMu.Lock()
+	prev := override
+	override = b
+	overrideMu.Unlock()
+	return func() {
+		overrideMu.Lock()
+		override = prev
+		overrideMu.Unlock()
+	}
+}
+
+// current returns the active backend: the one installed with Use, else the
+// configured az or REST backend behind the disk cache; journaling its
+// mutations once StartJournal was called, and in dry-run mode wrapped so
+// that mutations are only recorded.
+func current() (Backend, error) {
+	b, err := base()
+	if err != nil {
+		return nil, err
+	}
+	if !isOverride(b) {
+		b = withCache(b)
+	}
+	if journaling() {
+		b = journalBackend{Backend: b}
+	}
+	if DryRun() {
+		b = dryRunBackend{Backend: b}
+	}
+	return b, nil
+}
+
+// wrapper is implemented by the backends current wraps around another one.
+type wrapper interface {
+	unwrap() Backend
+}
+
+func (j journalBackend) unwrap() Backend { return j.Backend }
+func (d dryRunBackend) unwrap() Backend  { return d.Backend }
+
+// cacheOf returns the disk cache below the wrappers of b, if any.
+func cacheOf(b Backend) (*cachedBackend, bool) {
+	for {
+		if cb, ok := b.(*cachedBackend); ok {
+			return cb, true
+		}
+		w, ok := b.(wrapper)
+		if !ok {
+			return nil, false
+		}
+		b = w.unwrap()
+	}
+}
+
+func isOverride(b Backend) bool {
+	overrideMu.Lock()
+	defer overrideMu.Unlock()
+	return override != nil && b == override
+}
+
+// base returns the active backend without the disk cache.
+func base() (Backend, error) {
+	overrideMu.Lock()
+	b := override
+	overrideMu.Unlock()
+	if b != nil {
+		return b, nil
+	}
+	if useREST {
+		return getREST()
+	}
+	return cliBackend{}, nil
+}
+
+// NewRESTBackend returns a native REST backend for the given organization
+// URL and project, e.g. one served by an httptest server.
+func NewRESTBackend(org, project string) Backend {
+	return newRESTClient(org, project)
+}
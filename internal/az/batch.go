@@ -0,0 +1,106 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// batchSize is the most ids the workitemsbatch endpoint takes per request.
+const batchSize = 200
+
+// Expand options of WorkItemsBatch. The API rejects an expand other than
+// ExpandNone together with a field list.
+const (
+	ExpandNone      = ""
+	ExpandRelations = "Relations"
+	ExpandFields    = "Fields"
+	ExpandLinks     = "Links"
+	ExpandAll       = "All"
+)
+
+// WorkItemsBatch fetches the work items with ids, batchSize per request, in
+// the order of ids; ids that do not exist are left out. fields limits the
+// fields returned (all when empty).
+func WorkItemsBatch(ids []int, fields []string, expand string) ([]WorkItem, error) {
+	if len(ids) == 0 {
+		return []WorkItem{}, nil
+	}
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.WorkItemsBatch(ids, fields, expand)
+}
+
+// fetchWorkItemsBatch posts the batch requests with post.
+func fetchWorkItemsBatch(post func(u string, body []byte) ([]byte, error), projectURL string, ids []int, fields []string, expand string) ([]WorkItem, error) {
+	if len(fields) > 0 && expand != ExpandNone {
+		return nil, fmt.Errorf("work items batch: expand %s cannot be combined with fields", expand)
+	}
+	out := make([]WorkItem, 0, len(ids))
+	for start := 0; start < len(ids); start += batchSize {
+		chunk := ids[start:min(start+batchSize, len(ids))]
+		req := map[string]any{"ids": chunk, "errorPolicy": "Omit"}
+		if len(fields) > 0 {
+			req["fields"] = fields
+		}
+		if expand != ExpandNone {
+			req["$expand"] = expand
+		}
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := post(withVersion(projectURL+"/_apis/wit/workitemsbatch"), body)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			// Omitted (missing) items come back as null.
+			Value []*WorkItem `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &res); err != nil {
+			return nil, fmt.Errorf("parse work items: %w", err)
+		}
+		byID := make(map[int]WorkItem, len(res.Value))
+		for _, wi := range res.Value {
+			if wi != nil {
+				byID[wi.ID] = *wi
+			}
+		}
+		for _, id := range chunk {
+			if wi, ok := byID[id]; ok {
+				out = append(out, wi)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (cliBackend) WorkItemsBatch(ids []int, fields []string, expand string) ([]WorkItem, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	post := func(u string, body []byte) ([]byte, error) { return azRest("post", u, body) }
+	return fetchWorkItemsBatch(post, base, ids, fields, expand)
+}
+
+func (c *restClient) WorkItemsBatch(ids []int, fields []string, expand string) ([]WorkItem, error) {
+	post := func(u string, body []byte) ([]byte, error) {
+		return c.do(http.MethodPost, u, body, "application/json")
+	}
+	return fetchWorkItemsBatch(post, c.projectURL(), ids, fields, expand)
+}
+
+// WorkItemsBatch stores the fetched items as snapshots.
+func (cb *cachedBackend) WorkItemsBatch(ids []int, fields []string, expand string) ([]WorkItem, error) {
+	items, err := cb.Backend.WorkItemsBatch(ids, fields, expand)
+	if err == nil {
+		if raw, err := json.Marshal(items); err == nil {
+			cb.storeItems(raw)
+		}
+	}
+	return items, err
+}
@@ -0,0 +1,679 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/util"
+)
+
+// Cache lifetimes. Entries older than their TTL are refetched; queries,
+// repos and tags younger than it are served immediately and revalidated in
+// the background (see CachedQueryWIQL and WaitBackground).
+var (
+	IdentityTTL = 7 * 24 * time.Hour
+	BoardTTL    = 24 * time.Hour
+	RepoTTL     = time.Hour
+	TagTTL      = time.Hour
+	QueryTTL    = 10 * time.Minute
+)
+
+// cacheEnabled turns the disk cache on for the az and REST backends;
+// AB_NO_CACHE or SetCacheEnabled(false) disable it.
+var cacheEnabled = !util.EnvTrue("AB_NO_CACHE")
+
+// SetCacheEnabled enables or disables the disk cache.
+func SetCacheEnabled(on bool) {
+	cacheEnabled = on
+	resetCachedBase()
+}
+
+// CacheRoot is <user cache dir>/ab ($XDG_CACHE_HOME/ab on Linux).
+func CacheRoot() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ab"), nil
+}
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CacheDir is the cache directory for the organization and project in use.
+func CacheDir() (string, error) {
+	org, project, _, err := Scope()
+	if err != nil {
+		return "", err
+	}
+	return cacheDirFor(org, project)
+}
+
+func cacheDirFor(org, project string) (string, error) {
+	if org == "" || project == "" {
+		return "", fmt.Errorf("organization or project not configured")
+	}
+	root, err := CacheRoot()
+	if err != nil {
+		return "", err
+	}
+	clean := func(s string) string { return unsafePathChars.ReplaceAllString(s, "_") }
+	return filepath.Join(root, clean(strings.TrimRight(org, "/")), clean(project)), nil
+}
+
+// diskCache stores JSON entries as files below dir; keys may contain "/".
+type diskCache struct {
+	dir string
+	mu  sync.Mutex
+}
+
+type cacheEntry struct {
+	Stored time.Time       `json:"stored"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func (c *diskCache) path(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = unsafePathChars.ReplaceAllString(p, "_")
+	}
+	return filepath.Join(c.dir, filepath.Join(parts...)+".json")
+}
+
+// get decodes the entry for key into v and returns its age.
+func (c *diskCache) get(key string, v any) (time.Duration, bool) {
+	c.mu.Lock()
+	raw, err := os.ReadFile(c.path(key))
+	c.mu.Unlock()
+	if err != nil {
+		return 0, false
+	}
+	var e cacheEntry
+	if json.Unmarshal(raw, &e) != nil || json.Unmarshal(e.Data, v) != nil {
+		return 0, false
+	}
+	return time.Since(e.Stored), true
+}
+
+// put stores v under key; failures only cost a cache miss later.
+func (c *diskCache) put(key string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	raw, err := json.Marshal(cacheEntry{Stored: time.Now().UTC(), Data: data})
+	if err != nil {
+		return
+	}
+	p := c.path(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if os.MkdirAll(filepath.Dir(p), 0o700) != nil {
+		return
+	}
+	tmp := p + ".tmp"
+	if os.WriteFile(tmp, raw, 0o600) == nil {
+		_ = os.Rename(tmp, p)
+	}
+}
+
+// drop removes key, or every key below it when key names a directory.
+func (c *diskCache) drop(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := c.path(key)
+	_ = os.Remove(p)
+	_ = os.RemoveAll(strings.TrimSuffix(p, ".json"))
+}
+
+// cachedBackend decorates a Backend with the disk cache. Reads of slowly
+// changing data (identity, defaults, boards, iterations, areas, repos) are
+// served from the cache within their TTL; work item snapshots are stored by
+// id and rev on every read and write; query results are cached as id lists
+// for CachedQueryWIQL and dropped on any work item mutation.
+type cachedBackend struct {
+	Backend
+	c *diskCache
+	// gen counts mutations; background revalidations started before one
+	// must not store their now stale results.
+	gen atomic.Int64
+}
+
+// NewCachedBackend wraps b with a disk cache stored in dir.
+func NewCachedBackend(b Backend, dir string) Backend {
+	return &cachedBackend{Backend: b, c: &diskCache{dir: dir}}
+}
+
+var (
+	cachedBaseMu sync.Mutex
+	cachedBase   Backend
+	cachedFor    Backend
+)
+
+// withCache returns b wrapped with the cache for the current scope, reusing
+// the wrapper across calls. b is returned as is when caching is off or no
+// scope is configured.
+func withCache(b Backend) Backend {
+	if !cacheEnabled {
+		return b
+	}
+	cachedBaseMu.Lock()
+	defer cachedBaseMu.Unlock()
+	if cachedBase != nil && cachedFor == b {
+		return cachedBase
+	}
+	// Key on what is known without starting az, so that the cache never
+	// costs an extra az call.
+	var org, project string
+	switch c := b.(type) {
+	case *restClient:
+		org, project = c.org, c.project
+	default:
+		org, project = localDevOpsConfig()
+	}
+	dir, err := cacheDirFor(org, project)
+	if err != nil {
+		return b
+	}
+	cachedBase, cachedFor = NewCachedBackend(b, dir), b
+	return cachedBase
+}
+
+func resetCachedBase() {
+	cachedBaseMu.Lock()
+	cachedBase, cachedFor = nil, nil
+	cachedBaseMu.Unlock()
+}
+
+// snapshot is the cached state of a work item at a revision.
+type snapshot struct {
+	ID     int            `json:"id"`
+	Rev    int            `json:"rev"`
+	Fields map[string]any `json:"fields"`
+	URL    string         `json:"url,omitempty"`
+}
+
+// storeItems records snapshots from raw work item JSON (an object or an
+// array), keeping the highest revision seen per id.
+func (cb *cachedBackend) storeItems(raw []byte) {
+	var items []snapshot
+	if err := json.Unmarshal(raw, &items); err != nil {
+		var one snapshot
+		if json.Unmarshal(raw, &one) != nil {
+			return
+		}
+		items = []snapshot{one}
+	}
+	for _, it := range items {
+		if it.ID == 0 || it.Fields == nil {
+			continue
+		}
+		key := "items/" + strconv.Itoa(it.ID)
+		var prev snapshot
+		if _, ok := cb.c.get(key, &prev); ok && prev.Rev > it.Rev {
+			continue
+		}
+		if prev.Rev == it.Rev && prev.Fields != nil {
+			// Same revision: merge, query results only carry some fields.
+			for k, v := range it.Fields {
+				prev.Fields[k] = v
+			}
+			it.Fields = prev.Fields
+		}
+		cb.c.put(key, it)
+	}
+}
+
+// mutated records the item returned by a mutation and drops cached queries
+// and tags, which a mutation may have added to.
+func (cb *cachedBackend) mutated(raw []byte) {
+	cb.gen.Add(1)
+	cb.storeItems(raw)
+	cb.c.drop("queries")
+	cb.c.drop("tags")
+}
+
+func (cb *cachedBackend) QueryWIQL(wiql string) ([]byte, error) {
+	raw, err := cb.Backend.QueryWIQL(wiql)
+	if err != nil {
+		return nil, err
+	}
+	cb.storeQuery(wiql, raw)
+	return raw, nil
+}
+
+func queryKey(wiql string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(wiql))
+	return "queries/" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+type cachedQuery struct {
+	WIQL   string   `json:"wiql"`
+	Fields []string `json:"fields"`
+	IDs    []int    `json:"ids"`
+}
+
+func (cb *cachedBackend) storeQuery(wiql string, raw []byte) {
+	var items []snapshot
+	if json.Unmarshal(raw, &items) != nil {
+		return
+	}
+	q := cachedQuery{WIQL: wiql, IDs: make([]int, 0, len(items))}
+	seen := map[string]bool{}
+	for _, it := range items {
+		q.IDs = append(q.IDs, it.ID)
+		for k := range it.Fields {
+			if !seen[k] {
+				seen[k] = true
+				q.Fields = append(q.Fields, k)
+			}
+		}
+	}
+	sort.Strings(q.Fields)
+	cb.storeItems(raw)
+	cb.c.put(queryKey(wiql), q)
+}
+
+// cachedQuery rebuilds a query result from the id list and snapshots.
+func (cb *cachedBackend) cachedQuery(wiql string) ([]byte, time.Duration, bool) {
+	var q cachedQuery
+	age, ok := cb.c.get(queryKey(wiql), &q)
+	if !ok || q.WIQL != wiql {
+		return nil, 0, false
+	}
+	out := make([]snapshot, 0, len(q.IDs))
+	for _, id := range q.IDs {
+		var s snapshot
+		if _, ok := cb.c.get("items/"+strconv.Itoa(id), &s); !ok {
+			return nil, 0, false
+		}
+		fields := make(map[string]any, len(q.Fields))
+		for _, f := range q.Fields {
+			if v, ok := s.Fields[f]; ok {
+				fields[f] = v
+			}
+		}
+		out = append(out, snapshot{ID: s.ID, Rev: s.Rev, Fields: fields, URL: s.URL})
+	}
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return nil, 0, false
+	}
+	return raw, age, true
+}
+
+func (cb *cachedBackend) ShowWorkItem(id string) ([]byte, error) {
+	raw, err := cb.Backend.ShowWorkItem(id)
+	if err == nil {
+		cb.storeItems(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	raw, err := cb.Backend.UpdateWorkItemFields(id, fields)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	raw, err := cb.Backend.UpdateWorkItemFieldsAt(id, rev, fields)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	raw, err := cb.Backend.UpdateWorkItemAssignee(id, assignee)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	raw, err := cb.Backend.CreateWorkItem(wiType, title, fields)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	raw, err := cb.Backend.AddWorkItemRelation(id, relationType, targetID)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	raw, err := cb.Backend.LinkAttachment(id, attachmentURL, comment)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) DeleteWorkItem(id string) ([]byte, error) {
+	raw, err := cb.Backend.DeleteWorkItem(id)
+	if err == nil {
+		cb.gen.Add(1)
+		cb.c.drop("items/" + id)
+		cb.c.drop("queries")
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	raw, err := cb.Backend.RemoveWorkItemRelation(id, rel, target)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) RestoreWorkItem(id string) ([]byte, error) {
+	raw, err := cb.Backend.RestoreWorkItem(id)
+	if err == nil {
+		cb.mutated(raw)
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) CurrentUser() (*Identity, error) {
+	var me Identity
+	if age, ok := cb.c.get("identity", &me); ok && age < IdentityTTL && me.UniqueName != "" {
+		return &me, nil
+	}
+	fresh, err := cb.Backend.CurrentUser()
+	if err == nil {
+		cb.c.put("identity", fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) Defaults() (*DevOpsDefaults, error) {
+	var d DevOpsDefaults
+	if age, ok := cb.c.get("defaults", &d); ok && age < BoardTTL && d.Team != "" {
+		return &d, nil
+	}
+	fresh, err := cb.Backend.Defaults()
+	if err == nil {
+		cb.c.put("defaults", fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) Boards(team string) ([]Board, error) {
+	key := "boards/" + team + "/index"
+	var boards []Board
+	if age, ok := cb.c.get(key, &boards); ok && age < BoardTTL {
+		return boards, nil
+	}
+	fresh, err := cb.Backend.Boards(team)
+	if err == nil {
+		cb.c.put(key, fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) BoardColumns(team, boardID string) ([]BoardColumn, error) {
+	key := "boards/" + team + "/" + boardID
+	var cols []BoardColumn
+	if age, ok := cb.c.get(key, &cols); ok && age < BoardTTL && len(cols) > 0 {
+		return cols, nil
+	}
+	fresh, err := cb.Backend.BoardColumns(team, boardID)
+	if err == nil {
+		cb.c.put(key, fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) Iterations(team string) ([]Iteration, error) {
+	key := "iterations/" + team
+	var its []Iteration
+	if age, ok := cb.c.get(key, &its); ok && age < BoardTTL {
+		return its, nil
+	}
+	fresh, err := cb.Backend.Iterations(team)
+	if err == nil {
+		cb.c.put(key, fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) Areas() ([]string, error) {
+	var areas []string
+	if age, ok := cb.c.get("areas", &areas); ok && age < BoardTTL {
+		return areas, nil
+	}
+	fresh, err := cb.Backend.Areas()
+	if err == nil {
+		cb.c.put("areas", fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) ListRepos() ([]Repo, error) {
+	var repos []Repo
+	if age, ok := cb.c.get("repos", &repos); ok && age < RepoTTL {
+		gen := cb.gen.Load()
+		revalidate(func() {
+			if fresh, err := quietListRepos(cb.Backend); err == nil && cb.gen.Load() == gen {
+				cb.c.put("repos", fresh)
+			}
+		})
+		return repos, nil
+	}
+	fresh, err := cb.Backend.ListRepos()
+	if err == nil {
+		cb.c.put("repos", fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) Tags() ([]string, error) {
+	var tags []string
+	if age, ok := cb.c.get("tags", &tags); ok && age < TagTTL {
+		gen := cb.gen.Load()
+		revalidate(func() {
+			if fresh, err := quietTags(cb.Backend); err == nil && cb.gen.Load() == gen {
+				cb.c.put("tags", fresh)
+			}
+		})
+		return tags, nil
+	}
+	fresh, err := cb.Backend.Tags()
+	if err == nil {
+		cb.c.put("tags", fresh)
+	}
+	return fresh, err
+}
+
+func (cb *cachedBackend) CreateRepo(name string) ([]byte, error) {
+	raw, err := cb.Backend.CreateRepo(name)
+	if err == nil {
+		cb.gen.Add(1)
+		cb.c.drop("repos")
+	}
+	return raw, err
+}
+
+func (cb *cachedBackend) DeleteRepo(id string) error {
+	err := cb.Backend.DeleteRepo(id)
+	if err == nil {
+		cb.gen.Add(1)
+		cb.c.drop("repos")
+	}
+	return err
+}
+
+// background tracks revalidations started by cached reads.
+var background sync.WaitGroup
+
+// revalidate runs fn in the background, unless every az call must be
+// confirmed, in which case a prompt could interleave with the caller's UI.
+func revalidate(fn func()) {
+	if confirmMode == ConfirmAlways {
+		return
+	}
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		fn()
+	}()
+}
+
+// WaitBackground waits up to timeout for background revalidations to
+// finish so their results reach the cache before the process exits.
+func WaitBackground(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// CachedQueryWIQL is QueryWIQL for pickers: a result cached within
+// QueryTTL, rebuilt from the stored work item snapshots, is returned
+// immediately and the query is revalidated in the background. Without a
+// usable cache entry it behaves like QueryWIQL.
+func CachedQueryWIQL(wiql string) ([]byte, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	if cb, ok := cacheOf(b); ok {
+		if raw, age, ok := cb.cachedQuery(wiql); ok && age < QueryTTL {
+			gen := cb.gen.Load()
+			revalidate(func() {
+				if fresh, err := quietQueryWIQL(cb.Backend, wiql); err == nil && cb.gen.Load() == gen {
+					cb.storeQuery(wiql, fresh)
+				}
+			})
+			return raw, nil
+		}
+	}
+	return b.QueryWIQL(wiql)
+}
+
+// quietQuerier is implemented by the backends that can run a query without
+// printing its command lines.
+type quietQuerier interface {
+	queryWIQL(wiql string, quiet bool) ([]byte, error)
+}
+
+// quietQueryWIQL runs wiql on b without printing anything, so a background
+// revalidation does not write over the picker that asked for it.
+func quietQueryWIQL(b Backend, wiql string) ([]byte, error) {
+	if q, ok := b.(quietQuerier); ok {
+		return q.queryWIQL(wiql, true)
+	}
+	return b.QueryWIQL(wiql)
+}
+
+// quietRepoLister is implemented by the backends that can list repositories
+// without printing their command lines.
+type quietRepoLister interface {
+	listRepos(quiet bool) ([]Repo, error)
+}
+
+// quietListRepos lists the repositories of b without printing anything.
+func quietListRepos(b Backend) ([]Repo, error) {
+	if l, ok := b.(quietRepoLister); ok {
+		return l.listRepos(true)
+	}
+	return b.ListRepos()
+}
+
+// quietTagLister is implemented by the backends that can list tags without
+// printing their command lines.
+type quietTagLister interface {
+	tags(quiet bool) ([]string, error)
+}
+
+// quietTags lists the tags of b without printing anything.
+func quietTags(b Backend) ([]string, error) {
+	if l, ok := b.(quietTagLister); ok {
+		return l.tags(true)
+	}
+	return b.Tags()
+}
+
+// CacheStatus summarizes the cache for the organization and project in use.
+type CacheStatus struct {
+	Dir     string
+	Enabled bool
+	Entries map[string]int // per kind: items, queries, boards, ...
+	Bytes   int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// GetCacheStatus walks the cache directory of the current scope.
+func GetCacheStatus() (*CacheStatus, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	st := &CacheStatus{Dir: dir, Enabled: cacheEnabled, Entries: map[string]int{}}
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(p, ".json") {
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, p)
+		kind := strings.TrimSuffix(strings.SplitN(filepath.ToSlash(rel), "/", 2)[0], ".json")
+		st.Entries[kind]++
+		st.Bytes += info.Size()
+		if st.Oldest.IsZero() || info.ModTime().Before(st.Oldest) {
+			st.Oldest = info.ModTime()
+		}
+		if info.ModTime().After(st.Newest) {
+			st.Newest = info.ModTime()
+		}
+		return nil
+	})
+	return st, err
+}
+
+// ClearCache removes the cache of the current scope, or the whole cache
+// when all is true. Returns the removed directory.
+func ClearCache(all bool) (string, error) {
+	var dir string
+	var err error
+	if all {
+		dir, err = CacheRoot()
+	} else {
+		dir, err = CacheDir()
+	}
+	if err != nil {
+		return "", err
+	}
+	resetCachedBase()
+	return dir, os.RemoveAll(dir)
+}
@@ -0,0 +1,228 @@
+package az_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+// withCachedFake serves p through the disk cache in a temp directory.
+func withCachedFake(t *testing.T, p *fake.Project) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "ab", "fake", "project")
+	_ = az.SetConfirmMode("never")
+	az.SetSilent(true)
+	t.Cleanup(az.Use(az.NewCachedBackend(p, dir)))
+	return dir
+}
+
+func titles(t *testing.T, raw []byte) map[int]string {
+	t.Helper()
+	var items []az.WorkItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	out := map[int]string{}
+	for _, it := range items {
+		out[it.ID], _ = it.Fields["System.Title"].(string)
+	}
+	return out
+}
+
+func TestCache_PickerQueryServedFromCacheAndRevalidated(t *testing.T) {
+	// Every command runs with the journal on, and --dry-run wraps it too:
+	// pickers must find the cache below those wrappers.
+	for _, tc := range []struct {
+		name  string
+		setup func(t *testing.T)
+	}{
+		{"plain", func(t *testing.T) {}},
+		{"journal", func(t *testing.T) {
+			t.Setenv("AB_JOURNAL", filepath.Join(t.TempDir(), "journal.jsonl"))
+			az.StartJournal([]string{"list"})
+			t.Cleanup(az.StopJournal)
+		}},
+		{"dry-run", func(t *testing.T) {
+			az.SetDryRun(true)
+			t.Cleanup(func() { az.SetDryRun(false) })
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := fake.New()
+			id := p.Add("User Story", "Old title", nil)
+			withCachedFake(t, p)
+			tc.setup(t)
+			const wiql = "SELECT [System.Id], [System.Title] FROM WorkItems"
+
+			if _, err := az.QueryWIQL(wiql); err != nil {
+				t.Fatal(err)
+			}
+			// Changed behind ab's back: the picker still gets the cached title ...
+			if _, err := p.UpdateWorkItemFields(strconv.Itoa(id), map[string]string{"System.Title": "New title"}); err != nil {
+				t.Fatal(err)
+			}
+			raw, err := az.CachedQueryWIQL(wiql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(t, raw)[id]; got != "Old title" {
+				t.Fatalf("cached title = %q", got)
+			}
+			// ... and the background revalidation refreshes the cache.
+			az.WaitBackground(5 * time.Second)
+			raw, _ = az.CachedQueryWIQL(wiql)
+			if got := titles(t, raw)[id]; got != "New title" {
+				t.Fatalf("revalidated title = %q", got)
+			}
+			az.WaitBackground(5 * time.Second)
+		})
+	}
+}
+
+func TestCache_RevalidationPrintsNothing(t *testing.T) {
+	p := fake.New()
+	id := p.Add("User Story", "Old title", map[string]any{"System.Tags": "old"})
+	srv := p.NewServer()
+	defer srv.Close()
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+	_ = az.SetConfirmMode("never")
+	az.SetSilent(false)
+	defer az.SetSilent(true)
+	defer az.Use(az.NewCachedBackend(az.NewRESTBackend(p.BaseURL, p.Name), t.TempDir()))()
+	const wiql = "SELECT [System.Id], [System.Title] FROM WorkItems"
+
+	if _, err := az.QueryWIQL(wiql); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := az.ListRepos(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := az.Tags(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.UpdateWorkItemFields(strconv.Itoa(id), map[string]string{"System.Title": "New title", "System.Tags": "new"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.CreateRepo("fresh"); err != nil {
+		t.Fatal(err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	_, qerr := az.CachedQueryWIQL(wiql)
+	_, rerr := az.ListRepos()
+	_, terr := az.Tags()
+	az.WaitBackground(5 * time.Second)
+	os.Stderr = stderr
+	w.Close()
+	printed, _ := io.ReadAll(r)
+	if err := errors.Join(qerr, rerr, terr); err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) > 0 {
+		t.Fatalf("revalidation printed:\n%s", printed)
+	}
+
+	raw, _ := az.CachedQueryWIQL(wiql)
+	az.WaitBackground(5 * time.Second)
+	if got := titles(t, raw)[id]; got != "New title" {
+		t.Fatalf("revalidated title = %q", got)
+	}
+	repos, _ := az.ListRepos()
+	if !slices.ContainsFunc(repos, func(r az.Repo) bool { return r.Name == "fresh" }) {
+		t.Fatalf("revalidated repos = %+v", repos)
+	}
+	if tags, _ := az.Tags(); !slices.Contains(tags, "new") {
+		t.Fatalf("revalidated tags = %v", tags)
+	}
+}
+
+func TestCache_MutationDropsQueriesAndStoresSnapshot(t *testing.T) {
+	p := fake.New()
+	id := p.Add("User Story", "Story", nil)
+	dir := withCachedFake(t, p)
+	const wiql = "SELECT [System.Id], [System.State] FROM WorkItems"
+
+	if _, err := az.QueryWIQL(wiql); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := az.UpdateWorkItemFields(strconv.Itoa(id), map[string]string{"System.State": "Active"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "queries")); !os.IsNotExist(err) {
+		t.Fatalf("queries not dropped after mutation: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "items", strconv.Itoa(id)+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e struct {
+		Data struct {
+			Rev    int            `json:"rev"`
+			Fields map[string]any `json:"fields"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	wi, _ := p.Get(id)
+	if e.Data.Rev != wi.Rev || e.Data.Fields["System.State"] != "Active" {
+		t.Fatalf("snapshot = rev %d %v, want rev %d Active", e.Data.Rev, e.Data.Fields["System.State"], wi.Rev)
+	}
+	// Snapshots of private projects are readable by their owner only.
+	if runtime.GOOS != "windows" {
+		for path, want := range map[string]os.FileMode{filepath.Join(dir, "items"): 0o700, filepath.Join(dir, "items", strconv.Itoa(id)+".json"): 0o600} {
+			st, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if st.Mode().Perm() != want {
+				t.Fatalf("%s mode = %v, want %v", path, st.Mode().Perm(), want)
+			}
+		}
+	}
+}
+
+func TestCache_IdentityAndRepos(t *testing.T) {
+	p := fake.New()
+	withCachedFake(t, p)
+
+	me, err := az.CurrentUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Me.UniqueName = "someone.else@example.com"
+	again, _ := az.CurrentUser()
+	if again.UniqueName != me.UniqueName {
+		t.Fatalf("identity not cached: %q", again.UniqueName)
+	}
+
+	before, err := az.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := az.CreateRepo("cached-repo"); err != nil {
+		t.Fatal(err)
+	}
+	after, err := az.ListRepos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before)+1 {
+		t.Fatalf("repo list not invalidated by create: %d -> %d", len(before), len(after))
+	}
+	az.WaitBackground(5 * time.Second)
+}
@@ -0,0 +1,249 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// cliBackend implements Backend by shelling out to the az CLI via runAz.
+type cliBackend struct{}
+
+func (c cliBackend) QueryWIQL(wiql string) ([]byte, error) { return c.queryWIQL(wiql, false) }
+
+func (cliBackend) queryWIQL(wiql string, quiet bool) ([]byte, error) {
+	args := append([]string{"boards", "query", "--wiql", wiql}, scopeArgs()...)
+	return runAzQuiet(quiet, append(args, "-o", "json")...)
+}
+
+func (cliBackend) ShowWorkItem(id string) ([]byte, error) {
+	args := append([]string{"boards", "work-item", "show", "--id", id}, orgArgs()...)
+	return runAz(append(args, "-o", "json")...)
+}
+
+func (cliBackend) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	return runAz(updateArgs(id, fields)...)
+}
+
+func (cliBackend) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	return runAz(assigneeArgs(id, assignee)...)
+}
+
+func (cliBackend) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	return runAz(createArgs(wiType, title, fields)...)
+}
+
+func (cliBackend) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	return runAz(relationArgs(id, relationType, targetID)...)
+}
+
+func (cliBackend) DeleteWorkItem(id string) ([]byte, error) {
+	return runAz(deleteArgs(id)...)
+}
+
+// updateArgs is the az command line updating fields of a work item, the
+// fields as repeated args (--fields "A=B" "C=D") in name order.
+func updateArgs(id string, fields map[string]string) []string {
+	args := append([]string{"boards", "work-item", "update", "--id", id}, fieldArgs(fields)...)
+	args = append(args, orgArgs()...)
+	return append(args, "-o", "json")
+}
+
+func assigneeArgs(id, assignee string) []string {
+	args := append([]string{"boards", "work-item", "update", "--id", id, "--assigned-to", assignee}, orgArgs()...)
+	return append(args, "-o", "json")
+}
+
+func createArgs(wiType, title string, fields map[string]string) []string {
+	args := append([]string{"boards", "work-item", "create", "--type", wiType, "--title", title}, fieldArgs(fields)...)
+	args = append(args, scopeArgs()...)
+	return append(args, "-o", "json")
+}
+
+func relationArgs(id, relationType, targetID string) []string {
+	args := append([]string{"boards", "work-item", "relation", "add", "--id", id, "--relation-type", relationType, "--target-id", targetID}, orgArgs()...)
+	return append(args, "-o", "json")
+}
+
+func deleteArgs(id string) []string {
+	args := append([]string{"boards", "work-item", "delete", "--id", id, "--yes"}, orgArgs()...)
+	return append(args, "-o", "json")
+}
+
+func fieldArgs(fields map[string]string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	args := []string{"--fields"}
+	for _, k := range names {
+		args = append(args, fmt.Sprintf("%s=%s", k, fields[k]))
+	}
+	return args
+}
+
+// CurrentUser returns the signed-in identity via az ad.
+func (cliBackend) CurrentUser() (*Identity, error) {
+	out, err := runAz("ad", "signed-in-user", "show", "-o", "json")
+	if err != nil {
+		return nil, err
+	}
+	var u struct {
+		ID                string `json:"id"`
+		DisplayName       string `json:"displayName"`
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+	if err := json.Unmarshal(out, &u); err != nil {
+		return nil, fmt.Errorf("parse signed-in user: %w", err)
+	}
+	return &Identity{ID: u.ID, DisplayName: u.DisplayName, UniqueName: u.UserPrincipalName}, nil
+}
+
+// Defaults resolves the configured organization and project, and the project's default team.
+func (cliBackend) Defaults() (*DevOpsDefaults, error) {
+	org, proj := orgOverride, projectOverride
+	if org == "" || proj == "" {
+		out, err := runAz("devops", "configure", "-l", "-o", "json")
+		if err != nil {
+			return nil, err
+		}
+		var cfg struct {
+			Defaults map[string]string `json:"defaults"`
+		}
+		_ = json.Unmarshal(out, &cfg) // best-effort
+		if org == "" {
+			org = cfg.Defaults["organization"]
+		}
+		if proj == "" {
+			proj = cfg.Defaults["project"]
+		}
+	}
+	if proj == "" {
+		return nil, fmt.Errorf("az devops default project not set; run 'az devops configure --defaults project=<name> organization=<url>'")
+	}
+	// Resolve default team name
+	pargs := append([]string{"devops", "project", "show", "--project", proj}, orgArgs()...)
+	pjson, err := runAz(append(pargs, "-o", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	var p struct {
+		DefaultTeam struct {
+			Name string `json:"name"`
+		} `json:"defaultTeam"`
+	}
+	if err := json.Unmarshal(pjson, &p); err != nil || p.DefaultTeam.Name == "" {
+		return nil, fmt.Errorf("unable to resolve default team for project %q", proj)
+	}
+	return &DevOpsDefaults{Organization: org, Project: proj, Team: p.DefaultTeam.Name}, nil
+}
+
+// teamURL builds the team-scoped REST base URL used with az rest.
+func (b cliBackend) teamURL(team string) (string, error) {
+	defs, err := b.Defaults()
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimRight(defs.Organization, "/")
+	return fmt.Sprintf("%s/%s/%s", base, url.PathEscape(defs.Project), url.PathEscape(team)), nil
+}
+
+func (b cliBackend) Boards(team string) ([]Board, error) {
+	base, err := b.teamURL(team)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRestGET(base + "/_apis/work/boards?api-version=7.0")
+	if err != nil {
+		return nil, err
+	}
+	var bl BoardsList
+	if err := json.Unmarshal(raw, &bl); err != nil {
+		return nil, err
+	}
+	return bl.Value, nil
+}
+
+func (b cliBackend) BoardColumns(team, boardID string) ([]BoardColumn, error) {
+	base, err := b.teamURL(team)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRestGET(fmt.Sprintf("%s/_apis/work/boards/%s/columns?api-version=7.0", base, url.PathEscape(boardID)))
+	if err != nil {
+		return nil, err
+	}
+	var cl ColumnsList
+	if err := json.Unmarshal(raw, &cl); err != nil {
+		return nil, err
+	}
+	return cl.Value, nil
+}
+
+func (c cliBackend) ListRepos() ([]Repo, error) { return c.listRepos(false) }
+
+// listRepos is ListRepos that, when quiet, does not print the command line.
+func (cliBackend) listRepos(quiet bool) ([]Repo, error) {
+	out, err := runAzQuiet(quiet, append(append([]string{"repos", "list"}, scopeArgs()...), "-o", "json")...)
+	if err != nil {
+		return nil, err
+	}
+	var repos []Repo
+	if err := json.Unmarshal(out, &repos); err != nil {
+		return nil, err
+	}
+	return repos, nil
+}
+
+func (cliBackend) CreateRepo(name string) ([]byte, error) {
+	return runAz(createRepoArgs(name)...)
+}
+
+// DeleteRepo passes --yes to az to avoid its prompt; callers confirm first.
+func (cliBackend) DeleteRepo(id string) error {
+	_, err := runAz(deleteRepoArgs(id)...)
+	return err
+}
+
+func createRepoArgs(name string) []string {
+	return append(append([]string{"repos", "create", "--name", name}, scopeArgs()...), "-o", "json")
+}
+
+func deleteRepoArgs(id string) []string {
+	return append([]string{"repos", "delete", "--id", id, "--yes"}, scopeArgs()...)
+}
+
+// azRestGET performs an authenticated GET using az rest and returns raw json bytes.
+func azRestGET(url string) ([]byte, error) { return azRest("get", url, nil) }
+
+// azRest calls the Azure DevOps REST API through az rest, which signs the
+// request with a token for the Azure DevOps resource. body, when not nil,
+// is sent as JSON.
+func azRest(method, url string, body []byte) ([]byte, error) {
+	return runAz(restArgs(method, url, "application/json", body)...)
+}
+
+// restArgs is the az rest command line; body, when not nil, is sent with
+// contentType.
+func restArgs(method, url, contentType string, body []byte) []string {
+	args := []string{"rest", "--method", method, "--url", url, "--resource", adoResourceID}
+	if body != nil {
+		args = append(args, "--headers", "Content-Type="+contentType, "--body", string(body))
+	}
+	return args
+}
+
+// cliProjectURL is the project-scoped REST base URL used with az rest.
+func cliProjectURL() (string, error) {
+	org, project, err := azDevOpsConfig()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(org, "/") + "/" + url.PathEscape(project), nil
+}
@@ -0,0 +1,142 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// commentsAPIVersion is the (preview) version of the work item comments API.
+const commentsAPIVersion = "7.0-preview.3"
+
+// Comment is a comment in the Discussion of a work item. Text is HTML.
+type Comment struct {
+	ID           int       `json:"id"`
+	WorkItemID   int       `json:"workItemId"`
+	Text         string    `json:"text"`
+	CreatedBy    Identity  `json:"createdBy"`
+	CreatedDate  time.Time `json:"createdDate"`
+	ModifiedDate time.Time `json:"modifiedDate,omitempty"`
+}
+
+// commentList is a page of the comments API.
+type commentList struct {
+	TotalCount        int       `json:"totalCount"`
+	Comments          []Comment `json:"comments"`
+	ContinuationToken string    `json:"continuationToken"`
+}
+
+// Comments returns the Discussion of a work item, oldest first. With top > 0
+// only the latest top comments are returned.
+func Comments(id string, top int) ([]Comment, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.Comments(id, top)
+}
+
+// AddComment posts an HTML comment to the Discussion of a work item.
+func AddComment(id, html string) (*Comment, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.AddComment(id, html)
+}
+
+func commentsURL(projectURL, id string) string {
+	return projectURL + "/_apis/wit/workItems/" + url.PathEscape(id) + "/comments"
+}
+
+// fetchComments pages through the comments API with get. The newest top
+// comments are requested in descending order and returned oldest first.
+func fetchComments(get func(u string) ([]byte, error), projectURL, id string, top int) ([]Comment, error) {
+	base := commentsURL(projectURL, id) + "?api-version=" + commentsAPIVersion
+	if top > 0 {
+		raw, err := get(base + "&$top=" + strconv.Itoa(top) + "&order=desc")
+		if err != nil {
+			return nil, err
+		}
+		var page commentList
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("decode comments: %w", err)
+		}
+		out := page.Comments
+		if len(out) > top {
+			out = out[:top]
+		}
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+		return out, nil
+	}
+	var out []Comment
+	token := ""
+	for {
+		u := base + "&order=asc"
+		if token != "" {
+			u += "&continuationToken=" + url.QueryEscape(token)
+		}
+		raw, err := get(u)
+		if err != nil {
+			return nil, err
+		}
+		var page commentList
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("decode comments: %w", err)
+		}
+		out = append(out, page.Comments...)
+		if page.ContinuationToken == "" || len(page.Comments) == 0 {
+			return out, nil
+		}
+		token = page.ContinuationToken
+	}
+}
+
+func decodeComment(raw []byte) (*Comment, error) {
+	var c Comment
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("decode comment: %w", err)
+	}
+	return &c, nil
+}
+
+func (cliBackend) Comments(id string, top int) ([]Comment, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	return fetchComments(azRestGET, base, id, top)
+}
+
+func (cliBackend) AddComment(id, html string) (*Comment, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"text": html})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRest("post", commentsURL(base, id)+"?api-version="+commentsAPIVersion, body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeComment(raw)
+}
+
+func (c *restClient) Comments(id string, top int) ([]Comment, error) {
+	return fetchComments(c.getJSON, c.projectURL(), id, top)
+}
+
+func (c *restClient) AddComment(id, html string) (*Comment, error) {
+	raw, err := c.sendJSON(http.MethodPost, commentsURL(c.projectURL(), id)+"?api-version="+commentsAPIVersion, map[string]string{"text": html})
+	if err != nil {
+		return nil, err
+	}
+	return decodeComment(raw)
+}
@@ -0,0 +1,346 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PlannedChange is a mutation recorded in dry-run mode instead of being
+// made.
+type PlannedChange struct {
+	// Command is the az command line that would make the change.
+	Command string
+	// Action is update, create, relation, unlink, delete, restore, comment,
+	// upload, attach, repo create or repo delete.
+	Action string
+	// Item is the work item changed, 0 for repos. Items created during
+	// the dry run have negative ids: -1 is the first.
+	Item  int
+	Title string
+	// Fields holds the changed fields with their values before and after.
+	Fields map[string]FieldChange
+	// Detail describes what else changes: the relation, the comment, the
+	// file or the repository.
+	Detail string
+}
+
+// dryRun is the state of dry-run mode: the plan so far and the work items
+// as they would be after it.
+var dryRun struct {
+	on      atomic.Bool
+	mu      sync.Mutex
+	plan    []PlannedChange
+	items   map[int]*WorkItem
+	created int
+}
+
+// SetDryRun turns dry-run mode on or off and starts an empty plan. In
+// dry-run mode reads are made as usual, while every mutation is recorded
+// (see Plan) and answered as if it had been made.
+func SetDryRun(on bool) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	dryRun.on.Store(on)
+	dryRun.plan = nil
+	dryRun.items = map[int]*WorkItem{}
+	dryRun.created = 0
+}
+
+// DryRun reports whether dry-run mode is on.
+func DryRun() bool { return dryRun.on.Load() }
+
+// Plan returns the mutations recorded in dry-run mode, in order.
+func Plan() []PlannedChange {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	return append([]PlannedChange(nil), dryRun.plan...)
+}
+
+// NewItemLabel is how work items created during a dry run appear in the
+// planned command lines, e.g. {new-1}.
+func NewItemLabel(id int) string { return fmt.Sprintf("{new-%d}", -id) }
+
+// errDryRun stops a mutation that got past the dry-run backend.
+func errDryRun(cmdline string) error {
+	return fmt.Errorf("dry run: refusing to run %s", cmdline)
+}
+
+// dryRunBackend records mutations in the plan and answers them from an
+// overlay of the work items they touch; reads go to Backend.
+type dryRunBackend struct {
+	Backend
+}
+
+// item returns the planned state of a work item, loading it on first use.
+// Callers hold dryRun.mu.
+func (d dryRunBackend) item(id string) (*WorkItem, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return nil, fmt.Errorf("invalid work item id %q", id)
+	}
+	if wi, ok := dryRun.items[n]; ok {
+		return wi, nil
+	}
+	raw, err := d.Backend.ShowWorkItem(id)
+	if err != nil {
+		return nil, err
+	}
+	var wi WorkItem
+	if err := json.Unmarshal(raw, &wi); err != nil {
+		return nil, fmt.Errorf("parse work item %s: %w", id, err)
+	}
+	if wi.Fields == nil {
+		wi.Fields = map[string]any{}
+	}
+	dryRun.items[n] = &wi
+	return &wi, nil
+}
+
+// record appends c to the plan. Callers hold dryRun.mu.
+func record(c PlannedChange) {
+	dryRun.plan = append(dryRun.plan, c)
+}
+
+// label returns id for command lines, {new-n} for planned items.
+func label(id string) string {
+	if n, err := strconv.Atoi(id); err == nil && n < 0 {
+		return NewItemLabel(n)
+	}
+	return id
+}
+
+// planURL is the project URL shown in planned az rest command lines.
+func planURL() string {
+	if u, err := cliProjectURL(); err == nil {
+		return u
+	}
+	return "{project}"
+}
+
+func (d dryRunBackend) ShowWorkItem(id string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	if n, err := strconv.Atoi(strings.TrimSpace(id)); err == nil {
+		if wi, ok := dryRun.items[n]; ok {
+			return json.Marshal(wi)
+		}
+	}
+	return d.Backend.ShowWorkItem(id)
+}
+
+func (d dryRunBackend) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	return d.update(id, fields, formatAz(updateArgs(label(id), fields)))
+}
+
+func (d dryRunBackend) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	body, err := json.Marshal(revisionOps(rev, fields))
+	if err != nil {
+		return nil, err
+	}
+	return d.update(id, fields, formatAz(restArgs("patch", workItemPatchURL(planURL(), label(id)), "application/json-patch+json", body)))
+}
+
+func (d dryRunBackend) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	return d.update(id, map[string]string{"System.AssignedTo": assignee}, formatAz(assigneeArgs(label(id), assignee)))
+}
+
+func (d dryRunBackend) update(id string, fields map[string]string, cmdline string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]FieldChange{}
+	for k, v := range fields {
+		changes[k] = FieldChange{OldValue: wi.Fields[k], NewValue: v}
+		wi.Fields[k] = v
+	}
+	wi.Rev++
+	record(PlannedChange{Command: cmdline, Action: "update", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"), Fields: changes})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	dryRun.created++
+	wi := &WorkItem{ID: -dryRun.created, Rev: 1, Fields: map[string]any{
+		"System.WorkItemType": wiType,
+		"System.Title":        title,
+		"System.State":        "New",
+	}}
+	for k, v := range fields {
+		wi.Fields[k] = v
+	}
+	changes := map[string]FieldChange{}
+	for k, v := range wi.Fields {
+		changes[k] = FieldChange{NewValue: v}
+	}
+	dryRun.items[wi.ID] = wi
+	record(PlannedChange{Command: formatAz(createArgs(wiType, title, fields)), Action: "create", Item: wi.ID, Title: title, Fields: changes})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	wi.Relations = append(wi.Relations, Relation{Rel: RelationRefName(relationType), URL: "{org}/_apis/wit/workItems/" + targetID})
+	wi.Rev++
+	record(PlannedChange{
+		Command: formatAz(relationArgs(label(id), relationType, label(targetID))),
+		Action:  "relation", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"),
+		Detail: RelationSummary(RelationRefName(relationType), targetID),
+	})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) DeleteWorkItem(id string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	record(PlannedChange{Command: formatAz(deleteArgs(label(id))), Action: "delete", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title")})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(wi)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := removeRelationOps(raw, rel, target)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range wi.Relations {
+		if strings.EqualFold(r.Rel, rel) && SameRelationTarget(r.URL, target) {
+			wi.Relations = append(wi.Relations[:i:i], wi.Relations[i+1:]...)
+			break
+		}
+	}
+	wi.Rev++
+	record(PlannedChange{
+		Command: formatAz(restArgs("patch", workItemPatchURL(planURL(), label(id)), "application/json-patch+json", body)),
+		Action:  "unlink", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"), Detail: RelationSummary(rel, target),
+	})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) RestoreWorkItem(id string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return nil, fmt.Errorf("invalid work item id %q", id)
+	}
+	record(PlannedChange{
+		Command: formatAz(restArgs("patch", recycleBinURL(planURL(), id), "application/json", restoreBody)),
+		Action:  "restore", Item: n,
+	})
+	return json.Marshal(map[string]any{"id": n, "code": 200})
+}
+
+func (d dryRunBackend) AddComment(id, html string) (*Comment, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"text": html})
+	if err != nil {
+		return nil, err
+	}
+	u := commentsURL(planURL(), label(id)) + "?api-version=" + commentsAPIVersion
+	record(PlannedChange{
+		Command: formatAz(restArgs("post", u, "application/json", body)),
+		Action:  "comment", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"), Detail: html,
+	})
+	return &Comment{WorkItemID: wi.ID, Text: html, CreatedDate: time.Now().UTC()}, nil
+}
+
+func (d dryRunBackend) UploadAttachment(path, name string) (*AttachmentRef, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	u := attachmentsURL(planURL(), name)
+	args := append(restArgs("post", u, "application/octet-stream", nil), "--body", "@"+path)
+	record(PlannedChange{Command: formatAz(args), Action: "upload", Detail: name})
+	return &AttachmentRef{ID: "{upload}", URL: u}, nil
+}
+
+func (d dryRunBackend) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	wi, err := d.item(id)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal([]patchOp{attachmentOp(attachmentURL, comment)})
+	if err != nil {
+		return nil, err
+	}
+	wi.Relations = append(wi.Relations, Relation{Rel: AttachedFileRel, URL: attachmentURL})
+	wi.Rev++
+	name := attachmentURL
+	if pu, err := url.Parse(attachmentURL); err == nil && pu.Query().Get("fileName") != "" {
+		name = pu.Query().Get("fileName")
+	}
+	u := withVersion(planURL() + "/_apis/wit/workitems/" + url.PathEscape(label(id)))
+	record(PlannedChange{
+		Command: formatAz(restArgs("patch", u, "application/json-patch+json", body)),
+		Action:  "attach", Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"), Detail: name,
+	})
+	return json.Marshal(wi)
+}
+
+func (d dryRunBackend) CreateRepo(name string) ([]byte, error) {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	record(PlannedChange{Command: formatAz(createRepoArgs(name)), Action: "repo create", Detail: name})
+	return json.Marshal(Repo{ID: "{new-repo}", Name: name})
+}
+
+func (d dryRunBackend) DeleteRepo(id string) error {
+	dryRun.mu.Lock()
+	defer dryRun.mu.Unlock()
+	record(PlannedChange{Command: formatAz(deleteRepoArgs(id)), Action: "repo delete", Detail: id})
+	return nil
+}
+
+// itemRef is AB#id, or the label of a planned item.
+func itemRef(id string) string {
+	if l := label(id); l != id {
+		return l
+	}
+	return "AB#" + id
+}
+
+func fieldString(fields map[string]any, name string) string {
+	if s, ok := fields[name].(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,1129 @@
+// Package fake provides an in-memory Azure DevOps project implementing
+// az.Backend, optionally served over HTTP for the native REST backend.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+// KanbanField is the dynamic Kanban column field of the fake team board.
+const KanbanField = "WEF_FAKE_Kanban.Column"
+
+// DefaultColumns is the board used by New: the same flow as board.ColumnOrder,
+// mapped onto the Agile User Story and Bug states.
+var DefaultColumns = []az.BoardColumn{
+	{ID: "c1", Name: "Backlog", ColumnType: "incoming", StateMapping: map[string]string{"User Story": "New", "Bug": "New"}},
+	{ID: "c2", Name: "Ready for Development", ColumnType: "inProgress", IsSplit: true, StateMapping: map[string]string{"User Story": "Active", "Bug": "Active"}},
+	{ID: "c3", Name: "In Process", ColumnType: "inProgress", StateMapping: map[string]string{"User Story": "Active", "Bug": "Active"}},
+	{ID: "c4", Name: "Ready to Test", ColumnType: "inProgress", StateMapping: map[string]string{"User Story": "Resolved", "Bug": "Resolved"}},
+	{ID: "c5", Name: "In Test", ColumnType: "inProgress", StateMapping: map[string]string{"User Story": "Resolved", "Bug": "Resolved"}},
+	{ID: "c6", Name: "Deploy", ColumnType: "inProgress", StateMapping: map[string]string{"User Story": "Resolved", "Bug": "Resolved"}},
+	{ID: "c7", Name: "Done", ColumnType: "outgoing", StateMapping: map[string]string{"User Story": "Closed", "Bug": "Closed"}},
+}
+
+// Project is an in-memory Azure DevOps project. The exported fields may be
+// adjusted before use; everything else is guarded by the project's mutex.
+type Project struct {
+	Org     string // organization name, e.g. "fake"
+	Name    string // project name
+	Team    string
+	Me      az.Identity
+	Users   []az.Identity
+	Columns []az.BoardColumn
+	// Sprints are the team's iterations, in schedule order.
+	Sprints []az.Iteration
+	// AreaPaths are the project's areas, the root (the project) first.
+	AreaPaths []string
+	Repos     []az.Repo
+
+	// BaseURL is the organization URL used in work item and repo links.
+	// NewServer sets it to the test server's organization URL.
+	BaseURL string
+
+	mu      sync.Mutex
+	items   map[int]*item
+	deleted map[int]*item
+	nextID  int
+	nextCmt int
+	uploads map[string]upload
+	now     func() time.Time
+
+	queries   []*az.SavedQuery
+	nextQuery int
+}
+
+type upload struct {
+	name    string
+	data    []byte
+	created time.Time
+}
+
+type item struct {
+	id        int
+	rev       int
+	fields    map[string]any
+	relations []az.Relation
+	revisions []az.WorkItem
+	comments  []az.Comment
+}
+
+var _ az.Backend = (*Project)(nil)
+
+// New returns an empty project with a signed-in user and the default board.
+func New() *Project {
+	me := az.Identity{ID: "me", DisplayName: "Me User", UniqueName: "me@example.com"}
+	return &Project{
+		Org:       "fake",
+		Name:      "Fake",
+		Team:      "Fake Team",
+		Me:        me,
+		Users:     []az.Identity{me},
+		Columns:   append([]az.BoardColumn(nil), DefaultColumns...),
+		Sprints:   sprints("Fake", time.Now()),
+		AreaPaths: []string{"Fake", `Fake\Backend`, `Fake\Frontend`, `Fake\Frontend\Mobile`},
+		items:     map[int]*item{},
+		deleted:   map[int]*item{},
+		nextID:    1,
+		nextCmt:   1,
+		uploads:   map[string]upload{},
+		now:       time.Now,
+	}
+}
+
+// sprints returns three two-week sprints of project, the second one current
+// at now.
+func sprints(project string, now time.Time) []az.Iteration {
+	start := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, -21)
+	out := make([]az.Iteration, 0, 3)
+	for i, frame := range []string{"past", "current", "future"} {
+		from, to := start.AddDate(0, 0, 14*i), start.AddDate(0, 0, 14*i+13)
+		name := fmt.Sprintf("Sprint %d", i+1)
+		out = append(out, az.Iteration{
+			ID:         fmt.Sprintf("iteration-%d", i+1),
+			Name:       name,
+			Path:       project + `\` + name,
+			Attributes: az.IterationAttributes{StartDate: &from, FinishDate: &to, TimeFrame: frame},
+		})
+	}
+	return out
+}
+
+// SetClock overrides the time source used for created/changed dates.
+func (p *Project) SetClock(now func() time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now = now
+}
+
+func (p *Project) orgURL() string {
+	if p.BaseURL != "" {
+		return strings.TrimRight(p.BaseURL, "/")
+	}
+	return "https://dev.azure.com/" + p.Org
+}
+
+func (p *Project) itemURL(id int) string {
+	return fmt.Sprintf("%s/_apis/wit/workItems/%d", p.orgURL(), id)
+}
+
+// Add creates a work item directly (without going through the Backend
+// methods) and returns its id. Extra fields are applied verbatim except
+// System.AssignedTo, which is resolved to an identity.
+func (p *Project) Add(wiType, title string, fields map[string]any) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	str := map[string]string{}
+	raw := map[string]any{}
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			str[k] = s
+		} else {
+			raw[k] = v
+		}
+	}
+	it := p.create(wiType, title, str)
+	for k, v := range raw {
+		it.fields[k] = v
+	}
+	it.revisions[0] = p.snapshot(it)
+	return it.id
+}
+
+// SetParent links child under parent.
+func (p *Project) SetParent(child, parent int) error {
+	_, err := p.AddWorkItemRelation(strconv.Itoa(child), "parent", strconv.Itoa(parent))
+	return err
+}
+
+// Get returns a copy of a work item.
+func (p *Project) Get(id int) (az.WorkItem, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, ok := p.items[id]
+	if !ok {
+		return az.WorkItem{}, false
+	}
+	return p.snapshot(it), true
+}
+
+// Field returns the string value of a field, or the display name for identities.
+func (p *Project) Field(id int, field string) string {
+	wi, ok := p.Get(id)
+	if !ok {
+		return ""
+	}
+	switch v := wi.Fields[field].(type) {
+	case string:
+		return v
+	case map[string]any:
+		s, _ := v["displayName"].(string)
+		return s
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Revisions returns every revision of a work item, oldest first.
+func (p *Project) Revisions(id int) []az.WorkItem {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, ok := p.items[id]
+	if !ok {
+		return nil
+	}
+	return append([]az.WorkItem(nil), it.revisions...)
+}
+
+// IDs returns the ids of all live work items in ascending order.
+func (p *Project) IDs() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ids := make([]int, 0, len(p.items))
+	for id := range p.items {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// snapshot deep-copies an item into the az.WorkItem shape.
+func (p *Project) snapshot(it *item) az.WorkItem {
+	fields := make(map[string]any, len(it.fields))
+	for k, v := range it.fields {
+		if m, ok := v.(map[string]any); ok {
+			cp := make(map[string]any, len(m))
+			for mk, mv := range m {
+				cp[mk] = mv
+			}
+			v = cp
+		}
+		fields[k] = v
+	}
+	rels := make([]az.Relation, len(it.relations))
+	copy(rels, it.relations)
+	if len(rels) == 0 {
+		rels = nil
+	}
+	return az.WorkItem{ID: it.id, Rev: it.rev, Fields: fields, Relations: rels, URL: p.itemURL(it.id)}
+}
+
+// identity resolves a name or email to an identity value as returned by Azure DevOps.
+func (p *Project) identity(v string) map[string]any {
+	for _, u := range append([]az.Identity{p.Me}, p.Users...) {
+		if strings.EqualFold(u.DisplayName, v) || strings.EqualFold(u.UniqueName, v) {
+			return map[string]any{"id": u.ID, "displayName": u.DisplayName, "uniqueName": u.UniqueName}
+		}
+	}
+	return map[string]any{"displayName": v, "uniqueName": v}
+}
+
+func (p *Project) columnFor(wiType string) (az.BoardColumn, bool) {
+	for _, c := range p.Columns {
+		if _, ok := c.StateMapping[wiType]; ok {
+			return c, true
+		}
+	}
+	return az.BoardColumn{}, false
+}
+
+// create must be called with p.mu held.
+func (p *Project) create(wiType, title string, fields map[string]string) *item {
+	now := p.now().UTC().Format(time.RFC3339)
+	it := &item{id: p.nextID, rev: 1, fields: map[string]any{
+		"System.Id":            p.nextID,
+		"System.WorkItemType":  wiType,
+		"System.Title":         title,
+		"System.State":         "New",
+		"System.TeamProject":   p.Name,
+		"System.AreaPath":      p.Name,
+		"System.IterationPath": p.Name,
+		"System.CreatedBy":     p.identity(p.Me.UniqueName),
+		"System.ChangedBy":     p.identity(p.Me.UniqueName),
+		"System.CreatedDate":   now,
+		"System.ChangedDate":   now,
+	}}
+	p.nextID++
+	if c, ok := p.columnFor(wiType); ok {
+		it.fields[KanbanField] = c.Name
+		it.fields["System.BoardColumn"] = c.Name
+	}
+	p.apply(it, fields)
+	p.items[it.id] = it
+	it.revisions = []az.WorkItem{p.snapshot(it)}
+	return it
+}
+
+// apply sets fields the way Azure DevOps does, keeping the Kanban column
+// and state in sync. Must be called with p.mu held.
+func (p *Project) apply(it *item, fields map[string]string) {
+	wiType, _ := it.fields["System.WorkItemType"].(string)
+	_, colSet := fields[KanbanField]
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := fields[k]
+		switch {
+		case k == "System.AssignedTo":
+			if strings.TrimSpace(v) == "" {
+				delete(it.fields, k)
+			} else {
+				it.fields[k] = p.identity(v)
+			}
+		case k == KanbanField || k == "System.BoardColumn":
+			it.fields[KanbanField] = v
+			it.fields["System.BoardColumn"] = v
+			for _, c := range p.Columns {
+				if c.Name == v {
+					if s, ok := c.StateMapping[wiType]; ok {
+						it.fields["System.State"] = s
+					}
+				}
+			}
+		case k == "System.State" && !colSet:
+			it.fields[k] = v
+			// Move the card to the first column mapping the new state.
+			cur, _ := it.fields[KanbanField].(string)
+			for _, c := range p.Columns {
+				if c.Name == cur && c.StateMapping[wiType] == v {
+					break
+				}
+				if s, ok := c.StateMapping[wiType]; ok && s == v {
+					it.fields[KanbanField] = c.Name
+					it.fields["System.BoardColumn"] = c.Name
+					break
+				}
+			}
+		case v == "":
+			delete(it.fields, k)
+		default:
+			it.fields[k] = v
+		}
+	}
+}
+
+// touch bumps the revision and records it. Must be called with p.mu held.
+func (p *Project) touch(it *item) {
+	it.rev++
+	it.fields["System.Rev"] = it.rev
+	it.fields["System.ChangedDate"] = p.now().UTC().Format(time.RFC3339)
+	it.fields["System.ChangedBy"] = p.identity(p.Me.UniqueName)
+	it.revisions = append(it.revisions, p.snapshot(it))
+}
+
+func (p *Project) lookup(id string) (*item, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return nil, fmt.Errorf("invalid work item id %q", id)
+	}
+	it, ok := p.items[n]
+	if !ok {
+		return nil, fmt.Errorf("TF401232: Work item %d does not exist, or you do not have permissions to read it", n)
+	}
+	return it, nil
+}
+
+// values returns the comparable string forms of a field for WIQL. The
+// [Source] prefix of link queries is ignored.
+func (p *Project) values(it *item, field string) []string {
+	if hasPrefixFold(field, sourcePrefix) {
+		field = field[len(sourcePrefix):]
+	}
+	if strings.EqualFold(field, "System.Id") {
+		return []string{strconv.Itoa(it.id)}
+	}
+	var v any
+	for k, fv := range it.fields {
+		if strings.EqualFold(k, field) {
+			v = fv
+			break
+		}
+	}
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if strings.EqualFold(field, "System.Tags") {
+			var out []string
+			for _, tag := range strings.Split(t, ";") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					out = append(out, tag)
+				}
+			}
+			// Also keep the whole string for CONTAINS on partial tags.
+			return append(out, t)
+		}
+		return []string{t}
+	case map[string]any:
+		dn, _ := t["displayName"].(string)
+		un, _ := t["uniqueName"].(string)
+		return []string{dn, un, fmt.Sprintf("%s <%s>", dn, un)}
+	default:
+		return []string{fmt.Sprint(t)}
+	}
+}
+
+// query evaluates a WIQL statement. Link queries also return their rows;
+// items are then the distinct targets in row order. Must be called with
+// p.mu held.
+func (p *Project) query(wiql string) (*query, []*item, []linkRow, error) {
+	q, err := parseWIQL(wiql)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("wiql: %w", err)
+	}
+	if q.links {
+		rows := p.queryLinks(q)
+		seen := map[int]bool{}
+		var out []*item
+		for _, r := range rows {
+			if !seen[r.target.id] {
+				seen[r.target.id] = true
+				out = append(out, r.target)
+			}
+		}
+		return q, out, rows, nil
+	}
+	var out []*item
+	for _, id := range sortedIDs(p.items) {
+		it := p.items[id]
+		if q.where == nil || q.where.eval(p.env(func(f string) []string { return p.values(it, f) })) {
+			out = append(out, it)
+		}
+	}
+	q.sortItems(out, p.values)
+	return q, out, nil, nil
+}
+
+// env returns the evaluation environment for values. Must be called with
+// p.mu held.
+func (p *Project) env(values func(field string) []string) *env {
+	return &env{
+		values:  values,
+		me:      []string{p.Me.DisplayName, p.Me.UniqueName},
+		today:   p.now().UTC().Truncate(24 * time.Hour),
+		project: p.Name,
+	}
+}
+
+// linkRow is a row of a link query: a top-level item (no source) or a
+// link from source to target.
+type linkRow struct {
+	source *item
+	rel    string
+	target *item
+}
+
+// queryLinks evaluates a WorkItemLinks query. Conditions on the source
+// select the top-level items; those on [Target] and the link type select
+// their links. Must be called with p.mu held.
+func (p *Project) queryLinks(q *query) []linkRow {
+	var srcConds, linkConds []expr
+	for _, c := range conjuncts(q.where) {
+		isLink := false
+		c.refs(func(f string) { isLink = isLink || linkRef(f) })
+		if isLink {
+			linkConds = append(linkConds, c)
+		} else {
+			srcConds = append(srcConds, c)
+		}
+	}
+	src, lnk := allOf(srcConds), allOf(linkConds)
+	envFor := func(s *item, rel string, t *item) *env {
+		return p.env(func(f string) []string {
+			switch {
+			case strings.EqualFold(f, linkTypeField):
+				return []string{rel}
+			case hasPrefixFold(f, targetPrefix):
+				if t == nil {
+					return nil
+				}
+				return p.values(t, f[len(targetPrefix):])
+			}
+			return p.values(s, f)
+		})
+	}
+	links := func(s *item) []linkRow {
+		var out []linkRow
+		for _, r := range s.relations {
+			if !strings.HasPrefix(r.Rel, "System.LinkTypes.") {
+				continue
+			}
+			id, err := strconv.Atoi(r.URL[strings.LastIndex(r.URL, "/")+1:])
+			if err != nil {
+				continue
+			}
+			t, ok := p.items[id]
+			if ok && (lnk == nil || lnk.eval(envFor(s, r.Rel, t))) {
+				out = append(out, linkRow{source: s, rel: r.Rel, target: t})
+			}
+		}
+		return out
+	}
+	var roots []*item
+	for _, id := range sortedIDs(p.items) {
+		it := p.items[id]
+		if src == nil || src.eval(envFor(it, "", nil)) {
+			roots = append(roots, it)
+		}
+	}
+	q.sortItems(roots, p.values)
+
+	var rows []linkRow
+	if q.mode == "recursive" {
+		// Items below another top-level item are only listed under it.
+		below := map[int]bool{}
+		var mark func(it *item, seen map[int]bool)
+		mark = func(it *item, seen map[int]bool) {
+			for _, l := range links(it) {
+				if !seen[l.target.id] {
+					seen[l.target.id], below[l.target.id] = true, true
+					mark(l.target, seen)
+				}
+			}
+		}
+		for _, r := range roots {
+			mark(r, map[int]bool{r.id: true})
+		}
+		var walk func(it *item, seen map[int]bool)
+		walk = func(it *item, seen map[int]bool) {
+			for _, l := range links(it) {
+				if !seen[l.target.id] {
+					seen[l.target.id] = true
+					rows = append(rows, l)
+					walk(l.target, seen)
+				}
+			}
+		}
+		for _, r := range roots {
+			if !below[r.id] {
+				rows = append(rows, linkRow{target: r})
+				walk(r, map[int]bool{r.id: true})
+			}
+		}
+		return rows
+	}
+	for _, r := range roots {
+		ls := links(r)
+		switch {
+		case q.mode == "mustcontain" && len(ls) == 0:
+			continue
+		case q.mode == "doesnotcontain":
+			if len(ls) == 0 {
+				rows = append(rows, linkRow{target: r})
+			}
+			continue
+		}
+		rows = append(rows, linkRow{target: r})
+		rows = append(rows, ls...)
+	}
+	return rows
+}
+
+func sortedIDs(m map[int]*item) []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// project returns a copy of it carrying only the requested fields.
+func (p *Project) project(it *item, fields []string) az.WorkItem {
+	wi := p.snapshot(it)
+	if len(fields) == 0 {
+		return wi
+	}
+	sel := map[string]any{}
+	for _, f := range fields {
+		for k, v := range wi.Fields {
+			if strings.EqualFold(k, f) {
+				sel[k] = v
+			}
+		}
+	}
+	wi.Fields = sel
+	wi.Relations = nil
+	return wi
+}
+
+// expand drops the relations of wi unless expand asks for them, like the
+// batch API does.
+func (p *Project) expand(wi az.WorkItem, expand string) az.WorkItem {
+	if !strings.EqualFold(expand, az.ExpandRelations) && !strings.EqualFold(expand, az.ExpandAll) {
+		wi.Relations = nil
+	}
+	return wi
+}
+
+// WorkItemsBatch implements az.Backend.
+func (p *Project) WorkItemsBatch(ids []int, fields []string, expand string) ([]az.WorkItem, error) {
+	if len(fields) > 0 && expand != az.ExpandNone {
+		return nil, fmt.Errorf("work items batch: expand %s cannot be combined with fields", expand)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]az.WorkItem, 0, len(ids))
+	for _, id := range ids {
+		if it, ok := p.items[id]; ok {
+			out = append(out, p.expand(p.project(it, fields), expand))
+		}
+	}
+	return out, nil
+}
+
+// QueryWIQL implements az.Backend.
+func (p *Project) QueryWIQL(wiql string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, items, _, err := p.query(wiql)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]az.WorkItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, p.project(it, q.fields))
+	}
+	return json.Marshal(out)
+}
+
+// QueryLinks implements az.Backend.
+func (p *Project) QueryLinks(wiql string) ([]az.WorkItemLink, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, _, rows, err := p.query(wiql)
+	if err != nil {
+		return nil, err
+	}
+	if !q.links {
+		return nil, fmt.Errorf("wiql: QueryLinks requires FROM WorkItemLinks")
+	}
+	out := make([]az.WorkItemLink, 0, len(rows))
+	for _, r := range rows {
+		l := az.WorkItemLink{Target: r.target.id, Rel: r.rel}
+		if r.source != nil {
+			l.Source = r.source.id
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+// ShowWorkItem implements az.Backend.
+func (p *Project) ShowWorkItem(id string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(p.snapshot(it))
+}
+
+// UpdateWorkItemFields implements az.Backend.
+func (p *Project) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	p.apply(it, fields)
+	p.touch(it)
+	return json.Marshal(p.snapshot(it))
+}
+
+// UpdateWorkItemFieldsAt implements az.Backend.
+func (p *Project) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if it.rev != rev {
+		return nil, fmt.Errorf("%w: %s", az.ErrConflict, errChanged)
+	}
+	p.apply(it, fields)
+	p.touch(it)
+	return json.Marshal(p.snapshot(it))
+}
+
+// errChanged is the message of a failed revision test.
+const errChanged = "TF26071: This work item has been changed by someone else since you opened it. You will need to refresh it and discard your changes."
+
+// UpdateWorkItemAssignee implements az.Backend.
+func (p *Project) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	return p.UpdateWorkItemFields(id, map[string]string{"System.AssignedTo": assignee})
+}
+
+// CreateWorkItem implements az.Backend.
+func (p *Project) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	if strings.TrimSpace(title) == "" {
+		return nil, fmt.Errorf("TF401320: Rule Error for field Title. Error code: Required")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it := p.create(wiType, title, fields)
+	return json.Marshal(p.snapshot(it))
+}
+
+// AddWorkItemRelation implements az.Backend. Hierarchy links are mirrored
+// on the target and update System.Parent, as in Azure DevOps.
+func (p *Project) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := p.lookup(targetID)
+	if err != nil {
+		return nil, err
+	}
+	rel := az.RelationRefName(relationType)
+	p.link(it, target, rel)
+	p.touch(it)
+	return json.Marshal(p.snapshot(it))
+}
+
+// link must be called with p.mu held.
+func (p *Project) link(it, target *item, rel string) {
+	it.relations = append(it.relations, az.Relation{Rel: rel, URL: p.itemURL(target.id), Attributes: map[string]any{"isLocked": false}})
+	switch rel {
+	case "System.LinkTypes.Hierarchy-Reverse":
+		it.fields["System.Parent"] = target.id
+		target.relations = append(target.relations, az.Relation{Rel: "System.LinkTypes.Hierarchy-Forward", URL: p.itemURL(it.id)})
+	case "System.LinkTypes.Hierarchy-Forward":
+		target.fields["System.Parent"] = it.id
+		target.relations = append(target.relations, az.Relation{Rel: "System.LinkTypes.Hierarchy-Reverse", URL: p.itemURL(it.id)})
+	}
+}
+
+// DeleteWorkItem implements az.Backend. Deleted items move to the recycle bin.
+func (p *Project) DeleteWorkItem(id string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	delete(p.items, it.id)
+	p.deleted[it.id] = it
+	return json.Marshal(p.deletion(it))
+}
+
+// deletion is the WorkItemDelete response of the delete and recycle bin
+// APIs. Must be called with p.mu held.
+func (p *Project) deletion(it *item) map[string]any {
+	return map[string]any{"id": it.id, "code": 200, "name": it.fields["System.Title"], "project": p.Name, "url": p.itemURL(it.id)}
+}
+
+// RemoveWorkItemRelation implements az.Backend. Removing a hierarchy link
+// also removes its mirror on the target and updates System.Parent.
+func (p *Project) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(it.relations, func(r az.Relation) bool {
+		return strings.EqualFold(r.Rel, rel) && az.SameRelationTarget(r.URL, target)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("work item %d has no %s relation to %s", it.id, rel, target)
+	}
+	removed := it.relations[i]
+	it.relations = slices.Delete(it.relations, i, i+1)
+	mirror := map[string]string{
+		"System.LinkTypes.Hierarchy-Reverse": "System.LinkTypes.Hierarchy-Forward",
+		"System.LinkTypes.Hierarchy-Forward": "System.LinkTypes.Hierarchy-Reverse",
+	}[removed.Rel]
+	if other, ok := p.items[urlItemID(removed.URL)]; ok && mirror != "" {
+		other.relations = slices.DeleteFunc(other.relations, func(r az.Relation) bool {
+			return r.Rel == mirror && az.SameRelationTarget(r.URL, strconv.Itoa(it.id))
+		})
+		child := it
+		if removed.Rel == "System.LinkTypes.Hierarchy-Forward" {
+			child = other
+		}
+		delete(child.fields, "System.Parent")
+	}
+	if removed.Rel == az.AttachedFileRel {
+		it.fields["System.AttachedFileCount"] = countRel(it.relations, az.AttachedFileRel)
+	}
+	p.touch(it)
+	return json.Marshal(p.snapshot(it))
+}
+
+// RestoreWorkItem implements az.Backend by taking the item out of the
+// recycle bin.
+func (p *Project) RestoreWorkItem(id string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, err := strconv.Atoi(strings.TrimSpace(id))
+	if err != nil {
+		return nil, fmt.Errorf("invalid work item id %q", id)
+	}
+	it, ok := p.deleted[n]
+	if !ok {
+		return nil, fmt.Errorf("TF401232: Work item %d does not exist in the recycle bin", n)
+	}
+	delete(p.deleted, n)
+	p.items[n] = it
+	p.touch(it)
+	return json.Marshal(p.deletion(it))
+}
+
+// WorkItemUpdates implements az.Backend by diffing consecutive revisions.
+// Like Azure DevOps, RevisedDate is when the revision was superseded.
+func (p *Project) WorkItemUpdates(id string) ([]az.WorkItemUpdate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]az.WorkItemUpdate, 0, len(it.revisions))
+	var prev map[string]any
+	for i, rev := range it.revisions {
+		u := az.WorkItemUpdate{ID: i + 1, WorkItemID: it.id, Rev: rev.Rev, Fields: map[string]az.FieldChange{}}
+		u.RevisedDate = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+		if i+1 < len(it.revisions) {
+			if s, ok := it.revisions[i+1].Fields["System.ChangedDate"].(string); ok {
+				u.RevisedDate, _ = time.Parse(time.RFC3339, s)
+			}
+		}
+		if by, ok := rev.Fields["System.ChangedBy"].(map[string]any); ok {
+			u.RevisedBy.ID, _ = by["id"].(string)
+			u.RevisedBy.DisplayName, _ = by["displayName"].(string)
+			u.RevisedBy.UniqueName, _ = by["uniqueName"].(string)
+		}
+		for k, v := range rev.Fields {
+			if old, ok := prev[k]; !ok || !reflect.DeepEqual(old, v) {
+				u.Fields[k] = az.FieldChange{OldValue: prev[k], NewValue: v}
+			}
+		}
+		for k, old := range prev {
+			if _, ok := rev.Fields[k]; !ok {
+				u.Fields[k] = az.FieldChange{OldValue: old}
+			}
+		}
+		out = append(out, u)
+		prev = rev.Fields
+	}
+	return out, nil
+}
+
+// Comments implements az.Backend.
+func (p *Project) Comments(id string, top int) ([]az.Comment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]az.Comment(nil), it.comments...)
+	if top > 0 && len(out) > top {
+		out = out[len(out)-top:]
+	}
+	return out, nil
+}
+
+// AddComment implements az.Backend. Like Azure DevOps it records a new
+// revision with the updated System.CommentCount.
+func (p *Project) AddComment(id, html string) (*az.Comment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	c := az.Comment{
+		ID:          p.nextCmt,
+		WorkItemID:  it.id,
+		Text:        html,
+		CreatedBy:   p.Me,
+		CreatedDate: p.now().UTC(),
+	}
+	p.nextCmt++
+	it.comments = append(it.comments, c)
+	it.fields["System.CommentCount"] = len(it.comments)
+	p.touch(it)
+	return &c, nil
+}
+
+// UploadAttachment implements az.Backend.
+func (p *Project) UploadAttachment(path, name string) (*az.AttachmentRef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.upload(name, data), nil
+}
+
+func (p *Project) upload(name string, data []byte) *az.AttachmentRef {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := fmt.Sprintf("00000000-0000-0000-0000-%012d", len(p.uploads)+1)
+	p.uploads[id] = upload{name: name, data: append([]byte(nil), data...), created: p.now().UTC()}
+	return &az.AttachmentRef{ID: id, URL: p.orgURL() + "/_apis/wit/attachments/" + id + "?fileName=" + url.QueryEscape(name)}
+}
+
+// LinkAttachment implements az.Backend. Like Azure DevOps the relation
+// carries the file name and size of the upload.
+func (p *Project) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	it, err := p.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	up, ok := p.uploads[attachmentKey(attachmentURL)]
+	if !ok {
+		return nil, fmt.Errorf("attachment %s not found", attachmentURL)
+	}
+	attrs := map[string]any{
+		"name":                up.name,
+		"resourceSize":        float64(len(up.data)),
+		"resourceCreatedDate": up.created.Format(time.RFC3339),
+		"authorizedDate":      p.now().UTC().Format(time.RFC3339),
+	}
+	if comment != "" {
+		attrs["comment"] = comment
+	}
+	it.relations = append(it.relations, az.Relation{Rel: az.AttachedFileRel, URL: attachmentURL, Attributes: attrs})
+	it.fields["System.AttachedFileCount"] = countRel(it.relations, az.AttachedFileRel)
+	p.touch(it)
+	return json.Marshal(p.snapshot(it))
+}
+
+// DownloadAttachment implements az.Backend.
+func (p *Project) DownloadAttachment(attachmentURL, dest string) error {
+	data, err := p.attachment(attachmentKey(attachmentURL))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dest, data, 0644)
+}
+
+func (p *Project) attachment(key string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	up, ok := p.uploads[key]
+	if !ok {
+		return nil, fmt.Errorf("attachment %s not found", key)
+	}
+	return append([]byte(nil), up.data...), nil
+}
+
+func attachmentKey(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	return u[strings.LastIndex(u, "/")+1:]
+}
+
+// urlItemID returns the id at the end of a work item URL.
+func urlItemID(u string) int {
+	n, _ := strconv.Atoi(u[strings.LastIndex(u, "/")+1:])
+	return n
+}
+
+func countRel(rels []az.Relation, rel string) int {
+	n := 0
+	for _, r := range rels {
+		if r.Rel == rel {
+			n++
+		}
+	}
+	return n
+}
+
+// CurrentUser implements az.Backend.
+func (p *Project) CurrentUser() (*az.Identity, error) {
+	me := p.Me
+	return &me, nil
+}
+
+// Defaults implements az.Backend.
+func (p *Project) Defaults() (*az.DevOpsDefaults, error) {
+	return &az.DevOpsDefaults{Organization: p.orgURL(), Project: p.Name, Team: p.Team}, nil
+}
+
+// Boards implements az.Backend. The fake team has a single Stories board.
+func (p *Project) Boards(team string) ([]az.Board, error) {
+	if !strings.EqualFold(team, p.Team) {
+		return nil, fmt.Errorf("team %q not found", team)
+	}
+	return []az.Board{{ID: "stories", Name: "Stories"}}, nil
+}
+
+// BoardColumns implements az.Backend.
+func (p *Project) BoardColumns(team, boardID string) ([]az.BoardColumn, error) {
+	if _, err := p.Boards(team); err != nil {
+		return nil, err
+	}
+	if boardID != "stories" && !strings.EqualFold(boardID, "Stories") {
+		return nil, fmt.Errorf("board %q not found", boardID)
+	}
+	return append([]az.BoardColumn(nil), p.Columns...), nil
+}
+
+// Tags implements az.Backend with the tags in use on any work item.
+func (p *Project) Tags() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	seen := map[string]bool{}
+	var out []string
+	for _, it := range p.items {
+		tags, _ := it.fields["System.Tags"].(string)
+		for _, t := range strings.Split(tags, ";") {
+			t = strings.TrimSpace(t)
+			if t != "" && !seen[strings.ToLower(t)] {
+				seen[strings.ToLower(t)] = true
+				out = append(out, t)
+			}
+		}
+	}
+	az.SortTags(out)
+	return out, nil
+}
+
+// Iterations implements az.Backend.
+func (p *Project) Iterations(team string) ([]az.Iteration, error) {
+	if !strings.EqualFold(team, p.Team) {
+		return nil, fmt.Errorf("team %q not found", team)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]az.Iteration(nil), p.Sprints...), nil
+}
+
+// Areas implements az.Backend.
+func (p *Project) Areas() ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.AreaPaths...), nil
+}
+
+// ListRepos implements az.Backend.
+func (p *Project) ListRepos() ([]az.Repo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]az.Repo(nil), p.Repos...), nil
+}
+
+// CreateRepo implements az.Backend.
+func (p *Project) CreateRepo(name string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range p.Repos {
+		if strings.EqualFold(r.Name, name) {
+			return nil, fmt.Errorf("TF400948: A Git repository with the name %s already exists", name)
+		}
+	}
+	r := az.Repo{
+		ID:        fmt.Sprintf("repo-%d", len(p.Repos)+1),
+		Name:      name,
+		SSHURL:    fmt.Sprintf("git@ssh.dev.azure.com:v3/%s/%s/%s", p.Org, p.Name, name),
+		RemoteURL: fmt.Sprintf("%s/%s/_git/%s", p.orgURL(), p.Name, name),
+		WebURL:    fmt.Sprintf("%s/%s/_git/%s", p.orgURL(), p.Name, name),
+	}
+	p.Repos = append(p.Repos, r)
+	return json.Marshal(r)
+}
+
+// DeleteRepo implements az.Backend.
+func (p *Project) DeleteRepo(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, r := range p.Repos {
+		if r.ID == id {
+			p.Repos = append(p.Repos[:i], p.Repos[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("repository %s not found", id)
+}
+
+// Seed is the JSON document accepted by Load.
+type Seed struct {
+	Me        *az.Identity     `json:"me,omitempty"`
+	Users     []az.Identity    `json:"users,omitempty"`
+	Columns   []az.BoardColumn `json:"columns,omitempty"`
+	Repos     []az.Repo        `json:"repos,omitempty"`
+	WorkItems []struct {
+		Type   string         `json:"type"`
+		Title  string         `json:"title"`
+		Parent int            `json:"parent,omitempty"`
+		Fields map[string]any `json:"fields,omitempty"`
+	} `json:"workItems,omitempty"`
+}
+
+// Load populates the project from a JSON Seed. Work items are created in
+// order, so ids are assigned sequentially from 1 and parents must appear
+// before their children.
+func (p *Project) Load(r io.Reader) error {
+	var s Seed
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return fmt.Errorf("parse seed: %w", err)
+	}
+	if s.Me != nil {
+		p.Me = *s.Me
+	}
+	if len(s.Users) > 0 {
+		p.Users = s.Users
+	}
+	if len(s.Columns) > 0 {
+		p.Columns = s.Columns
+	}
+	p.Repos = append(p.Repos, s.Repos...)
+	for _, w := range s.WorkItems {
+		id := p.Add(w.Type, w.Title, w.Fields)
+		if w.Parent != 0 {
+			if err := p.SetParent(id, w.Parent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
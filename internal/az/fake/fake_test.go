@@ -0,0 +1,235 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+func ids(t *testing.T, raw []byte) []int {
+	t.Helper()
+	var items []az.WorkItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out := make([]int, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.ID)
+	}
+	return out
+}
+
+func TestQueryWIQL_FiltersAndOrders(t *testing.T) {
+	p := New()
+	p.Add("User Story", "Story A", map[string]any{"System.AssignedTo": "me@example.com", "Microsoft.VSTS.Common.StackRank": 2.0})
+	p.Add("Task", "Task B", nil)
+	closed := p.Add("User Story", "Story C", map[string]any{"Microsoft.VSTS.Common.StackRank": 1.0})
+	if _, err := p.UpdateWorkItemFields("3", map[string]string{"System.State": "Closed"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		wiql string
+		want []int
+	}{
+		{"SELECT [System.Id] FROM WorkItems WHERE [System.State] <> 'Closed' ORDER BY [System.Id] DESC", []int{2, 1}},
+		{"SELECT [System.Id] FROM WorkItems WHERE [System.WorkItemType] IN ('User Story','Bug') ORDER BY [Microsoft.VSTS.Common.StackRank] ASC", []int{closed, 1}},
+		{"SELECT [System.Id] FROM WorkItems WHERE [System.WorkItemType] NOT IN ('User Story','Bug')", []int{2}},
+		{"SELECT [System.Id] FROM WorkItems WHERE [System.AssignedTo] = @Me", []int{1}},
+		{`SELECT [System.Id] FROM WorkItems WHERE [System.Title] CONTAINS 'story' AND NOT ([System.State] = "Closed")`, []int{1}},
+		{"SELECT [System.Id] FROM WorkItems WHERE [System.Id] IN (1,2) AND [System.State] <> \"Closed\"", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		raw, err := p.QueryWIQL(tt.wiql)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.wiql, err)
+		}
+		got := ids(t, raw)
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %v want %v", tt.wiql, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("%s: got %v want %v", tt.wiql, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestQueryWIQL_TodayMacro(t *testing.T) {
+	p := New()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func() time.Time { return now.AddDate(0, 0, -5) })
+	p.Add("Task", "old", nil)
+	p.SetClock(func() time.Time { return now })
+	p.Add("Task", "new", nil)
+	raw, err := p.QueryWIQL("SELECT [System.Id] FROM WorkItems WHERE [System.ChangedDate] >= @Today - 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(t, raw); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestKanbanColumnDrivesState(t *testing.T) {
+	p := New()
+	id := p.Add("User Story", "S", nil)
+	if got := p.Field(id, KanbanField); got != "Backlog" {
+		t.Fatalf("new story column = %q", got)
+	}
+	if _, err := p.UpdateWorkItemFields("1", map[string]string{KanbanField: "In Process"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Field(id, "System.State"); got != "Active" {
+		t.Fatalf("state after move = %q", got)
+	}
+	if revs := p.Revisions(id); len(revs) != 2 || revs[1].Rev != 2 {
+		t.Fatalf("unexpected revisions: %+v", revs)
+	}
+}
+
+func TestSetParentMirrorsHierarchy(t *testing.T) {
+	p := New()
+	story := p.Add("User Story", "S", nil)
+	task := p.Add("Task", "T", nil)
+	if err := p.SetParent(task, story); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := p.QueryWIQL("SELECT [System.Id] FROM WorkItems WHERE [System.Parent] = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(t, raw); len(got) != 1 || got[0] != task {
+		t.Fatalf("children = %v", got)
+	}
+	parent, _ := p.Get(story)
+	if len(parent.Relations) != 1 || parent.Relations[0].Rel != "System.LinkTypes.Hierarchy-Forward" {
+		t.Fatalf("parent relations = %+v", parent.Relations)
+	}
+}
+
+// The REST backend against the served fake must behave like the in-process fake.
+func TestServer_RESTBackendRoundTrip(t *testing.T) {
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+	_ = az.SetConfirmMode("never")
+	az.SetSilent(true)
+	defer az.SetSilent(false)
+	p := New()
+	p.Add("User Story", "Parent", nil)
+	srv := p.NewServer()
+	defer srv.Close()
+	defer az.Use(az.NewRESTBackend(srv.URL+"/"+p.Org, p.Name))()
+
+	raw, err := az.CreateWorkItem("Task", "Child", map[string]string{"System.AssignedTo": "Me User"}, "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	var wi az.WorkItem
+	_ = json.Unmarshal(raw, &wi)
+	if _, err := az.AddWorkItemRelation("2", "parent", "1"); err != nil {
+		t.Fatalf("relation: %v", err)
+	}
+	qraw, err := az.QueryWIQL("SELECT [System.Id], [System.Title], [System.AssignedTo] FROM WorkItems WHERE [System.Parent] = 1")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	var items []az.WorkItem
+	_ = json.Unmarshal(qraw, &items)
+	if len(items) != 1 || items[0].ID != wi.ID || items[0].Fields["System.Title"] != "Child" {
+		t.Fatalf("unexpected query result: %s", qraw)
+	}
+	name, err := az.CurrentUserDisplayName()
+	if err != nil || name != "Me User" {
+		t.Fatalf("current user = %q, %v", name, err)
+	}
+	cols, err := az.BoardColumnsForType("User Story")
+	if err != nil || len(cols) != len(DefaultColumns) {
+		t.Fatalf("board columns = %v, %v", cols, err)
+	}
+	areas, err := az.AreaPaths()
+	if err != nil || len(areas) != 4 || areas[0] != "Fake" || areas[3] != `Fake\Frontend\Mobile` {
+		t.Fatalf("areas = %v, %v", areas, err)
+	}
+	its, err := az.TeamIterations()
+	if err != nil || len(its) != 3 || !its[1].IsCurrent(time.Now()) {
+		t.Fatalf("iterations = %+v, %v", its, err)
+	}
+	if _, err := az.UpdateWorkItemFields("1", map[string]string{"System.Tags": "b; A"}); err != nil {
+		t.Fatal(err)
+	}
+	if tags, err := az.Tags(); err != nil || len(tags) != 2 || tags[0] != "A" {
+		t.Fatalf("tags = %v, %v", tags, err)
+	}
+	if _, err := az.CreateRepo("svc"); err != nil {
+		t.Fatalf("create repo: %v", err)
+	}
+	repos, err := az.ListRepos()
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("repos = %v, %v", repos, err)
+	}
+	if err := az.DeleteRepo(repos[0].ID); err != nil {
+		t.Fatalf("delete repo: %v", err)
+	}
+	if _, err := az.DeleteWorkItem("2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, err := az.ShowWorkItem("2"); err == nil {
+		t.Fatal("expected error showing deleted item")
+	}
+}
+
+func TestServer_WorkItemsBatchChunks(t *testing.T) {
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "fake")
+	_ = az.SetConfirmMode("never")
+	az.SetSilent(true)
+	defer az.SetSilent(false)
+	p := New()
+	var added []int
+	for i := 0; i < 450; i++ {
+		added = append(added, p.Add("Task", fmt.Sprintf("Task %d", i), nil))
+	}
+	if err := p.SetParent(added[1], added[0]); err != nil {
+		t.Fatal(err)
+	}
+	batches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/workitemsbatch") {
+			batches++
+		}
+		p.Handler().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+	p.BaseURL = srv.URL + "/" + p.Org
+	defer az.Use(az.NewRESTBackend(p.BaseURL, p.Name))()
+
+	want := append([]int{9999}, added...)
+	slices.Reverse(want)
+	items, err := az.WorkItemsBatch(want, []string{"System.Title"}, az.ExpandNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batches != 3 {
+		t.Fatalf("batch requests = %d, want 3", batches)
+	}
+	if len(items) != len(added) || items[0].ID != added[len(added)-1] || items[len(items)-1].ID != added[0] {
+		t.Fatalf("got %d items, first %d, last %d", len(items), items[0].ID, items[len(items)-1].ID)
+	}
+	if items[0].Fields["System.Title"] != "Task 449" || len(items[0].Fields) != 1 {
+		t.Fatalf("fields = %v", items[0].Fields)
+	}
+
+	items, err = az.WorkItemsBatch(added[:1], nil, az.ExpandRelations)
+	if err != nil || len(items) != 1 || len(items[0].Relations) != 1 {
+		t.Fatalf("expand relations = %+v, %v", items, err)
+	}
+	if _, err := az.WorkItemsBatch(added[:1], []string{"System.Title"}, az.ExpandAll); err == nil {
+		t.Fatal("fields combined with expand accepted")
+	}
+}
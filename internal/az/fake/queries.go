@@ -0,0 +1,140 @@
+package fake
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+// Root folders of the query hierarchy.
+const (
+	MyQueries     = "My Queries"
+	SharedQueries = "Shared Queries"
+)
+
+// AddQuery saves wiql at path, e.g. "Shared Queries/Team/Open bugs",
+// creating missing folders, and returns the query's id. The first path
+// segment must be MyQueries or SharedQueries.
+func (p *Project) AddQuery(path, wiql string) (string, error) {
+	q, err := parseWIQL(wiql)
+	if err != nil {
+		return "", fmt.Errorf("wiql: %w", err)
+	}
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segs) < 2 || (segs[0] != MyQueries && segs[0] != SharedQueries) {
+		return "", fmt.Errorf("query path %q must start with %q or %q", path, MyQueries, SharedQueries)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queries == nil {
+		p.queries = []*az.SavedQuery{
+			{ID: "folder-my", Name: MyQueries, Path: MyQueries, IsFolder: true},
+			{ID: "folder-shared", Name: SharedQueries, Path: SharedQueries, IsFolder: true, IsPublic: true},
+		}
+	}
+	folder := p.queries[0]
+	if segs[0] == SharedQueries {
+		folder = p.queries[1]
+	}
+	for _, name := range segs[1 : len(segs)-1] {
+		var next *az.SavedQuery
+		for i := range folder.Children {
+			if c := &folder.Children[i]; c.IsFolder && strings.EqualFold(c.Name, name) {
+				next = c
+			}
+		}
+		if next == nil {
+			p.nextQuery++
+			folder.Children = append(folder.Children, az.SavedQuery{
+				ID: fmt.Sprintf("folder-%d", p.nextQuery), Name: name, Path: folder.Path + "/" + name,
+				IsFolder: true, IsPublic: folder.IsPublic,
+			})
+			next = &folder.Children[len(folder.Children)-1]
+		}
+		folder.HasChildren = true
+		folder = next
+	}
+	p.nextQuery++
+	saved := az.SavedQuery{
+		ID:        fmt.Sprintf("query-%d", p.nextQuery),
+		Name:      segs[len(segs)-1],
+		Path:      folder.Path + "/" + segs[len(segs)-1],
+		IsPublic:  folder.IsPublic,
+		QueryType: "flat",
+		WIQL:      wiql,
+	}
+	if q.links {
+		saved.QueryType = "oneHop"
+		if q.mode == "recursive" {
+			saved.QueryType = "tree"
+		}
+	}
+	folder.Children = append(folder.Children, saved)
+	folder.HasChildren = true
+	return saved.ID, nil
+}
+
+// SavedQueries implements az.Backend.
+func (p *Project) SavedQueries() ([]az.SavedQuery, error) {
+	return p.savedQueries(-1), nil
+}
+
+// savedQueries returns the hierarchy without WIQL, expanded depth levels
+// below the roots (all with depth < 0).
+func (p *Project) savedQueries(depth int) []az.SavedQuery {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := []az.SavedQuery{}
+	for _, f := range p.queries {
+		out = append(out, copyQuery(*f, depth, false))
+	}
+	return out
+}
+
+// SavedQuery implements az.Backend.
+func (p *Project) SavedQuery(idOrPath string) (*az.SavedQuery, error) {
+	return p.savedQuery(idOrPath, -1, true)
+}
+
+func (p *Project) savedQuery(idOrPath string, depth int, withWIQL bool) (*az.SavedQuery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	want := strings.Trim(idOrPath, "/")
+	var find func(qs []az.SavedQuery) *az.SavedQuery
+	find = func(qs []az.SavedQuery) *az.SavedQuery {
+		for i := range qs {
+			if qs[i].ID == want || strings.EqualFold(qs[i].Path, want) {
+				return &qs[i]
+			}
+			if q := find(qs[i].Children); q != nil {
+				return q
+			}
+		}
+		return nil
+	}
+	for _, f := range p.queries {
+		if q := find([]az.SavedQuery{*f}); q != nil {
+			cp := copyQuery(*q, depth, withWIQL)
+			return &cp, nil
+		}
+	}
+	return nil, fmt.Errorf("TF401243: The query %s does not exist, or you do not have permission to read it", idOrPath)
+}
+
+// copyQuery deep-copies q with depth levels of children (all with depth
+// < 0), dropping the WIQL unless withWIQL.
+func copyQuery(q az.SavedQuery, depth int, withWIQL bool) az.SavedQuery {
+	out := q
+	if !withWIQL {
+		out.WIQL = ""
+	}
+	out.Children = nil
+	if depth == 0 {
+		return out
+	}
+	for _, c := range q.Children {
+		out.Children = append(out.Children, copyQuery(c, depth-1, withWIQL))
+	}
+	return out
+}
@@ -0,0 +1,467 @@
+package fake
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+// NewServer serves the project over HTTP with the subset of the Azure
+// DevOps REST API used by the native backend. The organization URL to pass
+// to az.NewRESTBackend is srv.URL + "/" + p.Org. Authentication is not
+// checked.
+func (p *Project) NewServer() *httptest.Server {
+	srv := httptest.NewServer(p.Handler())
+	p.BaseURL = srv.URL + "/" + p.Org
+	return srv
+}
+
+// Handler returns the HTTP handler used by NewServer.
+func (p *Project) Handler() http.Handler {
+	return http.HandlerFunc(p.serveHTTP)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"message": err.Error()})
+}
+
+func writeRaw(w http.ResponseWriter, raw []byte, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.HasPrefix(err.Error(), "TF401232") {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(raw)
+}
+
+func (p *Project) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	segs := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segs) < 2 || segs[0] != p.Org {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown organization in %s", r.URL.Path))
+		return
+	}
+	segs = segs[1:]
+	// Organization-scoped endpoints
+	if segs[0] == "_apis" {
+		p.serveOrg(w, r, segs[1:])
+		return
+	}
+	if segs[0] != p.Name {
+		writeError(w, http.StatusNotFound, fmt.Errorf("project %q not found", segs[0]))
+		return
+	}
+	segs = segs[1:]
+	if len(segs) >= 2 && segs[0] != "_apis" && segs[1] == "_apis" {
+		p.serveTeam(w, r, segs[0], segs[2:])
+		return
+	}
+	if len(segs) == 0 || segs[0] != "_apis" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported path %s", r.URL.Path))
+		return
+	}
+	p.serveProject(w, r, segs[1:])
+}
+
+func (p *Project) serveOrg(w http.ResponseWriter, r *http.Request, segs []string) {
+	switch {
+	case len(segs) == 1 && segs[0] == "connectionData":
+		writeJSON(w, http.StatusOK, map[string]any{"authenticatedUser": map[string]any{
+			"id":                  p.Me.ID,
+			"providerDisplayName": p.Me.DisplayName,
+			"properties":          map[string]any{"Account": map[string]string{"$type": "System.String", "$value": p.Me.UniqueName}},
+		}})
+	case len(segs) == 2 && segs[0] == "projects" && segs[1] == p.Name:
+		writeJSON(w, http.StatusOK, map[string]any{"id": "project-" + p.Org, "name": p.Name, "defaultTeam": map[string]string{"name": p.Team}})
+	case len(segs) >= 3 && segs[0] == "wit" && strings.EqualFold(segs[1], "workitems"):
+		// Organization-scoped work item URLs as used in relation links.
+		p.serveProject(w, r, segs)
+	case len(segs) == 3 && segs[0] == "wit" && segs[1] == "attachments" && r.Method == http.MethodGet:
+		data, err := p.attachment(segs[2])
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(data)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported path %s", r.URL.Path))
+	}
+}
+
+func (p *Project) serveTeam(w http.ResponseWriter, r *http.Request, team string, segs []string) {
+	switch {
+	case len(segs) == 2 && segs[0] == "work" && segs[1] == "boards":
+		boards, err := p.Boards(team)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(boards), "value": boards})
+	case len(segs) == 4 && segs[0] == "work" && segs[1] == "boards" && segs[3] == "columns":
+		cols, err := p.BoardColumns(team, segs[2])
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(cols), "value": cols})
+	case len(segs) == 3 && segs[0] == "work" && segs[1] == "teamsettings" && segs[2] == "iterations":
+		its, err := p.Iterations(team)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(its), "value": its})
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported path %s", r.URL.Path))
+	}
+}
+
+func (p *Project) serveProject(w http.ResponseWriter, r *http.Request, segs []string) {
+	switch {
+	case len(segs) == 2 && segs[0] == "wit" && segs[1] == "wiql" && r.Method == http.MethodPost:
+		p.serveWIQL(w, r)
+	case len(segs) == 2 && segs[0] == "wit" && segs[1] == "workitemsbatch" && r.Method == http.MethodPost:
+		p.serveBatch(w, r)
+	case len(segs) == 3 && segs[0] == "wit" && strings.EqualFold(segs[1], "workitems"):
+		p.serveWorkItem(w, r, segs[2])
+	case len(segs) == 3 && segs[0] == "wit" && segs[1] == "recyclebin" && r.Method == http.MethodPatch:
+		raw, err := p.RestoreWorkItem(segs[2])
+		writeRaw(w, raw, err)
+	case len(segs) == 3 && segs[0] == "wit" && segs[1] == "classificationnodes" && strings.EqualFold(segs[2], "Areas"):
+		areas, _ := p.Areas()
+		writeJSON(w, http.StatusOK, areaTree(areas))
+	case len(segs) == 2 && segs[0] == "wit" && segs[1] == "tags" && r.Method == http.MethodGet:
+		tags, _ := p.Tags()
+		value := make([]map[string]string, 0, len(tags))
+		for _, t := range tags {
+			value = append(value, map[string]string{"name": t})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(value), "value": value})
+	case len(segs) == 2 && segs[0] == "wit" && segs[1] == "attachments" && r.Method == http.MethodPost:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, p.upload(r.URL.Query().Get("fileName"), data))
+	case len(segs) == 2 && segs[0] == "wit" && segs[1] == "queries" && r.Method == http.MethodGet:
+		depth, _ := strconv.Atoi(r.URL.Query().Get("$depth"))
+		qs := p.savedQueries(depth)
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(qs), "value": qs})
+	case len(segs) >= 3 && segs[0] == "wit" && segs[1] == "queries" && r.Method == http.MethodGet:
+		depth, _ := strconv.Atoi(r.URL.Query().Get("$depth"))
+		q, err := p.savedQuery(strings.Join(segs[2:], "/"), depth, r.URL.Query().Get("$expand") == "wiql")
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, q)
+	case len(segs) == 4 && segs[0] == "wit" && strings.EqualFold(segs[1], "workitems") && segs[3] == "comments":
+		p.serveComments(w, r, segs[2])
+	case len(segs) == 4 && segs[0] == "wit" && strings.EqualFold(segs[1], "workitems") && segs[3] == "updates" && r.Method == http.MethodGet:
+		p.serveUpdates(w, r, segs[2])
+	case len(segs) >= 2 && segs[0] == "git" && segs[1] == "repositories":
+		p.serveRepos(w, r, segs[2:])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported path %s", r.URL.Path))
+	}
+}
+
+func (p *Project) serveWIQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, items, rows, err := p.query(req.Query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cols := make([]map[string]string, 0, len(q.fields))
+	for _, f := range q.fields {
+		cols = append(cols, map[string]string{"referenceName": f})
+	}
+	ref := func(it *item) map[string]any {
+		if it == nil {
+			return nil
+		}
+		return map[string]any{"id": it.id, "url": p.itemURL(it.id)}
+	}
+	if q.links {
+		rels := make([]map[string]any, 0, len(rows))
+		for _, r := range rows {
+			var rel any
+			if r.rel != "" {
+				rel = r.rel
+			}
+			rels = append(rels, map[string]any{"rel": rel, "source": ref(r.source), "target": ref(r.target)})
+		}
+		queryType := "oneHop"
+		if q.mode == "recursive" {
+			queryType = "tree"
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"queryType": queryType, "columns": cols, "workItemRelations": rels})
+		return
+	}
+	refs := make([]map[string]any, 0, len(items))
+	for _, it := range items {
+		refs = append(refs, ref(it))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"queryType": "flat", "columns": cols, "workItems": refs})
+}
+
+func (p *Project) serveBatch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IDs         []int    `json:"ids"`
+		Fields      []string `json:"fields"`
+		Expand      string   `json:"$expand"`
+		ErrorPolicy string   `json:"errorPolicy"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(req.IDs) > 200 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("VS402337: at most 200 work items per batch, got %d", len(req.IDs)))
+		return
+	}
+	if len(req.Fields) > 0 && req.Expand != "" && !strings.EqualFold(req.Expand, "None") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the expand parameter can not be used with the fields parameter"))
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := []any{}
+	for _, id := range req.IDs {
+		it, ok := p.items[id]
+		if !ok {
+			if strings.EqualFold(req.ErrorPolicy, "Omit") {
+				out = append(out, nil)
+				continue
+			}
+			writeError(w, http.StatusNotFound, fmt.Errorf("TF401232: Work item %d does not exist, or you do not have permissions to read it", id))
+			return
+		}
+		out = append(out, p.expand(p.project(it, req.Fields), req.Expand))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(out), "value": out})
+}
+
+// patchOp mirrors the JSON Patch operations sent by the REST backend.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (p *Project) serveWorkItem(w http.ResponseWriter, r *http.Request, idSeg string) {
+	switch r.Method {
+	case http.MethodGet:
+		raw, err := p.ShowWorkItem(idSeg)
+		writeRaw(w, raw, err)
+	case http.MethodDelete:
+		raw, err := p.DeleteWorkItem(idSeg)
+		writeRaw(w, raw, err)
+	case http.MethodPatch, http.MethodPost:
+		var ops []patchOp
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &ops); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		fields := map[string]string{}
+		var rels, removed []az.Relation
+		n, _ := strconv.Atoi(idSeg)
+		for _, op := range ops {
+			switch {
+			case op.Op == "test" && op.Path == "/rev":
+				var rev int
+				_ = json.Unmarshal(op.Value, &rev)
+				if cur, ok := p.Get(n); !ok || cur.Rev != rev {
+					writeError(w, http.StatusPreconditionFailed, errors.New(errChanged))
+					return
+				}
+			case op.Op == "remove" && strings.HasPrefix(op.Path, "/relations/"):
+				i, err := strconv.Atoi(strings.TrimPrefix(op.Path, "/relations/"))
+				cur, ok := p.Get(n)
+				if err != nil || !ok || i < 0 || i >= len(cur.Relations) {
+					writeError(w, http.StatusBadRequest, fmt.Errorf("invalid patch path %s", op.Path))
+					return
+				}
+				removed = append(removed, cur.Relations[i])
+			case strings.HasPrefix(op.Path, "/fields/"):
+				var v any
+				_ = json.Unmarshal(op.Value, &v)
+				s, ok := v.(string)
+				if !ok && v != nil {
+					s = fmt.Sprint(v)
+				}
+				fields[strings.TrimPrefix(op.Path, "/fields/")] = s
+			case op.Path == "/relations/-":
+				var rel az.Relation
+				_ = json.Unmarshal(op.Value, &rel)
+				rels = append(rels, rel)
+			default:
+				writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported patch path %s", op.Path))
+				return
+			}
+		}
+		var raw []byte
+		var err error
+		id := idSeg
+		if strings.HasPrefix(idSeg, "$") {
+			title := fields["System.Title"]
+			delete(fields, "System.Title")
+			raw, err = p.CreateWorkItem(strings.TrimPrefix(idSeg, "$"), title, fields)
+			if err == nil {
+				var wi struct {
+					ID int `json:"id"`
+				}
+				_ = json.Unmarshal(raw, &wi)
+				id = strconv.Itoa(wi.ID)
+			}
+		} else if len(fields) > 0 {
+			raw, err = p.UpdateWorkItemFields(id, fields)
+		}
+		for _, rel := range removed {
+			if err != nil {
+				break
+			}
+			raw, err = p.RemoveWorkItemRelation(id, rel.Rel, rel.URL)
+		}
+		for _, rel := range rels {
+			if err != nil {
+				break
+			}
+			if rel.Rel == az.AttachedFileRel {
+				comment, _ := rel.Attributes["comment"].(string)
+				raw, err = p.LinkAttachment(id, rel.URL, comment)
+				continue
+			}
+			target := rel.URL[strings.LastIndex(rel.URL, "/")+1:]
+			raw, err = p.AddWorkItemRelation(id, rel.Rel, target)
+		}
+		writeRaw(w, raw, err)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// serveUpdates pages the updates of a work item by $top and $skip.
+func (p *Project) serveUpdates(w http.ResponseWriter, r *http.Request, id string) {
+	updates, err := p.WorkItemUpdates(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if skip, _ := strconv.Atoi(r.URL.Query().Get("$skip")); skip > 0 {
+		updates = updates[min(skip, len(updates)):]
+	}
+	if top, _ := strconv.Atoi(r.URL.Query().Get("$top")); top > 0 && len(updates) > top {
+		updates = updates[:top]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"count": len(updates), "value": updates})
+}
+
+func (p *Project) serveComments(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+		comments, err := p.Comments(id, top)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if r.URL.Query().Get("order") == "desc" {
+			for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+				comments[i], comments[j] = comments[j], comments[i]
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"totalCount": len(comments), "count": len(comments), "comments": comments})
+	case http.MethodPost:
+		var req struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		c, err := p.AddComment(id, req.Text)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (p *Project) serveRepos(w http.ResponseWriter, r *http.Request, segs []string) {
+	switch {
+	case len(segs) == 0 && r.Method == http.MethodGet:
+		repos, _ := p.ListRepos()
+		writeJSON(w, http.StatusOK, map[string]any{"count": len(repos), "value": repos})
+	case len(segs) == 0 && r.Method == http.MethodPost:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		raw, err := p.CreateRepo(req.Name)
+		writeRaw(w, raw, err)
+	case len(segs) == 1 && r.Method == http.MethodDelete:
+		if err := p.DeleteRepo(segs[0]); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported path %s", r.URL.Path))
+	}
+}
+
+// areaTree nests area paths (root first) into classification nodes.
+func areaTree(paths []string) map[string]any {
+	nodes := map[string]map[string]any{}
+	var root map[string]any
+	for _, p := range paths {
+		i := strings.LastIndex(p, `\`)
+		n := map[string]any{"name": p[i+1:], "structureType": "area", "path": `\` + p}
+		nodes[p] = n
+		if i < 0 {
+			root = n
+			continue
+		}
+		if parent, ok := nodes[p[:i]]; ok {
+			children, _ := parent["children"].([]map[string]any)
+			parent["children"] = append(children, n)
+			parent["hasChildren"] = true
+		}
+	}
+	return root
+}
@@ -0,0 +1,635 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// query is a parsed WIQL statement. Only the subset ab generates or saved
+// queries commonly use is supported: WorkItems queries with AND/OR/NOT,
+// comparisons, IN, CONTAINS, UNDER, the @Me, @Today and @project macros
+// and ORDER BY, and WorkItemLinks queries with [Source]/[Target] prefixed
+// fields, [System.Links.LinkType] and MODE.
+type query struct {
+	fields []string
+	where  expr
+	order  []orderKey
+	links  bool   // FROM WorkItemLinks
+	mode   string // mustcontain, maycontain, doesnotcontain or recursive
+}
+
+type orderKey struct {
+	field string
+	desc  bool
+}
+
+type expr interface {
+	eval(e *env) bool
+	// refs calls ref for every field the expression reads.
+	refs(ref func(field string))
+}
+
+// env carries the item being evaluated and macro values.
+type env struct {
+	values  func(field string) []string
+	me      []string
+	today   time.Time
+	project string
+}
+
+type andExpr struct{ l, r expr }
+type orExpr struct{ l, r expr }
+type notExpr struct{ x expr }
+
+func (a andExpr) eval(e *env) bool { return a.l.eval(e) && a.r.eval(e) }
+func (o orExpr) eval(e *env) bool  { return o.l.eval(e) || o.r.eval(e) }
+func (n notExpr) eval(e *env) bool { return !n.x.eval(e) }
+
+func (a andExpr) refs(ref func(string)) { a.l.refs(ref); a.r.refs(ref) }
+func (o orExpr) refs(ref func(string))  { o.l.refs(ref); o.r.refs(ref) }
+func (n notExpr) refs(ref func(string)) { n.x.refs(ref) }
+func (c cond) refs(ref func(string))    { ref(c.field) }
+
+// conjuncts flattens the top-level ANDs of x.
+func conjuncts(x expr) []expr {
+	if a, ok := x.(andExpr); ok {
+		return append(conjuncts(a.l), conjuncts(a.r)...)
+	}
+	if x == nil {
+		return nil
+	}
+	return []expr{x}
+}
+
+// allOf joins xs with AND; nil when empty.
+func allOf(xs []expr) expr {
+	var out expr
+	for _, x := range xs {
+		if out == nil {
+			out = x
+		} else {
+			out = andExpr{out, x}
+		}
+	}
+	return out
+}
+
+// Field prefixes of WorkItemLinks queries and the link type field.
+const (
+	sourcePrefix  = "Source."
+	targetPrefix  = "Target."
+	linkTypeField = "System.Links.LinkType"
+)
+
+// linkRef reports whether field is about the link or its target rather
+// than the source of a WorkItemLinks query.
+func linkRef(field string) bool {
+	return strings.EqualFold(field, linkTypeField) || hasPrefixFold(field, targetPrefix)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// operand is a literal or macro on the right-hand side of a condition.
+type operand struct {
+	lit   string
+	macro string // "me", "project" or "today"
+	days  int    // offset for @Today
+}
+
+func (o operand) resolve(e *env) []string {
+	switch o.macro {
+	case "me":
+		return e.me
+	case "project":
+		return []string{e.project}
+	case "today":
+		return []string{e.today.AddDate(0, 0, o.days).Format(time.RFC3339)}
+	}
+	return []string{o.lit}
+}
+
+type cond struct {
+	field string
+	op    string // = <> < > <= >= in contains under
+	neg   bool
+	args  []operand
+}
+
+func (c cond) eval(e *env) bool {
+	have := e.values(c.field)
+	var ok bool
+	switch c.op {
+	case "in":
+		ok = false
+		for _, a := range c.args {
+			if anyMatch(have, a.resolve(e), equalFold) {
+				ok = true
+				break
+			}
+		}
+	case "contains":
+		ok = anyMatch(have, c.args[0].resolve(e), func(h, w string) bool {
+			return strings.Contains(strings.ToLower(h), strings.ToLower(w))
+		})
+	case "under":
+		ok = anyMatch(have, c.args[0].resolve(e), func(h, w string) bool {
+			return equalFold(h, w) || strings.HasPrefix(strings.ToLower(h), strings.ToLower(w)+`\`)
+		})
+	case "=":
+		ok = anyMatch(have, c.args[0].resolve(e), equalFold)
+	case "<>":
+		// An empty field is not equal to any non-empty value.
+		ok = !anyMatch(have, c.args[0].resolve(e), equalFold)
+	default:
+		ok = anyMatch(have, c.args[0].resolve(e), func(h, w string) bool {
+			if h == "" {
+				return false
+			}
+			n := compareValues(h, w)
+			switch c.op {
+			case "<":
+				return n < 0
+			case ">":
+				return n > 0
+			case "<=":
+				return n <= 0
+			case ">=":
+				return n >= 0
+			}
+			return false
+		})
+	}
+	if c.neg {
+		return !ok
+	}
+	return ok
+}
+
+func equalFold(a, b string) bool { return strings.EqualFold(a, b) }
+
+func anyMatch(have, want []string, match func(h, w string) bool) bool {
+	if len(have) == 0 {
+		have = []string{""}
+	}
+	for _, h := range have {
+		for _, w := range want {
+			if match(h, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareValues compares numerically, then as times, then as strings.
+func compareValues(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := parseTime(a); ok {
+		if y, ok := parseTime(b); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// token kinds
+const (
+	tkEOF = iota
+	tkField
+	tkString
+	tkNumber
+	tkIdent
+	tkMacro
+	tkOp
+	tkLParen
+	tkRParen
+	tkComma
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+func lex(s string) ([]token, error) {
+	var out []token
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '[':
+			j := i + 1
+			for j < len(r) && r[j] != ']' {
+				j++
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated field reference")
+			}
+			name := string(r[i+1 : j])
+			// [Source].[System.Id] becomes Source.System.Id.
+			if j+2 < len(r) && r[j+1] == '.' && r[j+2] == '[' {
+				k := j + 3
+				for k < len(r) && r[k] != ']' {
+					k++
+				}
+				if k >= len(r) {
+					return nil, fmt.Errorf("unterminated field reference")
+				}
+				name += "." + string(r[j+3:k])
+				j = k
+			}
+			out = append(out, token{tkField, name})
+			i = j + 1
+		case c == '\'' || c == '"':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(r) {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				if r[j] == c {
+					if j+1 < len(r) && r[j+1] == c {
+						b.WriteRune(c)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteRune(r[j])
+				j++
+			}
+			out = append(out, token{tkString, b.String()})
+			i = j + 1
+		case c == '(':
+			out = append(out, token{tkLParen, "("})
+			i++
+		case c == ')':
+			out = append(out, token{tkRParen, ")"})
+			i++
+		case c == ',':
+			out = append(out, token{tkComma, ","})
+			i++
+		case strings.ContainsRune("=<>!", c):
+			j := i + 1
+			for j < len(r) && strings.ContainsRune("=<>", r[j]) {
+				j++
+			}
+			op := string(r[i:j])
+			if op == "!=" {
+				op = "<>"
+			}
+			out = append(out, token{tkOp, op})
+			i = j
+		case c == '-' || c == '+' || unicode.IsDigit(c):
+			sign := ""
+			j := i
+			if c == '-' || c == '+' {
+				sign = string(c)
+				j++
+				for j < len(r) && unicode.IsSpace(r[j]) {
+					j++
+				}
+			}
+			k := j
+			for k < len(r) && (unicode.IsDigit(r[k]) || r[k] == '.') {
+				k++
+			}
+			if k == j {
+				return nil, fmt.Errorf("expected number after %q", sign)
+			}
+			out = append(out, token{tkNumber, sign + string(r[j:k])})
+			i = k
+		case c == '@':
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j])) {
+				j++
+			}
+			out = append(out, token{tkMacro, strings.ToLower(string(r[i+1 : j]))})
+			i = j
+		case unicode.IsLetter(c):
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '.' || r[j] == '_') {
+				j++
+			}
+			out = append(out, token{tkIdent, string(r[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in WIQL", c)
+		}
+	}
+	return append(out, token{kind: tkEOF}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tkEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tkIdent && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind int, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s, got %q", what, t.text)
+	}
+	return t, nil
+}
+
+// parseWIQL parses the supported WIQL subset.
+func parseWIQL(s string) (*query, error) {
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	if !p.keyword("SELECT") {
+		return nil, fmt.Errorf("expected SELECT")
+	}
+	q := &query{}
+	for {
+		t, err := p.expect(tkField, "field")
+		if err != nil {
+			return nil, err
+		}
+		q.fields = append(q.fields, t.text)
+		if p.peek().kind != tkComma {
+			break
+		}
+		p.next()
+	}
+	if !p.keyword("FROM") {
+		return nil, fmt.Errorf("expected FROM")
+	}
+	switch {
+	case p.keyword("WorkItems"):
+	case p.keyword("WorkItemLinks"):
+		q.links, q.mode = true, "maycontain"
+	default:
+		return nil, fmt.Errorf("only FROM WorkItems and WorkItemLinks are supported")
+	}
+	if p.keyword("WHERE") {
+		if q.where, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.parseMode(q); err != nil {
+		return nil, err
+	}
+	if p.keyword("ORDER") {
+		if !p.keyword("BY") {
+			return nil, fmt.Errorf("expected BY after ORDER")
+		}
+		for {
+			t, err := p.expect(tkField, "field")
+			if err != nil {
+				return nil, err
+			}
+			k := orderKey{field: t.text}
+			if p.keyword("DESC") {
+				k.desc = true
+			} else {
+				p.keyword("ASC")
+			}
+			q.order = append(q.order, k)
+			if p.peek().kind != tkComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if err := p.parseMode(q); err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tkEOF {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return q, nil
+}
+
+// parseMode parses MODE (...) of WorkItemLinks queries.
+func (p *parser) parseMode(q *query) error {
+	if !p.keyword("MODE") {
+		return nil
+	}
+	if !q.links {
+		return fmt.Errorf("MODE requires FROM WorkItemLinks")
+	}
+	if _, err := p.expect(tkLParen, "("); err != nil {
+		return err
+	}
+	t, err := p.expect(tkIdent, "mode")
+	if err != nil {
+		return err
+	}
+	switch m := strings.ToLower(t.text); m {
+	case "mustcontain", "maycontain", "doesnotcontain", "recursive":
+		q.mode = m
+	default:
+		return fmt.Errorf("unsupported MODE %q", t.text)
+	}
+	_, err = p.expect(tkRParen, ")")
+	return err
+}
+
+func (p *parser) parseOr() (expr, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = orExpr{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	l, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		r, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l = andExpr{l, r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseFactor() (expr, error) {
+	if p.keyword("NOT") {
+		x, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	}
+	if p.peek().kind == tkLParen {
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tkRParen, ")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	return p.parseCond()
+}
+
+func (p *parser) parseCond() (expr, error) {
+	f, err := p.expect(tkField, "field")
+	if err != nil {
+		return nil, err
+	}
+	c := cond{field: f.text}
+	if p.keyword("NOT") {
+		c.neg = true
+	}
+	switch {
+	case p.keyword("IN"):
+		c.op = "in"
+		if _, err := p.expect(tkLParen, "("); err != nil {
+			return nil, err
+		}
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, o)
+			if p.peek().kind != tkComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tkRParen, ")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	case p.keyword("CONTAINS"):
+		c.op = "contains"
+		p.keyword("WORDS")
+	case p.keyword("UNDER"):
+		c.op = "under"
+	default:
+		if c.neg {
+			return nil, fmt.Errorf("expected IN, CONTAINS or UNDER after NOT")
+		}
+		t, err := p.expect(tkOp, "operator")
+		if err != nil {
+			return nil, err
+		}
+		c.op = t.text
+	}
+	o, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	c.args = []operand{o}
+	return c, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case tkString, tkNumber:
+		return operand{lit: t.text}, nil
+	case tkMacro:
+		switch t.text {
+		case "me", "project":
+			return operand{macro: t.text}, nil
+		case "today":
+			o := operand{macro: "today"}
+			if n := p.peek(); n.kind == tkNumber {
+				p.next()
+				d, err := strconv.Atoi(n.text)
+				if err != nil {
+					return o, fmt.Errorf("bad @Today offset %q", n.text)
+				}
+				o.days = d
+			}
+			return o, nil
+		}
+		return operand{}, fmt.Errorf("unsupported macro @%s", t.text)
+	}
+	return operand{}, fmt.Errorf("expected value, got %q", t.text)
+}
+
+// sortItems orders items by the query's ORDER BY keys.
+func (q *query) sortItems(items []*item, values func(it *item, field string) []string) {
+	if len(q.order) == 0 {
+		return
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, k := range q.order {
+			a := first(values(items[i], k.field))
+			b := first(values(items[j], k.field))
+			n := compareValues(a, b)
+			if n == 0 {
+				continue
+			}
+			if k.desc {
+				return n > 0
+			}
+			return n < 0
+		}
+		return false
+	})
+}
+
+func first(v []string) string {
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0]
+}
@@ -0,0 +1,132 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Iteration is a sprint of the team, as returned by the team settings API.
+type Iteration struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Path       string              `json:"path"`
+	Attributes IterationAttributes `json:"attributes"`
+}
+
+// IterationAttributes carries the dates of an iteration. TimeFrame is past,
+// current or future as computed by Azure DevOps.
+type IterationAttributes struct {
+	StartDate  *time.Time `json:"startDate,omitempty"`
+	FinishDate *time.Time `json:"finishDate,omitempty"`
+	TimeFrame  string     `json:"timeFrame,omitempty"`
+}
+
+// CurrentIterationMacro selects the team's current iteration.
+const CurrentIterationMacro = "@current"
+
+// IsCurrent reports whether now falls within the iteration. Dates win over
+// TimeFrame, which goes stale in cached copies; the finish date is
+// inclusive.
+func (it Iteration) IsCurrent(now time.Time) bool {
+	a := it.Attributes
+	if a.StartDate != nil && a.FinishDate != nil {
+		day := now.UTC().Truncate(24 * time.Hour)
+		return !day.Before(a.StartDate.UTC().Truncate(24*time.Hour)) && !day.After(a.FinishDate.UTC().Truncate(24*time.Hour))
+	}
+	return strings.EqualFold(a.TimeFrame, "current")
+}
+
+// TeamIterations returns the iterations of the team in use (default team
+// unless SetTeam) in schedule order.
+func TeamIterations() ([]Iteration, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	defs, err := GetDevOpsDefaults()
+	if err != nil {
+		return nil, err
+	}
+	return b.Iterations(defs.Team)
+}
+
+// ResolveIteration finds the team iteration called name, which may be
+// @current, an iteration name or its full path (case-insensitive).
+func ResolveIteration(name string) (*Iteration, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("no iteration given")
+	}
+	its, err := TeamIterations()
+	if err != nil {
+		return nil, err
+	}
+	return FindIteration(its, name, time.Now())
+}
+
+// FindIteration picks name (see ResolveIteration) from its.
+func FindIteration(its []Iteration, name string, now time.Time) (*Iteration, error) {
+	if strings.EqualFold(name, CurrentIterationMacro) {
+		for i := range its {
+			if its[i].IsCurrent(now) {
+				return &its[i], nil
+			}
+		}
+		return nil, fmt.Errorf("the team has no current iteration")
+	}
+	norm := func(s string) string { return strings.ToLower(strings.Trim(s, `\`)) }
+	var byName []*Iteration
+	for i := range its {
+		if norm(its[i].Path) == norm(name) {
+			return &its[i], nil
+		}
+		if strings.EqualFold(its[i].Name, name) {
+			byName = append(byName, &its[i])
+		}
+	}
+	switch len(byName) {
+	case 0:
+		return nil, fmt.Errorf("iteration %q not found among the team's iterations (see ab sprint)", name)
+	case 1:
+		return byName[0], nil
+	}
+	paths := make([]string, 0, len(byName))
+	for _, it := range byName {
+		paths = append(paths, it.Path)
+	}
+	return nil, fmt.Errorf("iteration %q is ambiguous: %s", name, strings.Join(paths, ", "))
+}
+
+type iterationList struct {
+	Value []Iteration `json:"value"`
+}
+
+func decodeIterations(raw []byte) ([]Iteration, error) {
+	var l iterationList
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, err
+	}
+	return l.Value, nil
+}
+
+func (b cliBackend) Iterations(team string) ([]Iteration, error) {
+	base, err := b.teamURL(team)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRestGET(base + "/_apis/work/teamsettings/iterations?api-version=7.0")
+	if err != nil {
+		return nil, err
+	}
+	return decodeIterations(raw)
+}
+
+func (c *restClient) Iterations(team string) ([]Iteration, error) {
+	raw, err := c.getJSON(withVersion(c.teamURL(team) + "/_apis/work/teamsettings/iterations"))
+	if err != nil {
+		return nil, err
+	}
+	return decodeIterations(raw)
+}
@@ -0,0 +1,588 @@
+package az
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	shellescape "al.essio.dev/pkg/shellescape"
+	"github.com/sa6mwa/ab/internal/util"
+)
+
+// JournalEntry is a mutation made through ab, with what is needed to
+// revert it.
+type JournalEntry struct {
+	// Run identifies the ab command the mutation was part of; Seq numbers
+	// the mutations of a run from 1.
+	Run     string    `json:"run"`
+	Seq     int       `json:"seq"`
+	Command string    `json:"command,omitempty"`
+	Time    time.Time `json:"time"`
+	Org     string    `json:"org,omitempty"`
+	Project string    `json:"project,omitempty"`
+	// Action is update, create, relation, attach, unlink, delete, restore,
+	// comment, repo create or repo delete.
+	Action string `json:"action"`
+	Item   int    `json:"item,omitempty"`
+	Title  string `json:"title,omitempty"`
+	// Fields holds the changed fields with their values before and after.
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+	// Relation is the relation added, or removed by unlink.
+	Relation *Relation `json:"relation,omitempty"`
+	// Detail describes what else changed: the relation, the comment or
+	// the repository.
+	Detail string `json:"detail,omitempty"`
+	// Undoes is the Key of the entry this mutation reverted.
+	Undoes string `json:"undoes,omitempty"`
+	// Undone is set by Journal when a later entry reverted this one.
+	Undone bool `json:"-"`
+}
+
+// Key identifies the entry in the journal.
+func (e JournalEntry) Key() string { return e.Run + "#" + strconv.Itoa(e.Seq) }
+
+// JournalRun is the mutations of one ab command.
+type JournalRun struct {
+	ID      string
+	Command string
+	Time    time.Time
+	// Entries are the mutations in the order they were made.
+	Entries []JournalEntry
+}
+
+// IsUndo reports whether the run was an undo.
+func (r JournalRun) IsUndo() bool {
+	return len(r.Entries) > 0 && r.Entries[0].Undoes != ""
+}
+
+// Pending returns the entries that have not been undone, newest first.
+func (r JournalRun) Pending() []JournalEntry {
+	var out []JournalEntry
+	for i := len(r.Entries) - 1; i >= 0; i-- {
+		if !r.Entries[i].Undone {
+			out = append(out, r.Entries[i])
+		}
+	}
+	return out
+}
+
+// Reversible reports whether Undo can revert the entry.
+func (e JournalEntry) Reversible() bool {
+	switch e.Action {
+	case "update", "create", "delete", "restore", "relation", "attach", "unlink":
+		return true
+	}
+	return false
+}
+
+// ErrIrreversible is returned by Undo for mutations it cannot revert.
+var ErrIrreversible = errors.New("cannot be undone")
+
+// journalMaxBytes is the size at which the journal is cut to its newer half.
+const journalMaxBytes = 1 << 20
+
+// journalEnabled is false when AB_NO_JOURNAL is set.
+var journalEnabled = !util.EnvTrue("AB_NO_JOURNAL")
+
+// journal is the run being recorded.
+var journal struct {
+	mu      sync.Mutex
+	on      bool
+	run     string
+	command string
+	seq     int
+	org     string
+	project string
+	// undoes is the key of the entry Undo is reverting.
+	undoes string
+	// known holds the work items as last read or written in this run.
+	known map[int]WorkItem
+}
+
+// StartJournal records the mutations made from here on as one run of the
+// ab command line args, unless AB_NO_JOURNAL is set.
+func StartJournal(args []string) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	journal.on = journalEnabled
+	journal.run = strconv.FormatInt(time.Now().UnixNano(), 36)
+	journal.command = shellescape.QuoteCommand(append([]string{"ab"}, args...))
+	journal.seq = 0
+	journal.org, journal.project = "", ""
+	journal.known = nil
+}
+
+// NoteCurrent tells the journal the current values of work items just read,
+// so that updating them does not read them again. Items fetched with only
+// some fields must include the fields the update sends.
+func NoteCurrent(items ...WorkItem) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	if !journal.on {
+		return
+	}
+	if journal.known == nil {
+		journal.known = map[int]WorkItem{}
+	}
+	for _, wi := range items {
+		if wi.ID != 0 {
+			journal.known[wi.ID] = wi
+		}
+	}
+}
+
+// knownItem returns the noted values of work item id.
+func knownItem(id string) (WorkItem, bool) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return WorkItem{}, false
+	}
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	wi, ok := journal.known[n]
+	return wi, ok
+}
+
+// StopJournal stops recording mutations.
+func StopJournal() {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	journal.on = false
+}
+
+func journaling() bool {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	return journal.on
+}
+
+// JournalPath is $AB_JOURNAL, else $XDG_STATE_HOME/ab/journal.jsonl, else
+// ~/.local/state/ab/journal.jsonl.
+func JournalPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("AB_JOURNAL")); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "ab", "journal.jsonl"), nil
+}
+
+// writeJournal completes e with the run and appends it to the journal. A
+// failure is only reported: the mutation has been made.
+func writeJournal(e JournalEntry) {
+	journal.mu.Lock()
+	defer journal.mu.Unlock()
+	if !journal.on {
+		return
+	}
+	if journal.org == "" {
+		journal.org, journal.project, _, _ = Scope()
+	}
+	journal.seq++
+	e.Run, e.Seq, e.Command, e.Undoes = journal.run, journal.seq, journal.command, journal.undoes
+	e.Time, e.Org, e.Project = time.Now().UTC(), journal.org, journal.project
+	if err := appendJournal(e); err != nil {
+		fmt.Fprintf(os.Stderr, "ab: journal: %v\n", err)
+	}
+}
+
+func appendJournal(e JournalEntry) error {
+	path, err := JournalPath()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if st, err := os.Stat(path); err == nil && st.Size() > journalMaxBytes {
+		return trimJournal(path)
+	}
+	return nil
+}
+
+// trimJournal keeps the newer half of the journal.
+func trimJournal(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	kept := bytes.Join(lines[len(lines)/2:], nil)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Journal returns the runs recorded for the organization and project in
+// use, newest first, with the entries reverted by later runs marked Undone.
+func Journal() ([]JournalRun, error) {
+	org, project, _, err := Scope()
+	if err != nil {
+		return nil, err
+	}
+	path, err := JournalPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []JournalEntry
+	undone := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, journalMaxBytes)
+	for sc.Scan() {
+		var e JournalEntry
+		if json.Unmarshal(sc.Bytes(), &e) != nil || e.Run == "" {
+			continue
+		}
+		if e.Org != org || e.Project != project {
+			continue
+		}
+		entries = append(entries, e)
+		if e.Undoes != "" {
+			undone[e.Undoes] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var runs []JournalRun
+	index := map[string]int{}
+	for _, e := range entries {
+		e.Undone = undone[e.Key()]
+		i, ok := index[e.Run]
+		if !ok {
+			i = len(runs)
+			index[e.Run] = i
+			runs = append(runs, JournalRun{ID: e.Run, Command: e.Command, Time: e.Time})
+		}
+		runs[i].Entries = append(runs[i].Entries, e)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Time.After(runs[j].Time) })
+	return runs, nil
+}
+
+// Undo reverts a journaled mutation: field values are restored, added
+// relations removed, created items deleted and deleted items restored from
+// the recycle bin. Fields are only restored while they still hold the
+// values the mutation set, unless force is given. The reverting mutation is
+// journaled as undoing e.
+func Undo(e JournalEntry, force bool) error {
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	journal.mu.Lock()
+	journal.undoes = e.Key()
+	journal.mu.Unlock()
+	defer func() {
+		journal.mu.Lock()
+		journal.undoes = ""
+		journal.mu.Unlock()
+	}()
+	id := strconv.Itoa(e.Item)
+	switch e.Action {
+	case "update":
+		raw, err := b.ShowWorkItem(id)
+		if err != nil {
+			return err
+		}
+		var wi WorkItem
+		if err := json.Unmarshal(raw, &wi); err != nil {
+			return fmt.Errorf("parse work item %s: %w", id, err)
+		}
+		restore := map[string]string{}
+		var changed []string
+		for k, fc := range e.Fields {
+			if journalValue(wi.Fields[k]) != journalValue(fc.NewValue) {
+				changed = append(changed, k)
+			}
+			restore[k] = journalValue(fc.OldValue)
+		}
+		if len(changed) > 0 && !force {
+			sort.Strings(changed)
+			return fmt.Errorf("AB#%d has changed since (%s); use --force to restore it anyway", e.Item, strings.Join(changed, ", "))
+		}
+		_, err = b.UpdateWorkItemFieldsAt(id, wi.Rev, restore)
+		return err
+	case "create", "restore":
+		_, err := b.DeleteWorkItem(id)
+		return err
+	case "delete":
+		_, err := b.RestoreWorkItem(id)
+		return err
+	case "relation", "attach":
+		if e.Relation == nil {
+			return fmt.Errorf("%s of AB#%d: relation not recorded", e.Action, e.Item)
+		}
+		_, err := b.RemoveWorkItemRelation(id, e.Relation.Rel, e.Relation.URL)
+		return err
+	case "unlink":
+		if e.Relation == nil {
+			return fmt.Errorf("%s of AB#%d: relation not recorded", e.Action, e.Item)
+		}
+		if e.Relation.Rel == AttachedFileRel {
+			_, err := b.LinkAttachment(id, e.Relation.URL, "")
+			return err
+		}
+		_, err := b.AddWorkItemRelation(id, RelationName(e.Relation.Rel), strconv.Itoa(relationItemID(e.Relation.URL)))
+		return err
+	}
+	return fmt.Errorf("%s %w", e.Action, ErrIrreversible)
+}
+
+// journalValue is the value of a field as sent in an update: identities
+// by unique name, numbers without exponent, unset as empty.
+func journalValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]any:
+		if s, ok := v["uniqueName"].(string); ok {
+			return s
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// journalBackend journals the mutations made through Backend.
+type journalBackend struct {
+	Backend
+}
+
+// journaledFields reports whether a field is compared before and after an
+// update: the fields sent, and the state and Kanban column Azure DevOps
+// keeps in sync with each other.
+func journaledFields(sent map[string]string) func(ref string) bool {
+	return func(ref string) bool {
+		if _, ok := sent[ref]; ok {
+			return true
+		}
+		return ref == "System.State" || (strings.HasPrefix(ref, "WEF_") && strings.HasSuffix(ref, "_Kanban.Column"))
+	}
+}
+
+func (j journalBackend) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	return j.update(id, fields, func() ([]byte, error) { return j.Backend.UpdateWorkItemFields(id, fields) })
+}
+
+func (j journalBackend) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	return j.update(id, fields, func() ([]byte, error) { return j.Backend.UpdateWorkItemFieldsAt(id, rev, fields) })
+}
+
+func (j journalBackend) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	return j.update(id, map[string]string{"System.AssignedTo": assignee}, func() ([]byte, error) {
+		return j.Backend.UpdateWorkItemAssignee(id, assignee)
+	})
+}
+
+// update compares the work item before and after the mutation so that the
+// journal holds the values to restore. The values before are those noted
+// with NoteCurrent, or read when there are none.
+func (j journalBackend) update(id string, fields map[string]string, mutate func() ([]byte, error)) ([]byte, error) {
+	old, ok := knownItem(id)
+	if !ok {
+		before, err := j.Backend.ShowWorkItem(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(before, &old); err != nil {
+			fmt.Fprintf(os.Stderr, "ab: journal: work item %s: %v\n", id, err)
+			old = WorkItem{}
+		}
+	}
+	raw, err := mutate()
+	if err != nil {
+		return raw, err
+	}
+	var cur WorkItem
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		fmt.Fprintf(os.Stderr, "ab: journal: work item %s: %v\n", id, err)
+		return raw, nil
+	}
+	NoteCurrent(cur)
+	if old.ID == 0 {
+		return raw, nil
+	}
+	// Only the fields of old are compared, besides those sent, as noted
+	// items may hold only some fields.
+	journaled := journaledFields(fields)
+	changes := map[string]FieldChange{}
+	for _, k := range append(sortedKeys(fields), sortedKeys(old.Fields)...) {
+		if journaled(k) && journalValue(old.Fields[k]) != journalValue(cur.Fields[k]) {
+			changes[k] = FieldChange{OldValue: old.Fields[k], NewValue: cur.Fields[k]}
+		}
+	}
+	if len(changes) > 0 {
+		writeJournal(JournalEntry{Action: "update", Item: old.ID, Title: fieldString(cur.Fields, "System.Title"), Fields: changes})
+	}
+	return raw, nil
+}
+
+func (j journalBackend) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	raw, err := j.Backend.CreateWorkItem(wiType, title, fields)
+	if err != nil {
+		return raw, err
+	}
+	var wi WorkItem
+	if json.Unmarshal(raw, &wi) == nil && wi.ID != 0 {
+		NoteCurrent(wi)
+		changes := map[string]FieldChange{}
+		for _, k := range append([]string{"System.WorkItemType", "System.Title"}, sortedKeys(fields)...) {
+			if v, ok := wi.Fields[k]; ok {
+				changes[k] = FieldChange{NewValue: v}
+			}
+		}
+		writeJournal(JournalEntry{Action: "create", Item: wi.ID, Title: title, Fields: changes})
+	}
+	return raw, nil
+}
+
+func (j journalBackend) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	raw, err := j.Backend.AddWorkItemRelation(id, relationType, targetID)
+	if err == nil {
+		j.related(raw, "relation", RelationRefName(relationType), targetID)
+	}
+	return raw, err
+}
+
+func (j journalBackend) LinkAttachment(id, attachmentURL, comment string) ([]byte, error) {
+	raw, err := j.Backend.LinkAttachment(id, attachmentURL, comment)
+	if err == nil {
+		j.related(raw, "attach", AttachedFileRel, attachmentURL)
+	}
+	return raw, err
+}
+
+// related journals the relation rel to target that the work item raw
+// gained, as found in its relations.
+func (j journalBackend) related(raw []byte, action, rel, target string) {
+	var wi WorkItem
+	if json.Unmarshal(raw, &wi) != nil {
+		return
+	}
+	e := JournalEntry{Action: action, Item: wi.ID, Title: fieldString(wi.Fields, "System.Title"), Detail: RelationSummary(rel, target)}
+	for i := len(wi.Relations) - 1; i >= 0; i-- {
+		if r := wi.Relations[i]; strings.EqualFold(r.Rel, rel) && SameRelationTarget(r.URL, target) {
+			e.Relation = &Relation{Rel: r.Rel, URL: r.URL}
+			break
+		}
+	}
+	writeJournal(e)
+}
+
+func (j journalBackend) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	raw, err := j.Backend.RemoveWorkItemRelation(id, rel, target)
+	if err == nil {
+		var wi WorkItem
+		_ = json.Unmarshal(raw, &wi)
+		n, _ := strconv.Atoi(id)
+		writeJournal(JournalEntry{
+			Action: "unlink", Item: n, Title: fieldString(wi.Fields, "System.Title"),
+			Relation: &Relation{Rel: rel, URL: target}, Detail: RelationSummary(rel, target),
+		})
+	}
+	return raw, err
+}
+
+func (j journalBackend) DeleteWorkItem(id string) ([]byte, error) {
+	raw, err := j.Backend.DeleteWorkItem(id)
+	if err == nil {
+		j.deletion(raw, "delete", id)
+	}
+	return raw, err
+}
+
+func (j journalBackend) RestoreWorkItem(id string) ([]byte, error) {
+	raw, err := j.Backend.RestoreWorkItem(id)
+	if err == nil {
+		j.deletion(raw, "restore", id)
+	}
+	return raw, err
+}
+
+// deletion journals a WorkItemDelete response; its name is the title.
+func (j journalBackend) deletion(raw []byte, action, id string) {
+	var d struct {
+		Name string `json:"name"`
+	}
+	_ = json.Unmarshal(raw, &d)
+	n, _ := strconv.Atoi(id)
+	writeJournal(JournalEntry{Action: action, Item: n, Title: d.Name})
+}
+
+func (j journalBackend) AddComment(id, html string) (*Comment, error) {
+	c, err := j.Backend.AddComment(id, html)
+	if err == nil {
+		n, _ := strconv.Atoi(id)
+		writeJournal(JournalEntry{Action: "comment", Item: n, Detail: html})
+	}
+	return c, err
+}
+
+func (j journalBackend) CreateRepo(name string) ([]byte, error) {
+	raw, err := j.Backend.CreateRepo(name)
+	if err == nil {
+		writeJournal(JournalEntry{Action: "repo create", Detail: name})
+	}
+	return raw, err
+}
+
+func (j journalBackend) DeleteRepo(id string) error {
+	err := j.Backend.DeleteRepo(id)
+	if err == nil {
+		writeJournal(JournalEntry{Action: "repo delete", Detail: id})
+	}
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WorkItemLink is a row of a WorkItemLinks query: a top-level item, with
+// Source 0 and no Rel, or a link of type Rel from Source to Target.
+type WorkItemLink struct {
+	Source int
+	Target int
+	Rel    string
+}
+
+// QueryLinks runs a WorkItemLinks query and returns its rows in order.
+// QueryWIQL only returns the linked items, not how they link.
+func QueryLinks(wiql string) ([]WorkItemLink, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.QueryLinks(wiql)
+}
+
+// parseLinks decodes the workItemRelations of a wiql response.
+func parseLinks(raw []byte) ([]WorkItemLink, error) {
+	type ref struct {
+		ID int `json:"id"`
+	}
+	var res struct {
+		WorkItemRelations []struct {
+			Rel    string `json:"rel"`
+			Source *ref   `json:"source"`
+			Target *ref   `json:"target"`
+		} `json:"workItemRelations"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("parse wiql result: %w", err)
+	}
+	out := make([]WorkItemLink, 0, len(res.WorkItemRelations))
+	for _, r := range res.WorkItemRelations {
+		if r.Target == nil {
+			continue
+		}
+		l := WorkItemLink{Target: r.Target.ID, Rel: r.Rel}
+		if r.Source != nil {
+			l.Source = r.Source.ID
+		}
+		out = append(out, l)
+	}
+	return out, nil
+}
+
+func (cliBackend) QueryLinks(wiql string) ([]WorkItemLink, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(map[string]string{"query": wiql})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := azRest("post", withVersion(base+"/_apis/wit/wiql"), body)
+	if err != nil {
+		return nil, err
+	}
+	return parseLinks(raw)
+}
+
+func (c *restClient) QueryLinks(wiql string) ([]WorkItemLink, error) {
+	raw, err := c.sendJSON(http.MethodPost, withVersion(c.projectURL()+"/_apis/wit/wiql"), map[string]string{"query": wiql})
+	if err != nil {
+		return nil, err
+	}
+	return parseLinks(raw)
+}
@@ -0,0 +1,132 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SavedQuery is a query or folder of the project's query hierarchy
+// ("My Queries", "Shared Queries"). QueryType is flat, tree or oneHop;
+// WIQL is only set when the query was fetched with SavedQuery.
+type SavedQuery struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Path        string       `json:"path"`
+	IsFolder    bool         `json:"isFolder,omitempty"`
+	HasChildren bool         `json:"hasChildren,omitempty"`
+	IsPublic    bool         `json:"isPublic,omitempty"`
+	QueryType   string       `json:"queryType,omitempty"`
+	WIQL        string       `json:"wiql,omitempty"`
+	Children    []SavedQuery `json:"children,omitempty"`
+}
+
+// queriesDepth is the deepest level the queries API expands per request.
+const queriesDepth = 2
+
+// SavedQueries returns the query hierarchy of the project, folders first
+// level by level, with every folder expanded.
+func SavedQueries() ([]SavedQuery, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.SavedQueries()
+}
+
+// GetSavedQuery returns the query at a path ("Shared Queries/Team/Open
+// bugs") or with an id, including its WIQL.
+func GetSavedQuery(idOrPath string) (*SavedQuery, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.SavedQuery(idOrPath)
+}
+
+// queryURL returns the URL of a query by id or path; each path segment is
+// escaped.
+func queryURL(projectURL, idOrPath string) string {
+	segs := strings.Split(strings.Trim(idOrPath, "/"), "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return projectURL + "/_apis/wit/queries/" + strings.Join(segs, "/")
+}
+
+// fetchSavedQueries loads the hierarchy with get, expanding folders the
+// first request left unexpanded.
+func fetchSavedQueries(get func(u string) ([]byte, error), projectURL string) ([]SavedQuery, error) {
+	raw, err := get(fmt.Sprintf("%s/_apis/wit/queries?$depth=%d&api-version=7.0", projectURL, queriesDepth))
+	if err != nil {
+		return nil, err
+	}
+	var l struct {
+		Value []SavedQuery `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &l); err != nil {
+		return nil, fmt.Errorf("decode queries: %w", err)
+	}
+	var expand func(qs []SavedQuery) error
+	expand = func(qs []SavedQuery) error {
+		for i := range qs {
+			q := &qs[i]
+			if q.IsFolder && q.HasChildren && len(q.Children) == 0 {
+				raw, err := get(fmt.Sprintf("%s?$depth=%d&api-version=7.0", queryURL(projectURL, q.ID), queriesDepth))
+				if err != nil {
+					return err
+				}
+				var f SavedQuery
+				if err := json.Unmarshal(raw, &f); err != nil {
+					return fmt.Errorf("decode query folder %s: %w", q.Path, err)
+				}
+				q.Children = f.Children
+			}
+			if err := expand(q.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := expand(l.Value); err != nil {
+		return nil, err
+	}
+	return l.Value, nil
+}
+
+func fetchSavedQuery(get func(u string) ([]byte, error), projectURL, idOrPath string) (*SavedQuery, error) {
+	raw, err := get(queryURL(projectURL, idOrPath) + "?$expand=wiql&api-version=7.0")
+	if err != nil {
+		return nil, err
+	}
+	var q SavedQuery
+	if err := json.Unmarshal(raw, &q); err != nil {
+		return nil, fmt.Errorf("decode query: %w", err)
+	}
+	return &q, nil
+}
+
+func (cliBackend) SavedQueries() ([]SavedQuery, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	return fetchSavedQueries(azRestGET, base)
+}
+
+func (cliBackend) SavedQuery(idOrPath string) (*SavedQuery, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	return fetchSavedQuery(azRestGET, base, idOrPath)
+}
+
+func (c *restClient) SavedQueries() ([]SavedQuery, error) {
+	return fetchSavedQueries(c.getJSON, c.projectURL())
+}
+
+func (c *restClient) SavedQuery(idOrPath string) (*SavedQuery, error) {
+	return fetchSavedQuery(c.getJSON, c.projectURL(), idOrPath)
+}
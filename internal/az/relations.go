@@ -0,0 +1,159 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RemoveWorkItemRelation removes the relation of type rel (a reference
+// name such as System.LinkTypes.Hierarchy-Reverse or AttachedFile) to
+// target, a work item id or the URL of the relation.
+func RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.RemoveWorkItemRelation(id, rel, target)
+}
+
+// RestoreWorkItem brings a deleted work item back from the recycle bin.
+func RestoreWorkItem(id string) ([]byte, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.RestoreWorkItem(id)
+}
+
+// SameRelationTarget reports whether the relation URL u points at target,
+// a work item id or a URL. Work item URLs are compared by id since their
+// form differs between the APIs (workItems vs workitems, org vs project).
+func SameRelationTarget(u, target string) bool {
+	if strings.EqualFold(u, target) {
+		return true
+	}
+	id := relationItemID(u)
+	if id == 0 {
+		return false
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(target)); err == nil {
+		return n == id
+	}
+	return relationItemID(target) == id
+}
+
+// RelationName maps a link type reference name to the friendly name
+// accepted by az, e.g. parent; unknown names are returned unchanged.
+func RelationName(ref string) string {
+	for name, r := range relationTypes {
+		if strings.EqualFold(r, ref) {
+			return name
+		}
+	}
+	return ref
+}
+
+// RelationSummary describes a relation for plans and the journal:
+// "parent AB#12", or the file name of an attachment.
+func RelationSummary(rel, target string) string {
+	if rel == AttachedFileRel {
+		if u, err := url.Parse(target); err == nil && u.Query().Get("fileName") != "" {
+			return u.Query().Get("fileName")
+		}
+		return target
+	}
+	if n := relationItemID(target); n != 0 {
+		target = strconv.Itoa(n)
+	}
+	return RelationName(rel) + " " + itemRef(target)
+}
+
+// relationItemID returns the work item id a relation URL points at, or 0.
+func relationItemID(u string) int {
+	i := strings.LastIndex(strings.ToLower(u), "/_apis/wit/workitems/")
+	if i < 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(u[i+len("/_apis/wit/workitems/"):])
+	return n
+}
+
+// removeRelationOps returns the JSON Patch removing the relation rel to
+// target from the work item raw, guarded by a test of its revision so
+// that the index cannot point at another relation.
+func removeRelationOps(raw []byte, rel, target string) ([]patchOp, error) {
+	var wi WorkItem
+	if err := json.Unmarshal(raw, &wi); err != nil {
+		return nil, fmt.Errorf("parse work item: %w", err)
+	}
+	for i, r := range wi.Relations {
+		if strings.EqualFold(r.Rel, rel) && SameRelationTarget(r.URL, target) {
+			return []patchOp{
+				{Op: "test", Path: "/rev", Value: wi.Rev},
+				{Op: "remove", Path: "/relations/" + strconv.Itoa(i)},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("work item %d has no %s relation to %s", wi.ID, rel, target)
+}
+
+// workItemPatchURL is the URL work item updates are sent to.
+func workItemPatchURL(projectURL, id string) string {
+	return withVersion(projectURL + "/_apis/wit/workitems/" + url.PathEscape(id))
+}
+
+// recycleBinURL is the URL restoring a deleted work item.
+func recycleBinURL(projectURL, id string) string {
+	return withVersion(projectURL + "/_apis/wit/recyclebin/" + url.PathEscape(id))
+}
+
+// restoreBody is the request restoring a work item from the recycle bin.
+var restoreBody = []byte(`{"IsDeleted":false}`)
+
+func (cliBackend) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := cliBackend{}.ShowWorkItem(id)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := removeRelationOps(raw, rel, target)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	return runAz(restArgs("patch", workItemPatchURL(base, id), "application/json-patch+json", body)...)
+}
+
+func (cliBackend) RestoreWorkItem(id string) ([]byte, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	return azRest("patch", recycleBinURL(base, id), restoreBody)
+}
+
+func (c *restClient) RemoveWorkItemRelation(id, rel, target string) ([]byte, error) {
+	raw, err := c.ShowWorkItem(id)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := removeRelationOps(raw, rel, target)
+	if err != nil {
+		return nil, err
+	}
+	return c.patchJSON(http.MethodPatch, workItemPatchURL(c.projectURL(), id), ops)
+}
+
+func (c *restClient) RestoreWorkItem(id string) ([]byte, error) {
+	return c.do(http.MethodPatch, recycleBinURL(c.projectURL(), id), restoreBody, "application/json")
+}
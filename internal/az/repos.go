@@ -17,20 +17,20 @@ type Repo struct {
 
 // ListRepos returns repositories for the current az devops defaults.
 func ListRepos() ([]Repo, error) {
-	out, err := runAz("repos", "list", "-o", "json")
+	b, err := current()
 	if err != nil {
 		return nil, err
 	}
-	var repos []Repo
-	if err := json.Unmarshal(out, &repos); err != nil {
-		return nil, err
-	}
-	return repos, nil
+	return b.ListRepos()
 }
 
 // CreateRepo creates a repository and returns its JSON info.
 func CreateRepo(name string) (*Repo, error) {
-	out, err := runAz("repos", "create", "--name", name, "-o", "json")
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	out, err := b.CreateRepo(name)
 	if err != nil {
 		return nil, err
 	}
@@ -44,12 +44,12 @@ func CreateRepo(name string) (*Repo, error) {
 	return &r, nil
 }
 
-// DeleteRepo deletes a repository by ID. Pass --yes to az to avoid its prompt.
-func DeleteRepo(id string, assumeYes bool) error {
-	args := []string{"repos", "delete", "--id", id}
-	if assumeYes {
-		args = append(args, "--yes")
+// DeleteRepo deletes a repository by ID without further prompting; callers
+// are expected to confirm first.
+func DeleteRepo(id string) error {
+	b, err := current()
+	if err != nil {
+		return err
 	}
-	_, err := runAz(args...)
-	return err
+	return b.DeleteRepo(id)
 }
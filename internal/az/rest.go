@@ -0,0 +1,596 @@
+package az
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// adoResourceID is the Azure AD application ID of Azure DevOps, used as the
+// resource when requesting an access token from az.
+const adoResourceID = "499b84ac-1321-427f-aa17-267ca6975798"
+
+const apiVersion = "7.0"
+
+// authMode selects how the REST client authenticates: "pat", "az" or "" (auto).
+var authMode string
+
+func init() {
+	if v := strings.TrimSpace(os.Getenv("AB_AUTH")); v != "" {
+		_ = SetAuthMode(v)
+	}
+}
+
+// SetAuthMode selects REST authentication (pat|az). With pat, the token is
+// read from AZURE_DEVOPS_EXT_PAT; with az, an access token is obtained once
+// per process via `az account get-access-token`. Empty means auto: PAT when
+// AZURE_DEVOPS_EXT_PAT is set, otherwise az.
+func SetAuthMode(mode string) error {
+	m, err := ParseAuthMode(mode)
+	if err != nil {
+		return err
+	}
+	authMode = m
+	return nil
+}
+
+// ParseAuthMode normalizes a REST authentication mode to "pat", "az" or ""
+// (auto) without selecting it.
+func ParseAuthMode(mode string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(mode)); v {
+	case "", "auto":
+		return "", nil
+	case "pat", "az":
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid auth mode: %q (valid: pat|az)", mode)
+}
+
+// CurrentAuthMode returns the selected REST authentication mode: "pat",
+// "az" or "" (auto).
+func CurrentAuthMode() string { return authMode }
+
+// restClient implements Backend by talking to the Azure DevOps REST API directly.
+type restClient struct {
+	org     string // organization URL without trailing slash
+	project string
+	http    *http.Client
+
+	mu   sync.Mutex
+	auth string // cached Authorization header value
+	me   *connectionUser
+}
+
+var (
+	restOnce sync.Once
+	restCli  *restClient
+	restErr  error
+)
+
+// getREST returns the process-wide REST client, resolving org and project
+// from the az devops defaults on first use.
+func getREST() (*restClient, error) {
+	restOnce.Do(func() {
+		if restCli != nil {
+			return
+		}
+		org, proj, err := azDevOpsConfig()
+		if err != nil {
+			restErr = err
+			return
+		}
+		restCli = newRESTClient(org, proj)
+	})
+	if restCli != nil {
+		return restCli, nil
+	}
+	return nil, restErr
+}
+
+func newRESTClient(org, project string) *restClient {
+	return &restClient{
+		org:     strings.TrimRight(org, "/"),
+		project: project,
+		http:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// azDevOpsConfig returns the organization and project, preferring the
+// overrides (see SetScope) and otherwise reading the az devops defaults
+// straight from the az config file, which avoids starting az. Falls back to
+// `az devops configure -l`.
+func azDevOpsConfig() (org, project string, err error) {
+	org, project = localDevOpsConfig()
+	fill := func(o, p string) {
+		if org == "" {
+			org = o
+		}
+		if project == "" {
+			project = p
+		}
+	}
+	if org == "" || project == "" {
+		out, err := runAz("devops", "configure", "-l", "-o", "json")
+		if err != nil {
+			return "", "", err
+		}
+		var cfg struct {
+			Defaults map[string]string `json:"defaults"`
+		}
+		_ = json.Unmarshal(out, &cfg)
+		fill(cfg.Defaults["organization"], cfg.Defaults["project"])
+	}
+	if org == "" || project == "" {
+		return "", "", fmt.Errorf("az devops defaults not set; run 'az devops configure --defaults project=<name> organization=<url>'")
+	}
+	return org, project, nil
+}
+
+// localDevOpsConfig returns the organization and project known without
+// starting az: the overrides, completed from the az devops config file.
+// Either may be empty.
+func localDevOpsConfig() (org, project string) {
+	org, project = orgOverride, projectOverride
+	if org != "" && project != "" {
+		return org, project
+	}
+	dir := os.Getenv("AZURE_CONFIG_DIR")
+	if dir == "" {
+		if h, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(h, ".azure")
+		}
+	}
+	if dir == "" {
+		return org, project
+	}
+	f, err := os.Open(filepath.Join(dir, "azuredevops", "config"))
+	if err != nil {
+		return org, project
+	}
+	defer f.Close()
+	o, p := parseDevOpsINI(f)
+	if org == "" {
+		org = o
+	}
+	if project == "" {
+		project = p
+	}
+	return org, project
+}
+
+// parseDevOpsINI extracts organization and project from the [defaults]
+// section of the az devops config file.
+func parseDevOpsINI(r io.Reader) (org, project string) {
+	sc := bufio.NewScanner(r)
+	section := ""
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			continue
+		}
+		if section != "defaults" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "organization":
+			org = strings.TrimSpace(v)
+		case "project":
+			project = strings.TrimSpace(v)
+		}
+	}
+	return org, project
+}
+
+// authorization returns the Authorization header, obtaining an az access
+// token at most once per process.
+func (c *restClient) authorization(quiet bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.auth != "" {
+		return c.auth, nil
+	}
+	pat := strings.TrimSpace(os.Getenv("AZURE_DEVOPS_EXT_PAT"))
+	mode := authMode
+	if mode == "" {
+		mode = "az"
+		if pat != "" {
+			mode = "pat"
+		}
+	}
+	switch mode {
+	case "pat":
+		if pat == "" {
+			return "", fmt.Errorf("AZURE_DEVOPS_EXT_PAT is not set")
+		}
+		c.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+pat))
+	default:
+		out, err := runAzQuiet(quiet, "account", "get-access-token", "--resource", adoResourceID, "--query", "accessToken", "-o", "tsv")
+		if err != nil {
+			return "", fmt.Errorf("get access token: %w", err)
+		}
+		c.auth = "Bearer " + strings.TrimSpace(string(out))
+	}
+	return c.auth, nil
+}
+
+// projectURL returns the project-scoped API base URL.
+func (c *restClient) projectURL() string {
+	return c.org + "/" + url.PathEscape(c.project)
+}
+
+func withVersion(u string) string {
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "api-version=" + apiVersion
+}
+
+// do prints and confirms the request like runAz does for az command lines,
+// then performs it and returns the response body.
+func (c *restClient) do(method, u string, body []byte, contentType string) ([]byte, error) {
+	return c.request(method, u, body, contentType, "application/json")
+}
+
+// request is do with an explicit Accept header, used for binary downloads.
+func (c *restClient) request(method, u string, body []byte, contentType, accept string) ([]byte, error) {
+	return c.send(method, u, body, contentType, accept, false)
+}
+
+// send is request that, when quiet, does not print the request line.
+func (c *restClient) send(method, u string, body []byte, contentType, accept string, quiet bool) ([]byte, error) {
+	args := []string{"rest", "--method", method, "--url", u}
+	if DryRun() && isMutation(args) {
+		return nil, errDryRun(method + " " + u)
+	}
+	if err := announce(method+" "+u, shouldConfirm(args), quiet); err != nil {
+		return nil, err
+	}
+	auth, err := c.authorization(quiet)
+	if err != nil {
+		return nil, err
+	}
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u, rdr)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Accept", accept)
+	if body != nil {
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, u, err)
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s %s failed: %s: %s", method, u, resp.Status, apiMessage(out))
+	}
+	return out, nil
+}
+
+func (c *restClient) getJSON(u string) ([]byte, error) {
+	return c.do(http.MethodGet, u, nil, "")
+}
+
+func (c *restClient) sendJSON(method, u string, v any) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(method, u, body, "application/json")
+}
+
+func (c *restClient) patchJSON(method, u string, ops []patchOp) ([]byte, error) {
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(method, u, body, "application/json-patch+json")
+}
+
+// apiMessage extracts the "message" of an Azure DevOps error body.
+func apiMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &e) == nil && e.Message != "" {
+		return e.Message
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// patchOp is a single JSON Patch operation.
+type patchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// fieldOps converts a field map into JSON Patch add operations in key order.
+func fieldOps(fields map[string]string) []patchOp {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ops := make([]patchOp, 0, len(keys))
+	for _, k := range keys {
+		ops = append(ops, patchOp{Op: "add", Path: "/fields/" + k, Value: fields[k]})
+	}
+	return ops
+}
+
+// relationTypes maps the friendly names accepted by az to link reference names.
+var relationTypes = map[string]string{
+	"parent":       "System.LinkTypes.Hierarchy-Reverse",
+	"child":        "System.LinkTypes.Hierarchy-Forward",
+	"related":      "System.LinkTypes.Related",
+	"predecessor":  "System.LinkTypes.Dependency-Reverse",
+	"successor":    "System.LinkTypes.Dependency-Forward",
+	"duplicate":    "System.LinkTypes.Duplicate-Forward",
+	"duplicate of": "System.LinkTypes.Duplicate-Reverse",
+}
+
+// RelationRefName maps a friendly relation name such as "parent" to its
+// link type reference name; unknown names are returned unchanged.
+func RelationRefName(name string) string {
+	if ref, ok := relationTypes[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return ref
+	}
+	return name
+}
+
+func (c *restClient) workItemURL(id string) string {
+	return c.org + "/_apis/wit/workItems/" + url.PathEscape(id)
+}
+
+func (c *restClient) QueryWIQL(wiql string) ([]byte, error) { return c.queryWIQL(wiql, false) }
+
+func (c *restClient) queryWIQL(wiql string, quiet bool) ([]byte, error) {
+	post := func(u string, body []byte) ([]byte, error) {
+		return c.send(http.MethodPost, u, body, "application/json", "application/json", quiet)
+	}
+	body, err := json.Marshal(map[string]string{"query": wiql})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := post(withVersion(c.projectURL()+"/_apis/wit/wiql"), body)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Columns []struct {
+			ReferenceName string `json:"referenceName"`
+		} `json:"columns"`
+		WorkItems []struct {
+			ID int `json:"id"`
+		} `json:"workItems"`
+		WorkItemRelations []struct {
+			Target *struct {
+				ID int `json:"id"`
+			} `json:"target"`
+		} `json:"workItemRelations"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, fmt.Errorf("parse wiql result: %w", err)
+	}
+	ids := make([]int, 0, len(res.WorkItems))
+	for _, w := range res.WorkItems {
+		ids = append(ids, w.ID)
+	}
+	if len(ids) == 0 {
+		// Link queries return relations instead of a flat list.
+		seen := map[int]bool{}
+		for _, r := range res.WorkItemRelations {
+			if r.Target != nil && !seen[r.Target.ID] {
+				seen[r.Target.ID] = true
+				ids = append(ids, r.Target.ID)
+			}
+		}
+	}
+	fields := make([]string, 0, len(res.Columns))
+	for _, col := range res.Columns {
+		fields = append(fields, col.ReferenceName)
+	}
+	items, err := fetchWorkItemsBatch(post, c.projectURL(), ids, fields, ExpandNone)
+	if err != nil {
+		return nil, err
+	}
+	// Same array shape as `az boards query`.
+	return json.Marshal(items)
+}
+
+func (c *restClient) ShowWorkItem(id string) ([]byte, error) {
+	return c.getJSON(withVersion(c.projectURL() + "/_apis/wit/workitems/" + url.PathEscape(id) + "?$expand=all"))
+}
+
+func (c *restClient) UpdateWorkItemFields(id string, fields map[string]string) ([]byte, error) {
+	return c.patchJSON(http.MethodPatch, withVersion(c.projectURL()+"/_apis/wit/workitems/"+url.PathEscape(id)), fieldOps(fields))
+}
+
+func (c *restClient) CreateWorkItem(wiType, title string, fields map[string]string) ([]byte, error) {
+	all := map[string]string{"System.Title": title}
+	for k, v := range fields {
+		all[k] = v
+	}
+	return c.patchJSON(http.MethodPost, withVersion(c.projectURL()+"/_apis/wit/workitems/$"+url.PathEscape(wiType)), fieldOps(all))
+}
+
+func (c *restClient) AddWorkItemRelation(id, relationType, targetID string) ([]byte, error) {
+	op := patchOp{Op: "add", Path: "/relations/-", Value: map[string]any{
+		"rel": RelationRefName(relationType),
+		"url": c.workItemURL(targetID),
+	}}
+	return c.patchJSON(http.MethodPatch, withVersion(c.projectURL()+"/_apis/wit/workitems/"+url.PathEscape(id)), []patchOp{op})
+}
+
+func (c *restClient) UpdateWorkItemAssignee(id, assignee string) ([]byte, error) {
+	return c.UpdateWorkItemFields(id, map[string]string{"System.AssignedTo": assignee})
+}
+
+func (c *restClient) DeleteWorkItem(id string) ([]byte, error) {
+	return c.do(http.MethodDelete, withVersion(c.projectURL()+"/_apis/wit/workitems/"+url.PathEscape(id)), nil, "")
+}
+
+// connectionUser is the authenticated identity from _apis/connectionData.
+type connectionUser struct {
+	ID                  string `json:"id"`
+	ProviderDisplayName string `json:"providerDisplayName"`
+	Properties          struct {
+		Account struct {
+			Value string `json:"$value"`
+		} `json:"Account"`
+	} `json:"properties"`
+}
+
+// currentUser resolves the signed-in identity once per process.
+func (c *restClient) currentUser() (*connectionUser, error) {
+	c.mu.Lock()
+	me := c.me
+	c.mu.Unlock()
+	if me != nil {
+		return me, nil
+	}
+	raw, err := c.getJSON(c.org + "/_apis/connectionData")
+	if err != nil {
+		return nil, err
+	}
+	var cd struct {
+		AuthenticatedUser connectionUser `json:"authenticatedUser"`
+	}
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, fmt.Errorf("parse connection data: %w", err)
+	}
+	c.mu.Lock()
+	c.me = &cd.AuthenticatedUser
+	c.mu.Unlock()
+	return &cd.AuthenticatedUser, nil
+}
+
+func (c *restClient) CurrentUser() (*Identity, error) {
+	me, err := c.currentUser()
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{ID: me.ID, DisplayName: me.ProviderDisplayName, UniqueName: me.Properties.Account.Value}, nil
+}
+
+// projectInfo returns the project's id and default team name.
+func (c *restClient) projectInfo() (id, team string, err error) {
+	raw, err := c.getJSON(withVersion(c.org + "/_apis/projects/" + url.PathEscape(c.project)))
+	if err != nil {
+		return "", "", err
+	}
+	var p struct {
+		ID          string `json:"id"`
+		DefaultTeam struct {
+			Name string `json:"name"`
+		} `json:"defaultTeam"`
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return "", "", fmt.Errorf("parse project: %w", err)
+	}
+	return p.ID, p.DefaultTeam.Name, nil
+}
+
+func (c *restClient) Defaults() (*DevOpsDefaults, error) {
+	_, team, err := c.projectInfo()
+	if err != nil {
+		return nil, err
+	}
+	if team == "" {
+		return nil, fmt.Errorf("unable to resolve default team for project %q", c.project)
+	}
+	return &DevOpsDefaults{Organization: c.org, Project: c.project, Team: team}, nil
+}
+
+func (c *restClient) teamURL(team string) string {
+	return c.projectURL() + "/" + url.PathEscape(team)
+}
+
+func (c *restClient) Boards(team string) ([]Board, error) {
+	raw, err := c.getJSON(withVersion(c.teamURL(team) + "/_apis/work/boards"))
+	if err != nil {
+		return nil, err
+	}
+	var bl BoardsList
+	if err := json.Unmarshal(raw, &bl); err != nil {
+		return nil, err
+	}
+	return bl.Value, nil
+}
+
+func (c *restClient) BoardColumns(team, boardID string) ([]BoardColumn, error) {
+	raw, err := c.getJSON(withVersion(c.teamURL(team) + "/_apis/work/boards/" + url.PathEscape(boardID) + "/columns"))
+	if err != nil {
+		return nil, err
+	}
+	var cl ColumnsList
+	if err := json.Unmarshal(raw, &cl); err != nil {
+		return nil, err
+	}
+	return cl.Value, nil
+}
+
+func (c *restClient) ListRepos() ([]Repo, error) { return c.listRepos(false) }
+
+// listRepos is ListRepos that, when quiet, does not print the request line.
+func (c *restClient) listRepos(quiet bool) ([]Repo, error) {
+	raw, err := c.send(http.MethodGet, withVersion(c.projectURL()+"/_apis/git/repositories"), nil, "", "application/json", quiet)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Value []Repo `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+func (c *restClient) CreateRepo(name string) ([]byte, error) {
+	pid, _, err := c.projectInfo()
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{"name": name, "project": map[string]string{"id": pid}}
+	return c.sendJSON(http.MethodPost, withVersion(c.projectURL()+"/_apis/git/repositories"), body)
+}
+
+func (c *restClient) DeleteRepo(id string) error {
+	_, err := c.do(http.MethodDelete, withVersion(c.projectURL()+"/_apis/git/repositories/"+url.PathEscape(id)), nil, "")
+	return err
+}
@@ -0,0 +1,141 @@
+package az
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// withREST points the REST backend at srv for the duration of test.
+func withREST(t *testing.T, srv *httptest.Server, test func()) {
+	t.Helper()
+	t.Setenv("AZURE_DEVOPS_EXT_PAT", "secret")
+	defer Use(NewRESTBackend(srv.URL+"/org/", "My Project"))()
+	test()
+}
+
+func TestREST_QueryWIQL_FetchesFieldsInOrder(t *testing.T) {
+	_ = SetConfirmMode("never")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != "" || p != "secret" {
+			t.Errorf("missing PAT basic auth")
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/org/My Project/_apis/wit/wiql":
+			_, _ = io.WriteString(w, `{"columns":[{"referenceName":"System.Id"},{"referenceName":"System.Title"}],"workItems":[{"id":2},{"id":1}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/org/My Project/_apis/wit/workitemsbatch":
+			var req struct {
+				IDs    []int    `json:"ids"`
+				Fields []string `json:"fields"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if len(req.IDs) != 2 || len(req.Fields) != 2 {
+				t.Errorf("unexpected batch request: %+v", req)
+			}
+			_, _ = io.WriteString(w, `{"value":[{"id":1,"fields":{"System.Title":"one"}},{"id":2,"fields":{"System.Title":"two"}}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	withREST(t, srv, func() {
+		raw, err := QueryWIQL("SELECT [System.Id], [System.Title] FROM WorkItems")
+		if err != nil {
+			t.Fatalf("QueryWIQL: %v", err)
+		}
+		var items []WorkItem
+		if err := json.Unmarshal(raw, &items); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if len(items) != 2 || items[0].ID != 2 || items[1].ID != 1 {
+			t.Fatalf("expected ids [2 1], got %+v", items)
+		}
+	})
+}
+
+func TestREST_UpdateWorkItemFields_SendsJSONPatch(t *testing.T) {
+	_ = SetConfirmMode("never")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/org/My Project/_apis/wit/workitems/123" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json-patch+json" {
+			t.Errorf("content type = %q", ct)
+		}
+		var ops []patchOp
+		_ = json.NewDecoder(r.Body).Decode(&ops)
+		if len(ops) != 2 || ops[0].Path != "/fields/System.AssignedTo" || ops[1].Path != "/fields/System.State" || ops[1].Value != "Active" {
+			t.Errorf("unexpected ops: %+v", ops)
+		}
+		_, _ = io.WriteString(w, `{"id":123,"rev":2,"fields":{"System.State":"Active"}}`)
+	}))
+	defer srv.Close()
+	withREST(t, srv, func() {
+		if _, err := UpdateWorkItemFields("123", map[string]string{"System.State": "Active", "System.AssignedTo": ""}); err != nil {
+			t.Fatalf("UpdateWorkItemFields: %v", err)
+		}
+	})
+}
+
+func TestREST_AddWorkItemRelation_MapsParent(t *testing.T) {
+	_ = SetConfirmMode("never")
+	var got []patchOp
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = io.WriteString(w, `{"id":5}`)
+	}))
+	defer srv.Close()
+	withREST(t, srv, func() {
+		if _, err := AddWorkItemRelation("5", "parent", "42"); err != nil {
+			t.Fatalf("AddWorkItemRelation: %v", err)
+		}
+	})
+	if len(got) != 1 || got[0].Path != "/relations/-" {
+		t.Fatalf("unexpected ops: %+v", got)
+	}
+	rel, _ := got[0].Value.(map[string]any)
+	if rel["rel"] != "System.LinkTypes.Hierarchy-Reverse" || !strings.HasSuffix(rel["url"].(string), "/org/_apis/wit/workItems/42") {
+		t.Fatalf("unexpected relation: %+v", rel)
+	}
+}
+
+func TestREST_ErrorIncludesMessage(t *testing.T) {
+	_ = SetConfirmMode("never")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"message":"TF401232: Work item 9 does not exist"}`)
+	}))
+	defer srv.Close()
+	withREST(t, srv, func() {
+		_, _, err := ShowWorkItem("9")
+		if err == nil || !strings.Contains(err.Error(), "TF401232") {
+			t.Fatalf("expected API message in error, got %v", err)
+		}
+	})
+}
+
+func TestShouldConfirm_ReadOnlyPOST(t *testing.T) {
+	_ = SetConfirmMode("mutations")
+	defer SetConfirmMode("never")
+	if shouldConfirm([]string{"rest", "--method", "POST", "--url", "https://dev.azure.com/o/p/_apis/wit/wiql?api-version=7.0"}) {
+		t.Fatal("WIQL POST should not confirm in mutations mode")
+	}
+	if !shouldConfirm([]string{"rest", "--method", "POST", "--url", "https://dev.azure.com/o/p/_apis/wit/workitems/$Task"}) {
+		t.Fatal("create POST should confirm in mutations mode")
+	}
+	if shouldConfirm([]string{"rest", "--method", "POST", "--url", "https://dev.azure.com/o/p/_apis/wit/attachments?fileName=a.txt"}) {
+		t.Fatal("attachment upload should not confirm; the link does")
+	}
+}
+
+func TestParseDevOpsINI(t *testing.T) {
+	ini := "[core]\nfoo = bar\n[defaults]\norganization = https://dev.azure.com/acme/\nproject = Board Game\n"
+	org, proj := parseDevOpsINI(strings.NewReader(ini))
+	if org != "https://dev.azure.com/acme/" || proj != "Board Game" {
+		t.Fatalf("got org=%q project=%q", org, proj)
+	}
+}
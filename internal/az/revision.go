@@ -0,0 +1,60 @@
+package az
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrConflict is returned by UpdateWorkItemFieldsAt when the work item is
+// no longer at the expected revision.
+var ErrConflict = errors.New("work item changed since it was read")
+
+// UpdateWorkItemFieldsAt updates fields like UpdateWorkItemFields, but
+// only while the work item is still at revision rev: a JSON Patch test of
+// /rev precedes the changes. It fails with ErrConflict otherwise.
+func UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.UpdateWorkItemFieldsAt(id, rev, fields)
+}
+
+// revisionOps is the JSON Patch setting fields at revision rev.
+func revisionOps(rev int, fields map[string]string) []patchOp {
+	return append([]patchOp{{Op: "test", Path: "/rev", Value: rev}}, fieldOps(fields)...)
+}
+
+// conflictErr wraps the error of a failed revision test, TF26071 or HTTP
+// 412 from either backend, with ErrConflict.
+func conflictErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "TF26071") || strings.Contains(msg, "Precondition Failed") {
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	return err
+}
+
+func (cliBackend) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(revisionOps(rev, fields))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := runAz(restArgs("patch", workItemPatchURL(base, id), "application/json-patch+json", body)...)
+	return raw, conflictErr(err)
+}
+
+func (c *restClient) UpdateWorkItemFieldsAt(id string, rev int, fields map[string]string) ([]byte, error) {
+	raw, err := c.patchJSON(http.MethodPatch, workItemPatchURL(c.projectURL(), id), revisionOps(rev, fields))
+	return raw, conflictErr(err)
+}
@@ -0,0 +1,72 @@
+package az
+
+import (
+	"os"
+	"strings"
+)
+
+// Explicit organization, project and team, replacing the az devops
+// defaults and the project's default team. Initialized from
+// AB_ORGANIZATION, AB_PROJECT and AB_TEAM; see SetScope and SetTeam.
+var (
+	orgOverride     = strings.TrimSpace(os.Getenv("AB_ORGANIZATION"))
+	projectOverride = strings.TrimSpace(os.Getenv("AB_PROJECT"))
+	team            = strings.TrimSpace(os.Getenv("AB_TEAM"))
+)
+
+// SetScope overrides the az devops default organization (URL) and project.
+// Empty values keep the current setting.
+func SetScope(org, project string) {
+	if v := strings.TrimSpace(org); v != "" {
+		orgOverride = strings.TrimRight(v, "/")
+	}
+	if v := strings.TrimSpace(project); v != "" {
+		projectOverride = v
+	}
+	resetCachedBase()
+}
+
+// SetTeam selects the team whose board is used; empty means the project's
+// default team.
+func SetTeam(name string) { team = strings.TrimSpace(name) }
+
+// Scope identifies the organization, project and team in use. Unlike
+// GetDevOpsDefaults it does not resolve the project's default team (team is
+// empty then), and with the az backend it reads the az devops config file
+// instead of starting az, so it is cheap enough to key caches on.
+func Scope() (org, project, teamName string, err error) {
+	b, err := base()
+	if err != nil {
+		return "", "", "", err
+	}
+	switch c := b.(type) {
+	case *restClient:
+		org, project = c.org, c.project
+	case cliBackend:
+		org, project, err = azDevOpsConfig()
+	default:
+		var defs *DevOpsDefaults
+		if defs, err = b.Defaults(); err == nil {
+			org, project = defs.Organization, defs.Project
+		}
+	}
+	return strings.TrimRight(org, "/"), project, team, err
+}
+
+// orgArgs returns the --org argument for az when the organization is overridden.
+func orgArgs() []string {
+	if orgOverride == "" {
+		return nil
+	}
+	return []string{"--org", orgOverride}
+}
+
+// scopeArgs returns --org and --project arguments for az commands that take
+// both, when overridden.
+func scopeArgs() []string {
+	args := orgArgs()
+	if projectOverride != "" {
+		args = append(args, "--project", projectOverride)
+	}
+	return args
+}
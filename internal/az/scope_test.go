@@ -0,0 +1,45 @@
+package az
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScopeOverride_CLIArgs(t *testing.T) {
+	_ = SetConfirmMode("never")
+	defer SetExecutorForTest(nil)
+	defer func(o, p, tm string) { orgOverride, projectOverride, team = o, p, tm }(orgOverride, projectOverride, team)
+	SetScope("https://dev.azure.com/acme/", "Board Game")
+	SetTeam("Game Team")
+
+	var calls []string
+	SetExecutorForTest(func(args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[0] == "devops" {
+			return []byte(`{"defaultTeam":{"name":"Board Game Team"}}`), nil
+		}
+		return []byte(`[]`), nil
+	})
+	if _, err := QueryWIQL("SELECT [System.Id] FROM WorkItems"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(calls[0], "--org https://dev.azure.com/acme --project Board Game") {
+		t.Fatalf("query args = %q", calls[0])
+	}
+	defs, err := GetDevOpsDefaults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defs.Organization != "https://dev.azure.com/acme" || defs.Project != "Board Game" || defs.Team != "Game Team" {
+		t.Fatalf("defaults = %+v", defs)
+	}
+	for _, c := range calls {
+		if strings.HasPrefix(c, "devops configure") {
+			t.Fatalf("az devops configure should not run with an explicit scope: %v", calls)
+		}
+	}
+	org, project, tm, err := Scope()
+	if err != nil || org != "https://dev.azure.com/acme" || project != "Board Game" || tm != "Game Team" {
+		t.Fatalf("Scope = %q %q %q %v", org, project, tm, err)
+	}
+}
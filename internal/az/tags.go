@@ -0,0 +1,71 @@
+package az
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Tags returns the names of the work item tags defined in the project,
+// sorted case-insensitively.
+func Tags() ([]string, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.Tags()
+}
+
+func tagsURL(projectURL string) string { return withVersion(projectURL + "/_apis/wit/tags") }
+
+// decodeTags reads the tag list of the tags API.
+func decodeTags(raw []byte) ([]string, error) {
+	var res struct {
+		Value []struct {
+			Name string `json:"name"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(res.Value))
+	for _, t := range res.Value {
+		if t.Name != "" {
+			out = append(out, t.Name)
+		}
+	}
+	SortTags(out)
+	return out, nil
+}
+
+// SortTags sorts tag names case-insensitively, as Azure DevOps shows them.
+func SortTags(tags []string) {
+	sort.Slice(tags, func(i, j int) bool { return strings.ToLower(tags[i]) < strings.ToLower(tags[j]) })
+}
+
+func (c cliBackend) Tags() ([]string, error) { return c.tags(false) }
+
+// tags is Tags that, when quiet, does not print the command line.
+func (cliBackend) tags(quiet bool) ([]string, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := runAzQuiet(quiet, restArgs("get", tagsURL(base), "application/json", nil)...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTags(raw)
+}
+
+func (c *restClient) Tags() ([]string, error) { return c.tags(false) }
+
+// tags is Tags that, when quiet, does not print the request line.
+func (c *restClient) tags(quiet bool) ([]string, error) {
+	raw, err := c.send(http.MethodGet, tagsURL(c.projectURL()), nil, "", "application/json", quiet)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTags(raw)
+}
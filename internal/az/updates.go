@@ -0,0 +1,90 @@
+package az
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// updatesPageSize is the largest page the work item updates API returns.
+const updatesPageSize = 200
+
+// WorkItemUpdate is one revision of a work item as the set of fields it
+// changed. The first update of an item carries its initial values.
+type WorkItemUpdate struct {
+	ID          int                    `json:"id"`
+	WorkItemID  int                    `json:"workItemId"`
+	Rev         int                    `json:"rev"`
+	RevisedBy   Identity               `json:"revisedBy"`
+	RevisedDate time.Time              `json:"revisedDate"`
+	Fields      map[string]FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is the old and new value of a field in an update; either is
+// nil when the field was unset.
+type FieldChange struct {
+	OldValue any `json:"oldValue,omitempty"`
+	NewValue any `json:"newValue,omitempty"`
+}
+
+// ChangedDate returns when the update was made. Azure DevOps sets
+// RevisedDate to when the revision was superseded (9999-01-01 for the
+// latest), so System.ChangedDate wins when the update carries it.
+func (u WorkItemUpdate) ChangedDate() time.Time {
+	if fc, ok := u.Fields["System.ChangedDate"]; ok {
+		if s, ok := fc.NewValue.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return t
+			}
+		}
+	}
+	return u.RevisedDate
+}
+
+// WorkItemUpdates returns every update of a work item, oldest first.
+func WorkItemUpdates(id string) ([]WorkItemUpdate, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	return b.WorkItemUpdates(id)
+}
+
+type updateList struct {
+	Count int              `json:"count"`
+	Value []WorkItemUpdate `json:"value"`
+}
+
+// fetchUpdates pages through the updates API with get.
+func fetchUpdates(get func(u string) ([]byte, error), projectURL, id string) ([]WorkItemUpdate, error) {
+	base := projectURL + "/_apis/wit/workItems/" + url.PathEscape(id) + "/updates?api-version=7.0&$top=" + strconv.Itoa(updatesPageSize)
+	var out []WorkItemUpdate
+	for {
+		raw, err := get(base + "&$skip=" + strconv.Itoa(len(out)))
+		if err != nil {
+			return nil, err
+		}
+		var page updateList
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return nil, fmt.Errorf("decode updates: %w", err)
+		}
+		out = append(out, page.Value...)
+		if len(page.Value) < updatesPageSize {
+			return out, nil
+		}
+	}
+}
+
+func (cliBackend) WorkItemUpdates(id string) ([]WorkItemUpdate, error) {
+	base, err := cliProjectURL()
+	if err != nil {
+		return nil, err
+	}
+	return fetchUpdates(azRestGET, base, id)
+}
+
+func (c *restClient) WorkItemUpdates(id string) ([]WorkItemUpdate, error) {
+	return fetchUpdates(c.getJSON, c.projectURL(), id)
+}
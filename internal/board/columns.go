@@ -0,0 +1,82 @@
+package board
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+var (
+	// overridden is set when the order was chosen explicitly (AB_COLUMNS or
+	// -d); the live board is not consulted then.
+	overridden bool
+
+	liveMu sync.Mutex
+	live   = map[string][]string{}
+
+	// fetchColumns loads the columns of the board carrying wiType.
+	fetchColumns = func(wiType string) ([]string, error) {
+		cols, err := az.BoardColumnsForType(wiType)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(cols))
+		for _, c := range cols {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	}
+)
+
+// builtinOrder is the default ColumnOrder, restored by ResetColumnOrder.
+var builtinOrder = append([]string(nil), ColumnOrder...)
+
+// Overridden reports whether the column order was set explicitly.
+func Overridden() bool { return overridden }
+
+// ResetColumnOrder drops any explicit override and forgets board columns
+// loaded by this process.
+func ResetColumnOrder() {
+	ColumnOrder = append([]string(nil), builtinOrder...)
+	overridden = false
+	liveMu.Lock()
+	live = map[string][]string{}
+	liveMu.Unlock()
+}
+
+// ColumnsFor returns the Kanban columns for a work item type: the explicit
+// override when set, otherwise the columns of the team board carrying the
+// type (cached on disk by the az package for az.BoardTTL). Falls back to
+// ColumnOrder, with a warning on stderr, when the board cannot be read.
+func ColumnsFor(wiType string) []string {
+	if overridden || wiType == "" {
+		return ColumnOrder
+	}
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	if cols, ok := live[wiType]; ok {
+		return cols
+	}
+	cols, err := fetchColumns(wiType)
+	if err != nil || len(cols) == 0 {
+		if err == nil {
+			err = fmt.Errorf("board has no columns")
+		}
+		fmt.Fprintf(os.Stderr, "warning: using built-in column order for %s: %v\n", wiType, err)
+		cols = ColumnOrder
+	}
+	live[wiType] = cols
+	return cols
+}
+
+// NextColumnFor returns the column after cur on the board of wiType.
+func NextColumnFor(wiType, cur string) (string, error) {
+	return step(ColumnsFor(wiType), cur, 1)
+}
+
+// PrevColumnFor returns the column before cur on the board of wiType.
+func PrevColumnFor(wiType, cur string) (string, error) {
+	return step(ColumnsFor(wiType), cur, -1)
+}
@@ -0,0 +1,62 @@
+package board
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/az/fake"
+)
+
+func TestColumnsFor_LoadsBoardThroughCache(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	ResetColumnOrder()
+	defer ResetColumnOrder()
+	p := fake.New()
+	p.Columns = []az.BoardColumn{
+		{ID: "a", Name: "Todo", StateMapping: map[string]string{"User Story": "New"}},
+		{ID: "b", Name: "Done", StateMapping: map[string]string{"User Story": "Closed"}},
+	}
+	defer az.Use(az.NewCachedBackend(p, filepath.Join(cache, "ab", "test")))()
+
+	if got := strings.Join(ColumnsFor("User Story"), ","); got != "Todo,Done" {
+		t.Fatalf("ColumnsFor = %q", got)
+	}
+
+	// A new process reads the cached board instead of the live one.
+	ResetColumnOrder()
+	p.Columns[0].Name = "Backlog"
+	if n, err := NextColumnFor("User Story", "Todo"); err != nil || n != "Done" {
+		t.Fatalf("NextColumnFor = %q, %v", n, err)
+	}
+
+	// Expired entries are fetched again.
+	ResetColumnOrder()
+	defer func(ttl time.Duration) { az.BoardTTL = ttl }(az.BoardTTL)
+	az.BoardTTL = 0
+	if p, err := PrevColumnFor("User Story", "Done"); err != nil || p != "Backlog" {
+		t.Fatalf("PrevColumnFor = %q, %v", p, err)
+	}
+}
+
+func TestColumnsFor_OverrideAndFallback(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer ResetColumnOrder()
+	orig := fetchColumns
+	defer func() { fetchColumns = orig }()
+	fetchColumns = func(string) ([]string, error) { return nil, os.ErrNotExist }
+	defer az.Use(fake.New())()
+
+	ResetColumnOrder()
+	if got := ColumnsFor("User Story"); len(got) != len(builtinOrder) {
+		t.Fatalf("fallback = %v", got)
+	}
+	_ = SetColumnOrderFromCSV("A,B")
+	if got := strings.Join(ColumnsFor("User Story"), ","); got != "A,B" {
+		t.Fatalf("override = %q", got)
+	}
+}
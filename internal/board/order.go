@@ -6,7 +6,8 @@ import (
 	"strings"
 )
 
-// ColumnOrder defines the canonical Kanban column progression used by this CLI.
+// ColumnOrder is the Kanban column progression used when the team board
+// cannot be read, or the explicit order set via AB_COLUMNS or -d.
 var ColumnOrder = []string{
 	"Backlog",
 	"Ready for Development",
@@ -27,6 +28,7 @@ func init() {
 // SetDefaultAgileColumns sets the column order to the default Agile process columns.
 func SetDefaultAgileColumns() {
 	ColumnOrder = []string{"New", "Active", "Resolved", "Closed"}
+	overridden = true
 }
 
 // SetColumnOrderFromCSV parses a comma-separated string and sets ColumnOrder accordingly.
@@ -44,28 +46,26 @@ func SetColumnOrderFromCSV(csv string) error {
 		return fmt.Errorf("AB_COLUMNS contained no valid column names")
 	}
 	ColumnOrder = cols
+	overridden = true
 	return nil
 }
 
 // NextColumn returns the next column after cur in ColumnOrder.
-func NextColumn(cur string) (string, error) {
-	for i, c := range ColumnOrder {
-		if c == cur {
-			if i+1 < len(ColumnOrder) {
-				return ColumnOrder[i+1], nil
-			}
-			return "", fmt.Errorf("already in last column %q", cur)
-		}
-	}
-	return "", fmt.Errorf("unknown current column %q", cur)
-}
+func NextColumn(cur string) (string, error) { return step(ColumnOrder, cur, 1) }
 
 // PrevColumn returns the previous column before cur in ColumnOrder.
-func PrevColumn(cur string) (string, error) {
-	for i, c := range ColumnOrder {
+func PrevColumn(cur string) (string, error) { return step(ColumnOrder, cur, -1) }
+
+// step moves delta columns from cur within cols.
+func step(cols []string, cur string, delta int) (string, error) {
+	for i, c := range cols {
 		if c == cur {
-			if i-1 >= 0 {
-				return ColumnOrder[i-1], nil
+			j := i + delta
+			if j >= 0 && j < len(cols) {
+				return cols[j], nil
+			}
+			if delta > 0 {
+				return "", fmt.Errorf("already in last column %q", cur)
 			}
 			return "", fmt.Errorf("already in first column %q", cur)
 		}
@@ -0,0 +1,251 @@
+// Package config reads and writes ab's configuration file with named
+// profiles.
+//
+// The file lives at $AB_CONFIG, else $XDG_CONFIG_HOME/ab/config.yaml, else
+// ~/.config/ab/config.yaml:
+//
+//	current: work
+//	profiles:
+//	  work:
+//	    organization: https://dev.azure.com/acme
+//	    project: Board Game
+//	    team: Game Team
+//	    columns: [Backlog, Doing, Done]
+//	    confirm: mutations
+//	    po_order: true
+//	    assignee: "@me"
+//	    format: json
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is used when no profile is selected.
+const DefaultProfile = "default"
+
+// Profile holds the settings of one named profile. Empty fields are unset
+// and leave the corresponding env var or az default in effect.
+type Profile struct {
+	Organization string   `yaml:"organization,omitempty"`
+	Project      string   `yaml:"project,omitempty"`
+	Team         string   `yaml:"team,omitempty"`
+	Columns      []string `yaml:"columns,omitempty"`
+	Confirm      string   `yaml:"confirm,omitempty"`
+	POOrder      *bool    `yaml:"po_order,omitempty"`
+	Assignee     string   `yaml:"assignee,omitempty"`
+	Format       string   `yaml:"format,omitempty"`
+	Backend      string   `yaml:"backend,omitempty"`
+	Auth         string   `yaml:"auth,omitempty"`
+}
+
+// Config is the configuration file.
+type Config struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles,omitempty"`
+
+	path string
+}
+
+// Keys lists the profile settings accepted by Get and Set.
+var Keys = []string{"organization", "project", "team", "columns", "confirm", "po_order", "assignee", "format", "backend", "auth"}
+
+// Path returns the configuration file path.
+func Path() (string, error) {
+	if p := strings.TrimSpace(os.Getenv("AB_CONFIG")); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "ab", "config.yaml"), nil
+}
+
+// Load reads the configuration file. A missing file yields an empty config.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{path: path}
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// File returns the path the config was loaded from.
+func (c *Config) File() string { return c.path }
+
+// Save writes the configuration file, creating its directory.
+func (c *Config) Save() error {
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b.Bytes(), 0o644)
+}
+
+// Active returns the name of the profile to use: name if given, else
+// $AB_PROFILE, else the current profile, else DefaultProfile.
+func (c *Config) Active(name string) string {
+	for _, n := range []string{name, os.Getenv("AB_PROFILE"), c.Current} {
+		if n = strings.TrimSpace(n); n != "" {
+			return n
+		}
+	}
+	return DefaultProfile
+}
+
+// Profile returns the named profile, or nil when it does not exist.
+func (c *Config) Profile(name string) *Profile { return c.Profiles[name] }
+
+// Names returns the profile names in sorted order.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Use makes name the current profile, creating it if needed.
+func (c *Config) Use(name string) {
+	c.ensure(name)
+	c.Current = name
+}
+
+func (c *Config) ensure(name string) *Profile {
+	if c.Profiles == nil {
+		c.Profiles = map[string]*Profile{}
+	}
+	p := c.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+		c.Profiles[name] = p
+	}
+	return p
+}
+
+// Get returns the value of key in the named profile as a string; unset
+// values are empty.
+func (c *Config) Get(profile, key string) (string, error) {
+	p := c.Profiles[profile]
+	if p == nil {
+		p = &Profile{}
+	}
+	return p.Get(key)
+}
+
+// Set stores value under key in the named profile, creating the profile.
+// An empty value unsets the key.
+func (c *Config) Set(profile, key, value string) error {
+	p := c.ensure(profile)
+	return p.Set(key, value)
+}
+
+// Get returns the value of key as a string.
+func (p *Profile) Get(key string) (string, error) {
+	switch strings.ToLower(key) {
+	case "organization", "org":
+		return p.Organization, nil
+	case "project":
+		return p.Project, nil
+	case "team":
+		return p.Team, nil
+	case "columns":
+		return strings.Join(p.Columns, ","), nil
+	case "confirm":
+		return p.Confirm, nil
+	case "po_order", "po-order":
+		if p.POOrder == nil {
+			return "", nil
+		}
+		return strconv.FormatBool(*p.POOrder), nil
+	case "assignee":
+		return p.Assignee, nil
+	case "format":
+		return p.Format, nil
+	case "backend":
+		return p.Backend, nil
+	case "auth":
+		return p.Auth, nil
+	}
+	return "", unknownKey(key)
+}
+
+// Set parses and stores value under key; an empty value unsets the key.
+func (p *Profile) Set(key, value string) error {
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(key) {
+	case "organization", "org":
+		p.Organization = strings.TrimRight(value, "/")
+	case "project":
+		p.Project = value
+	case "team":
+		p.Team = value
+	case "columns":
+		p.Columns = nil
+		for _, c := range strings.Split(value, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				p.Columns = append(p.Columns, c)
+			}
+		}
+	case "confirm":
+		p.Confirm = value
+	case "po_order", "po-order":
+		if value == "" {
+			p.POOrder = nil
+			return nil
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("po_order: %q is not a boolean", value)
+		}
+		p.POOrder = &b
+	case "assignee":
+		p.Assignee = value
+	case "format":
+		p.Format = value
+	case "backend":
+		p.Backend = value
+	case "auth":
+		p.Auth = value
+	default:
+		return unknownKey(key)
+	}
+	return nil
+}
+
+func unknownKey(key string) error {
+	return fmt.Errorf("unknown config key %q (valid: %s)", key, strings.Join(Keys, ", "))
+}
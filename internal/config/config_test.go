@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPath_XDG(t *testing.T) {
+	t.Setenv("AB_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if p, err := Path(); err != nil || p != filepath.Join("/xdg", "ab", "config.yaml") {
+		t.Fatalf("Path = %q, %v", p, err)
+	}
+	t.Setenv("AB_CONFIG", "/etc/ab.yaml")
+	if p, _ := Path(); p != "/etc/ab.yaml" {
+		t.Fatalf("AB_CONFIG not honored: %q", p)
+	}
+}
+
+func TestSetSaveLoad(t *testing.T) {
+	t.Setenv("AB_CONFIG", filepath.Join(t.TempDir(), "sub", "config.yaml"))
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Use("work")
+	for k, v := range map[string]string{
+		"organization": "https://dev.azure.com/acme/",
+		"columns":      "Todo, Doing ,Done",
+		"po_order":     "true",
+		"assignee":     "@me",
+	} {
+		if err := cfg.Set("work", k, v); err != nil {
+			t.Fatalf("Set %s: %v", k, err)
+		}
+	}
+	if err := cfg.Set("work", "po_order", "maybe"); err == nil {
+		t.Fatal("expected error for non-boolean po_order")
+	}
+	if err := cfg.Set("work", "colour", "blue"); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := got.Profile("work")
+	if got.Current != "work" || p == nil || p.Organization != "https://dev.azure.com/acme" || len(p.Columns) != 3 || p.POOrder == nil || !*p.POOrder {
+		t.Fatalf("round trip mismatch: %+v %+v", got, p)
+	}
+	if v, _ := got.Get("work", "columns"); v != "Todo,Doing,Done" {
+		t.Fatalf("Get columns = %q", v)
+	}
+	if err := got.Set("work", "po_order", ""); err != nil || got.Profile("work").POOrder != nil {
+		t.Fatalf("unset po_order: %v", err)
+	}
+	raw, _ := os.ReadFile(got.File())
+	if !strings.Contains(string(raw), "current: work") {
+		t.Fatalf("unexpected file:\n%s", raw)
+	}
+}
+
+func TestActive_Precedence(t *testing.T) {
+	cfg := &Config{Current: "work"}
+	t.Setenv("AB_PROFILE", "")
+	if n := cfg.Active(""); n != "work" {
+		t.Fatalf("current profile = %q", n)
+	}
+	t.Setenv("AB_PROFILE", "env")
+	if n := cfg.Active(""); n != "env" {
+		t.Fatalf("env profile = %q", n)
+	}
+	if n := cfg.Active("flag"); n != "flag" {
+		t.Fatalf("flag profile = %q", n)
+	}
+	if n := (&Config{}).Active(""); n != "env" {
+		t.Fatalf("got %q", n)
+	}
+	t.Setenv("AB_PROFILE", "")
+	if n := (&Config{}).Active(""); n != DefaultProfile {
+		t.Fatalf("default profile = %q", n)
+	}
+}
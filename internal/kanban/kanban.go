@@ -0,0 +1,480 @@
+// Package kanban implements the full-screen board of `ab board`: the team
+// board's columns side by side with a card per work item, keys to move
+// cards between columns, open a card, filter to your own cards and reload.
+//
+// The Model only talks to Azure DevOps through a Source, so it can be
+// driven with plain messages in tests.
+package kanban
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Card is a work item on the board.
+type Card struct {
+	ID       int
+	Type     string
+	Assignee string
+	Title    string
+	Column   string
+	Mine     bool // assigned to the signed-in user
+}
+
+// Source loads and changes the work items shown on the board.
+type Source interface {
+	// Cards returns the work items on the board.
+	Cards() ([]Card, error)
+	// Move moves a card delta columns (1 forward, -1 back) and returns it
+	// as updated.
+	Move(id, delta int) (Card, error)
+	// Show returns the rendered `ab show` document of a card.
+	Show(id, width int) (string, error)
+}
+
+// Options configure a Model.
+type Options struct {
+	Columns []string // board columns, left to right
+	Mine    bool     // start with only the user's own cards
+	Confirm bool     // ask before moving a card
+}
+
+type mode int
+
+const (
+	modeBoard mode = iota
+	modeConfirm
+	modeDetail
+)
+
+// Messages returned by the commands that call the Source.
+type (
+	loadedMsg struct {
+		cards []Card
+		err   error
+	}
+	movedMsg struct {
+		card     Card
+		from     string
+		err      error
+		selectID int
+	}
+	shownMsg struct {
+		id  int
+		doc string
+		err error
+	}
+)
+
+// Model is the bubbletea model of the board.
+type Model struct {
+	src     Source
+	columns []string
+	cards   []Card
+	mine    bool
+	confirm bool
+
+	col, row int
+	width    int
+	height   int
+
+	mode    mode
+	pending struct{ id, delta int }
+	detail  viewport.Model
+	loading bool
+	status  string
+}
+
+// New returns a board over src. The cards are loaded by Init.
+func New(src Source, opts Options) Model {
+	return Model{
+		src:     src,
+		columns: append([]string(nil), opts.Columns...),
+		mine:    opts.Mine,
+		confirm: opts.Confirm,
+		width:   80,
+		height:  24,
+		loading: true,
+	}
+}
+
+// Run shows the board full-screen until the user quits.
+func Run(src Source, opts Options) error {
+	_, err := tea.NewProgram(New(src, opts), tea.WithAltScreen()).Run()
+	return err
+}
+
+func (m Model) load() tea.Msg {
+	cards, err := m.src.Cards()
+	return loadedMsg{cards: cards, err: err}
+}
+
+// Init loads the cards.
+func (m Model) Init() tea.Cmd { return m.load }
+
+// Update handles keys, window sizes and Source results.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.detail.Width, m.detail.Height = msg.Width, max(1, msg.Height-2)
+		return m, nil
+	case loadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		sel, hasSel := m.selected()
+		m.setCards(msg.cards)
+		if hasSel {
+			m.selectCard(sel.ID)
+		}
+		m.status = fmt.Sprintf("Loaded %d cards", len(m.cards))
+		return m, nil
+	case movedMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		for i := range m.cards {
+			if m.cards[i].ID == msg.card.ID {
+				msg.card.Mine = m.cards[i].Mine
+				m.cards[i] = msg.card
+			}
+		}
+		m.addColumn(msg.card.Column)
+		m.selectCard(msg.selectID)
+		m.status = fmt.Sprintf("Moved #%d from %s to %s", msg.card.ID, msg.from, msg.card.Column)
+		return m, nil
+	case shownMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+			return m, nil
+		}
+		m.detail = viewport.New(m.width, max(1, m.height-2))
+		m.detail.SetContent(msg.doc)
+		m.mode = modeDetail
+		m.status = fmt.Sprintf("#%d", msg.id)
+		return m, nil
+	case tea.KeyMsg:
+		switch m.mode {
+		case modeDetail:
+			return m.updateDetail(msg)
+		case modeConfirm:
+			return m.updateConfirm(msg)
+		}
+		return m.updateBoard(msg)
+	}
+	return m, nil
+}
+
+func (m Model) updateBoard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c", "esc":
+		return m, tea.Quit
+	case "left", "h":
+		m.selectColumn(m.col - 1)
+	case "right", "l":
+		m.selectColumn(m.col + 1)
+	case "up", "k":
+		m.selectRow(m.row - 1)
+	case "down", "j":
+		m.selectRow(m.row + 1)
+	case "home", "g":
+		m.selectRow(0)
+	case "end", "G":
+		m.selectRow(len(m.visible(m.col)) - 1)
+	case "shift+right", "L", ">":
+		return m.move(1)
+	case "shift+left", "H", "<":
+		return m.move(-1)
+	case "enter", " ":
+		if c, ok := m.selected(); ok && !m.loading {
+			m.loading = true
+			m.status = fmt.Sprintf("Opening #%d ...", c.ID)
+			src, id, width := m.src, c.ID, m.width
+			return m, func() tea.Msg {
+				doc, err := src.Show(id, width)
+				return shownMsg{id: id, doc: doc, err: err}
+			}
+		}
+	case "m":
+		sel, hasSel := m.selected()
+		m.mine = !m.mine
+		m.clampSelection()
+		if hasSel {
+			m.selectCard(sel.ID)
+		}
+	case "r", "ctrl+r":
+		if !m.loading {
+			m.loading = true
+			m.status = "Refreshing ..."
+			return m, m.load
+		}
+	}
+	return m, nil
+}
+
+// move starts moving the selected card, asking first when confirming.
+func (m Model) move(delta int) (tea.Model, tea.Cmd) {
+	c, ok := m.selected()
+	if !ok || m.loading {
+		return m, nil
+	}
+	target := m.col + delta
+	if target < 0 || target >= len(m.columns) {
+		m.status = fmt.Sprintf("#%d is already in %s", c.ID, m.columns[m.col])
+		return m, nil
+	}
+	m.pending.id, m.pending.delta = c.ID, delta
+	if m.confirm {
+		m.mode = modeConfirm
+		m.status = fmt.Sprintf("Move #%d from %s to %s? [y/N]", c.ID, m.columns[m.col], m.columns[target])
+		return m, nil
+	}
+	return m.startMove()
+}
+
+func (m Model) startMove() (tea.Model, tea.Cmd) {
+	m.mode = modeBoard
+	m.loading = true
+	id, delta, src := m.pending.id, m.pending.delta, m.src
+	from := ""
+	for _, c := range m.cards {
+		if c.ID == id {
+			from = c.Column
+		}
+	}
+	m.status = fmt.Sprintf("Moving #%d ...", id)
+	return m, func() tea.Msg {
+		card, err := src.Move(id, delta)
+		return movedMsg{card: card, from: from, err: err, selectID: id}
+	}
+}
+
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if s := msg.String(); s == "y" || s == "Y" {
+		return m.startMove()
+	}
+	m.mode = modeBoard
+	m.status = "Cancelled"
+	return m, nil
+}
+
+func (m Model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc", "enter", "backspace":
+		m.mode = modeBoard
+		m.status = ""
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.detail, cmd = m.detail.Update(msg)
+	return m, cmd
+}
+
+// setCards replaces the cards, adding columns not on the board (e.g. when
+// the built-in column order is used) at the end so no card is hidden.
+func (m *Model) setCards(cards []Card) {
+	m.cards = cards
+	for _, c := range cards {
+		m.addColumn(c.Column)
+	}
+	m.clampSelection()
+}
+
+func (m *Model) addColumn(name string) {
+	if name == "" {
+		return
+	}
+	for _, c := range m.columns {
+		if strings.EqualFold(c, name) {
+			return
+		}
+	}
+	m.columns = append(m.columns, name)
+}
+
+// visible returns the cards shown in column i.
+func (m Model) visible(i int) []Card {
+	if i < 0 || i >= len(m.columns) {
+		return nil
+	}
+	var out []Card
+	for _, c := range m.cards {
+		if strings.EqualFold(c.Column, m.columns[i]) && (!m.mine || c.Mine) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (m Model) selected() (Card, bool) {
+	cards := m.visible(m.col)
+	if m.row < 0 || m.row >= len(cards) {
+		return Card{}, false
+	}
+	return cards[m.row], true
+}
+
+func (m *Model) selectColumn(i int) {
+	if i < 0 || i >= len(m.columns) {
+		return
+	}
+	m.col = i
+	m.clampSelection()
+}
+
+func (m *Model) selectRow(i int) {
+	n := len(m.visible(m.col))
+	if n == 0 {
+		m.row = 0
+		return
+	}
+	m.row = min(max(i, 0), n-1)
+}
+
+// selectCard selects the card with id if it is visible.
+func (m *Model) selectCard(id int) {
+	for i := range m.columns {
+		for j, c := range m.visible(i) {
+			if c.ID == id {
+				m.col, m.row = i, j
+				return
+			}
+		}
+	}
+	m.clampSelection()
+}
+
+func (m *Model) clampSelection() {
+	m.col = min(max(m.col, 0), max(len(m.columns)-1, 0))
+	m.selectRow(m.row)
+}
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	headerStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	activeHeader  = headerStyle.Foreground(lipgloss.Color("12"))
+	cardStyle     = lipgloss.NewStyle().PaddingLeft(1)
+	selectedStyle = cardStyle.Reverse(true)
+	mineStyle     = lipgloss.NewStyle().Bold(true)
+	dimStyle      = lipgloss.NewStyle().Faint(true)
+)
+
+const (
+	minColumnWidth = 22
+	cardHeight     = 3 // two lines and a blank line
+)
+
+// View renders the board, or the open card.
+func (m Model) View() string {
+	if m.mode == modeDetail {
+		return m.detail.View() + "\n" + dimStyle.Render("↑/↓ scroll • q/esc back • "+m.status)
+	}
+	var b strings.Builder
+	heading := "ab board"
+	if m.mine {
+		heading += " (mine)"
+	}
+	b.WriteString(titleStyle.Render(heading))
+	if first, n := m.columnWindow(); n < len(m.columns) {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  columns %d-%d of %d", first+1, first+n, len(m.columns))))
+	}
+	b.WriteString("\n\n")
+
+	if len(m.columns) == 0 {
+		b.WriteString("No board columns.\n")
+	} else {
+		first, n := m.columnWindow()
+		width := max(minColumnWidth, m.width/n)
+		rows := max(1, (m.height-6)/cardHeight)
+		cols := make([]string, 0, n)
+		for i := first; i < first+n; i++ {
+			cols = append(cols, m.renderColumn(i, width, rows))
+		}
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cols...))
+		b.WriteString("\n")
+	}
+	help := "←/→ column • ↑/↓ card • H/L move • enter open • m mine • r refresh • q quit"
+	b.WriteString(dimStyle.Render(help) + "\n")
+	b.WriteString(m.status)
+	return b.String()
+}
+
+// columnWindow returns the first and number of columns that fit the width,
+// keeping the selected column in view.
+func (m Model) columnWindow() (first, n int) {
+	n = min(len(m.columns), max(1, m.width/minColumnWidth))
+	first = 0
+	if m.col >= n {
+		first = m.col - n + 1
+	}
+	return first, n
+}
+
+func (m Model) renderColumn(i, width, rows int) string {
+	cards := m.visible(i)
+	hs := headerStyle
+	if i == m.col {
+		hs = activeHeader
+	}
+	lines := []string{hs.Render(truncate(fmt.Sprintf("%s (%d)", m.columns[i], len(cards)), width-1)), ""}
+	start := 0
+	if i == m.col && m.row >= rows {
+		start = m.row - rows + 1
+	}
+	if start > 0 {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf(" ↑ %d more", start)))
+	}
+	for j := start; j < len(cards) && j < start+rows; j++ {
+		c := cards[j]
+		head := fmt.Sprintf("#%d %s %s", c.ID, initial(c.Type), c.Assignee)
+		body := []string{truncate(head, width-2), truncate(c.Title, width-2)}
+		style := cardStyle
+		if i == m.col && j == m.row {
+			style = selectedStyle
+		} else if c.Mine {
+			style = style.Inherit(mineStyle)
+		}
+		for _, l := range body {
+			lines = append(lines, style.Width(width-1).Render(l))
+		}
+		lines = append(lines, "")
+	}
+	if rest := len(cards) - start - rows; rest > 0 {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf(" ↓ %d more", rest)))
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+func initial(wiType string) string {
+	if wiType == "" {
+		return "?"
+	}
+	return strings.ToUpper(wiType[:1])
+}
+
+// truncate shortens s to at most w cells, marking the cut with "…".
+func truncate(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= w {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && lipgloss.Width(string(r))+1 > w {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
@@ -0,0 +1,132 @@
+package kanban
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type stubSource struct {
+	cards []Card
+	moves []int
+}
+
+func (s *stubSource) Cards() ([]Card, error) { return append([]Card(nil), s.cards...), nil }
+
+func (s *stubSource) Move(id, delta int) (Card, error) {
+	cols := []string{"Todo", "Doing", "Done"}
+	for i, c := range s.cards {
+		if c.ID != id {
+			continue
+		}
+		for j, name := range cols {
+			if name == c.Column {
+				s.cards[i].Column = cols[j+delta]
+			}
+		}
+		s.moves = append(s.moves, id)
+		return s.cards[i], nil
+	}
+	return Card{}, nil
+}
+
+func (s *stubSource) Show(id, width int) (string, error) { return "details of card", nil }
+
+// send feeds msg to m and runs the returned command, feeding its message
+// back, until no command remains (tea.Quit stops the loop).
+func send(t *testing.T, m tea.Model, msg tea.Msg) tea.Model {
+	t.Helper()
+	for msg != nil {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		msg = nil
+		if cmd != nil {
+			msg = cmd()
+			if _, quit := msg.(tea.QuitMsg); quit {
+				return m
+			}
+		}
+	}
+	return m
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func loaded(t *testing.T, src *stubSource, opts Options) tea.Model {
+	t.Helper()
+	m := New(src, opts)
+	var tm tea.Model = m
+	tm = send(t, tm, tea.WindowSizeMsg{Width: 120, Height: 30})
+	return send(t, tm, m.Init()())
+}
+
+func TestBoard_LayoutAndMove(t *testing.T) {
+	src := &stubSource{cards: []Card{
+		{ID: 1, Type: "User Story", Assignee: "Me", Title: "First", Column: "Todo", Mine: true},
+		{ID: 2, Type: "Bug", Assignee: "You", Title: "Second", Column: "Todo"},
+		{ID: 3, Type: "User Story", Title: "Elsewhere", Column: "Archive"},
+	}}
+	m := loaded(t, src, Options{Columns: []string{"Todo", "Doing", "Done"}})
+	view := m.View()
+	for _, want := range []string{"Todo (2)", "Doing (0)", "Archive (1)", "#1 U Me", "#2 B You", "Second"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Select the second card and push it forward twice.
+	m = send(t, m, key("j"))
+	m = send(t, m, key("L"))
+	m = send(t, m, key("L"))
+	if got := src.cards[1].Column; got != "Done" {
+		t.Fatalf("card 2 in %q, want Done", got)
+	}
+	if sel, _ := m.(Model).selected(); sel.ID != 2 {
+		t.Fatalf("selection did not follow the moved card: %+v", sel)
+	}
+	if !strings.Contains(m.View(), "Moved #2 from Doing to Done") {
+		t.Fatalf("status missing:\n%s", m.View())
+	}
+}
+
+func TestBoard_ConfirmMineAndDetail(t *testing.T) {
+	src := &stubSource{cards: []Card{
+		{ID: 1, Type: "User Story", Title: "Mine", Column: "Todo", Mine: true},
+		{ID: 2, Type: "User Story", Title: "Theirs", Column: "Todo"},
+	}}
+	m := loaded(t, src, Options{Columns: []string{"Todo", "Doing", "Done"}, Confirm: true})
+
+	m = send(t, m, key("L"))
+	m = send(t, m, key("n"))
+	if len(src.moves) != 0 {
+		t.Fatal("move ran although declined")
+	}
+	m = send(t, m, key("L"))
+	m = send(t, m, key("y"))
+	if len(src.moves) != 1 || src.cards[0].Column != "Doing" {
+		t.Fatalf("confirmed move not performed: %+v", src.cards)
+	}
+
+	m = send(t, m, key("m"))
+	if strings.Contains(m.View(), "Theirs") {
+		t.Fatalf("mine filter shows other cards:\n%s", m.View())
+	}
+
+	m = send(t, m, key("enter"))
+	if !strings.Contains(m.View(), "details of card") {
+		t.Fatalf("card not opened:\n%s", m.View())
+	}
+	m = send(t, m, key("esc"))
+	if !strings.Contains(m.View(), "ab board (mine)") {
+		t.Fatalf("esc did not return to the board:\n%s", m.View())
+	}
+}
@@ -0,0 +1,418 @@
+// Package output writes work items in machine-readable formats for scripts.
+//
+// Every format uses the same record (Item) so that switching between json,
+// yaml, csv, tsv and ids never changes which data is available. Fields are
+// only ever added at the end of the record, never renamed or reordered.
+// Other data, such as comments and attachments, has its own records written
+// the same way (see Record).
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sa6mwa/ab/internal/az"
+	"github.com/sa6mwa/ab/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported formats. The empty format means the default rendered Markdown.
+const (
+	JSON = "json"
+	YAML = "yaml"
+	CSV  = "csv"
+	TSV  = "tsv"
+	IDs  = "ids"
+)
+
+// Formats lists the accepted format names in documentation order.
+var Formats = []string{JSON, YAML, CSV, TSV, IDs}
+
+// Normalize validates a format name and returns its canonical spelling.
+func Normalize(format string) (string, error) {
+	f := strings.ToLower(strings.TrimSpace(format))
+	switch f {
+	case "", "md", "markdown":
+		return "", nil
+	case "yml":
+		return YAML, nil
+	case JSON, YAML, CSV, TSV, IDs:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid format: %q (valid: %s)", format, strings.Join(Formats, "|"))
+}
+
+// Item is the stable record emitted for a work item.
+type Item struct {
+	ID       int      `json:"id" yaml:"id"`
+	Rev      int      `json:"rev" yaml:"rev"`
+	Type     string   `json:"type" yaml:"type"`
+	State    string   `json:"state" yaml:"state"`
+	Column   string   `json:"column" yaml:"column"`
+	Assignee string   `json:"assignee" yaml:"assignee"`
+	Title    string   `json:"title" yaml:"title"`
+	Parent   *int     `json:"parent" yaml:"parent"`
+	Tags     []string `json:"tags" yaml:"tags"`
+	URL      string   `json:"url" yaml:"url"`
+	// Action names the mutation applied (resolved, closed, deleted, ...)
+	// in the summaries printed by commands that change work items.
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+	// Error is why the action failed (Action is then "failed" or "skipped").
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Children is only set by show for items that have child work items.
+	Children []Item `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// Columns is the header row used by the csv and tsv formats.
+var Columns = []string{"id", "rev", "type", "state", "column", "assignee", "title", "parent", "tags", "url", "action", "error"}
+
+// FromWorkItem builds the record for wi.
+func FromWorkItem(wi *az.WorkItem) Item {
+	it := FromFields(wi.ID, wi.Fields)
+	it.Rev = wi.Rev
+	it.URL = wi.URL
+	if it.Parent == nil {
+		for _, r := range wi.Relations {
+			if r.Rel != "System.LinkTypes.Hierarchy-Reverse" {
+				continue
+			}
+			if n, err := strconv.Atoi(r.URL[strings.LastIndex(r.URL, "/")+1:]); err == nil {
+				it.Parent = &n
+			}
+		}
+	}
+	return it
+}
+
+// FromFields builds a record from a work item id and its fields map as
+// returned by queries, which carry neither revision nor relations.
+func FromFields(id int, fields map[string]any) Item {
+	it := Item{
+		ID:       id,
+		Type:     util.FieldString(fields, "System.WorkItemType"),
+		State:    util.FieldString(fields, "System.State"),
+		Assignee: identityName(fields["System.AssignedTo"]),
+		Title:    util.FieldString(fields, "System.Title"),
+		Tags:     SplitTags(util.FieldString(fields, "System.Tags")),
+	}
+	if rev, ok := fields["System.Rev"].(float64); ok {
+		it.Rev = int(rev)
+	}
+	if _, col := util.FindKanbanColumn(fields); col != "" {
+		it.Column = col
+	} else {
+		it.Column = util.FieldString(fields, "System.BoardColumn")
+	}
+	switch p := fields["System.Parent"].(type) {
+	case float64:
+		n := int(p)
+		it.Parent = &n
+	case int:
+		it.Parent = &p
+	case string:
+		if n, err := strconv.Atoi(p); err == nil {
+			it.Parent = &n
+		}
+	}
+	return it
+}
+
+// SplitTags splits the semicolon separated System.Tags value.
+func SplitTags(tags string) []string {
+	out := []string{}
+	for _, t := range strings.Split(tags, ";") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func identityName(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]any:
+		if dn, ok := t["displayName"].(string); ok {
+			return dn
+		}
+	}
+	return ""
+}
+
+// Write emits items as a list in the given format.
+func Write(w io.Writer, format string, items []Item) error {
+	if items == nil {
+		items = []Item{}
+	}
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case YAML:
+		return writeYAML(w, items)
+	}
+	return writeRows(w, format, items)
+}
+
+// WriteOne emits a single item; json and yaml produce an object instead of
+// a one-element list, csv and tsv also emit the item's children as rows.
+func WriteOne(w io.Writer, format string, item Item) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(item)
+	case YAML:
+		return writeYAML(w, item)
+	}
+	rows := append([]Item{item}, item.Children...)
+	return writeRows(w, format, rows)
+}
+
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func writeRows(w io.Writer, format string, items []Item) error {
+	switch format {
+	case IDs:
+		for _, it := range items {
+			if _, err := fmt.Fprintln(w, it.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV, TSV:
+		cw := csv.NewWriter(w)
+		if format == TSV {
+			cw.Comma = '\t'
+		}
+		if err := cw.Write(Columns); err != nil {
+			return err
+		}
+		for _, it := range items {
+			parent := ""
+			if it.Parent != nil {
+				parent = strconv.Itoa(*it.Parent)
+			}
+			row := []string{strconv.Itoa(it.ID), strconv.Itoa(it.Rev), it.Type, it.State, it.Column, it.Assignee, it.Title, parent, strings.Join(it.Tags, ";"), it.URL, it.Action, it.Error}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unsupported format: %q", format)
+}
+
+// Record is implemented by the records of things other than work items
+// (comments, ...). json and yaml encode the record itself; csv and tsv
+// write Row under a header; ids prints the first column.
+type Record interface {
+	Row() []string
+}
+
+// WriteRecords emits recs as a list in the given format; header names the
+// csv/tsv columns.
+func WriteRecords[T Record](w io.Writer, format string, header []string, recs []T) error {
+	if recs == nil {
+		recs = []T{}
+	}
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(recs)
+	case YAML:
+		return writeYAML(w, recs)
+	case IDs:
+		for _, r := range recs {
+			if _, err := fmt.Fprintln(w, r.Row()[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV, TSV:
+		cw := csv.NewWriter(w)
+		if format == TSV {
+			cw.Comma = '\t'
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, r := range recs {
+			if err := cw.Write(r.Row()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unsupported format: %q", format)
+}
+
+// Comment is the record of a comment in a work item's Discussion. Text is
+// Markdown.
+type Comment struct {
+	ID      int    `json:"id" yaml:"id"`
+	Item    int    `json:"item" yaml:"item"`
+	Author  string `json:"author" yaml:"author"`
+	Created string `json:"created" yaml:"created"`
+	Text    string `json:"text" yaml:"text"`
+}
+
+// CommentColumns is the csv/tsv header of Comment.
+var CommentColumns = []string{"id", "item", "author", "created", "text"}
+
+// Row implements Record.
+func (c Comment) Row() []string {
+	return []string{strconv.Itoa(c.ID), strconv.Itoa(c.Item), c.Author, c.Created, c.Text}
+}
+
+// Attachment is the record of a file attached to a work item.
+type Attachment struct {
+	ID      string `json:"id" yaml:"id"`
+	Item    int    `json:"item" yaml:"item"`
+	Name    string `json:"name" yaml:"name"`
+	Size    int64  `json:"size" yaml:"size"`
+	Created string `json:"created" yaml:"created"`
+	URL     string `json:"url" yaml:"url"`
+	// Path is where download saved the file.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// AttachmentColumns is the csv/tsv header of Attachment.
+var AttachmentColumns = []string{"id", "item", "name", "size", "created", "url", "path"}
+
+// Row implements Record.
+func (a Attachment) Row() []string {
+	return []string{a.ID, strconv.Itoa(a.Item), a.Name, strconv.FormatInt(a.Size, 10), a.Created, a.URL, a.Path}
+}
+
+// Iteration is the record of a team iteration (sprint). Dates are
+// YYYY-MM-DD.
+type Iteration struct {
+	Name      string `json:"name" yaml:"name"`
+	Path      string `json:"path" yaml:"path"`
+	Start     string `json:"start" yaml:"start"`
+	Finish    string `json:"finish" yaml:"finish"`
+	TimeFrame string `json:"timeFrame" yaml:"timeFrame"`
+	Current   bool   `json:"current" yaml:"current"`
+}
+
+// IterationColumns is the csv/tsv header of Iteration.
+var IterationColumns = []string{"name", "path", "start", "finish", "timeframe", "current"}
+
+// Row implements Record.
+func (i Iteration) Row() []string {
+	return []string{i.Name, i.Path, i.Start, i.Finish, i.TimeFrame, strconv.FormatBool(i.Current)}
+}
+
+// Change is the record of one field changed in a revision of a work item.
+// Date is RFC 3339; HTML fields carry Markdown.
+type Change struct {
+	Item  int    `json:"item" yaml:"item"`
+	Rev   int    `json:"rev" yaml:"rev"`
+	Date  string `json:"date" yaml:"date"`
+	By    string `json:"by" yaml:"by"`
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+}
+
+// ChangeColumns is the csv/tsv header of Change.
+var ChangeColumns = []string{"item", "rev", "date", "by", "field", "old", "new"}
+
+// Row implements Record.
+func (c Change) Row() []string {
+	return []string{strconv.Itoa(c.Item), strconv.Itoa(c.Rev), c.Date, c.By, c.Field, c.Old, c.New}
+}
+
+// SavedQuery is the record of a saved Azure DevOps query. Type is flat,
+// tree or oneHop.
+type SavedQuery struct {
+	ID     string `json:"id" yaml:"id"`
+	Path   string `json:"path" yaml:"path"`
+	Type   string `json:"type" yaml:"type"`
+	Public bool   `json:"public" yaml:"public"`
+}
+
+// SavedQueryColumns is the csv/tsv header of SavedQuery.
+var SavedQueryColumns = []string{"id", "path", "type", "public"}
+
+// Row implements Record.
+func (q SavedQuery) Row() []string {
+	return []string{q.ID, q.Path, q.Type, strconv.FormatBool(q.Public)}
+}
+
+// Operation is the record of a journaled mutation. Run numbers the ab
+// commands from the latest (1); Time is RFC 3339 and Changes lists the
+// fields as "field: old → new".
+type Operation struct {
+	Run     int    `json:"run" yaml:"run"`
+	Time    string `json:"time" yaml:"time"`
+	Command string `json:"command" yaml:"command"`
+	Action  string `json:"action" yaml:"action"`
+	Item    int    `json:"item,omitempty" yaml:"item,omitempty"`
+	Title   string `json:"title,omitempty" yaml:"title,omitempty"`
+	Changes string `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Undone  bool   `json:"undone" yaml:"undone"`
+	Error   string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// OperationColumns is the csv/tsv header of Operation.
+var OperationColumns = []string{"run", "time", "command", "action", "item", "title", "changes", "undone", "error"}
+
+// Row implements Record.
+func (o Operation) Row() []string {
+	item := ""
+	if o.Item != 0 {
+		item = strconv.Itoa(o.Item)
+	}
+	return []string{strconv.Itoa(o.Run), o.Time, o.Command, o.Action, item, o.Title, o.Changes, strconv.FormatBool(o.Undone), o.Error}
+}
+
+// Export is the record of a work item in a backlog export. Description and
+// AcceptanceCriteria are Markdown; Children is only set by json and yaml,
+// csv and tsv list the children as rows after their parent.
+type Export struct {
+	ID                 int      `json:"id" yaml:"id"`
+	Type               string   `json:"type" yaml:"type"`
+	State              string   `json:"state" yaml:"state"`
+	Column             string   `json:"column" yaml:"column"`
+	Assignee           string   `json:"assignee" yaml:"assignee"`
+	Title              string   `json:"title" yaml:"title"`
+	Parent             *int     `json:"parent" yaml:"parent"`
+	Tags               []string `json:"tags" yaml:"tags"`
+	Area               string   `json:"area" yaml:"area"`
+	Iteration          string   `json:"iteration" yaml:"iteration"`
+	Description        string   `json:"description" yaml:"description"`
+	AcceptanceCriteria string   `json:"acceptanceCriteria,omitempty" yaml:"acceptanceCriteria,omitempty"`
+	URL                string   `json:"url" yaml:"url"`
+	Children           []Export `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// ExportColumns is the csv/tsv header of Export.
+var ExportColumns = []string{"id", "type", "state", "column", "assignee", "title", "parent", "tags", "area", "iteration", "description", "acceptancecriteria", "url"}
+
+// Row implements Record.
+func (e Export) Row() []string {
+	parent := ""
+	if e.Parent != nil {
+		parent = strconv.Itoa(*e.Parent)
+	}
+	return []string{strconv.Itoa(e.ID), e.Type, e.State, e.Column, e.Assignee, e.Title, parent, strings.Join(e.Tags, ";"),
+		e.Area, e.Iteration, e.Description, e.AcceptanceCriteria, e.URL}
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sa6mwa/ab/internal/az"
+)
+
+func sample() Item {
+	return FromWorkItem(&az.WorkItem{
+		ID:  7,
+		Rev: 3,
+		URL: "https://example/_apis/wit/workItems/7",
+		Fields: map[string]any{
+			"System.WorkItemType":   "Task",
+			"System.State":          "Active",
+			"System.Title":          "Fix, then \"ship\"",
+			"System.AssignedTo":     map[string]any{"displayName": "Me User", "uniqueName": "me@example.com"},
+			"System.Tags":           "alpha; beta",
+			"WEF_ABC_Kanban.Column": "In Process",
+		},
+		Relations: []az.Relation{{Rel: "System.LinkTypes.Hierarchy-Reverse", URL: "https://example/_apis/wit/workItems/5"}},
+	})
+}
+
+func TestFromWorkItem(t *testing.T) {
+	it := sample()
+	if it.Assignee != "Me User" || it.Column != "In Process" || it.Parent == nil || *it.Parent != 5 {
+		t.Fatalf("unexpected record: %+v", it)
+	}
+	if len(it.Tags) != 2 || it.Tags[1] != "beta" {
+		t.Fatalf("tags = %v", it.Tags)
+	}
+}
+
+func TestWrite_JSONSchema(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, JSON, []Item{sample()}); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, b.String())
+	}
+	for _, k := range []string{"id", "rev", "type", "state", "column", "assignee", "title", "parent", "tags", "url"} {
+		if _, ok := got[0][k]; !ok {
+			t.Fatalf("missing key %q in %s", k, b.String())
+		}
+	}
+	b.Reset()
+	if err := Write(&b, JSON, nil); err != nil || strings.TrimSpace(b.String()) != "[]" {
+		t.Fatalf("empty list = %q, %v", b.String(), err)
+	}
+}
+
+func TestWrite_CSVAndTSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, CSV, []Item{sample()}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if lines[0] != strings.Join(Columns, ",") {
+		t.Fatalf("header = %q", lines[0])
+	}
+	if want := `7,3,Task,Active,In Process,Me User,"Fix, then ""ship""",5,alpha;beta,https://example/_apis/wit/workItems/7,,`; lines[1] != want {
+		t.Fatalf("row = %q, want %q", lines[1], want)
+	}
+	b.Reset()
+	if err := Write(&b, TSV, []Item{sample()}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "id\trev\ttype") {
+		t.Fatalf("tsv = %q", b.String())
+	}
+}
+
+func TestWriteOne_YAMLAndIDs(t *testing.T) {
+	it := sample()
+	it.Children = []Item{{ID: 8, Tags: []string{}}}
+	var b bytes.Buffer
+	if err := WriteOne(&b, YAML, it); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "id: 7\n") || !strings.Contains(b.String(), "children:") {
+		t.Fatalf("yaml = %q", b.String())
+	}
+	b.Reset()
+	if err := WriteOne(&b, IDs, it); err != nil || b.String() != "7\n8\n" {
+		t.Fatalf("ids = %q, %v", b.String(), err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if f, err := Normalize("YML"); err != nil || f != YAML {
+		t.Fatalf("Normalize(YML) = %q, %v", f, err)
+	}
+	if f, err := Normalize(""); err != nil || f != "" {
+		t.Fatalf("Normalize(\"\") = %q, %v", f, err)
+	}
+	if _, err := Normalize("xml"); err == nil {
+		t.Fatal("expected error for xml")
+	}
+}
@@ -0,0 +1,12 @@
+package term
+
+import (
+	"os"
+
+	xterm "golang.org/x/term"
+)
+
+// IsTerminal reports whether f is connected to a terminal.
+func IsTerminal(f *os.File) bool {
+	return xterm.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"os"
+	"strings"
+)
+
+// EnvTrue reports whether the environment variable name is set to a true
+// value: 1, true, yes, y or on, in any case.
+func EnvTrue(name string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes", "y", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package util
+
+import "testing"
+
+func TestEnvTrue(t *testing.T) {
+	for v, want := range map[string]bool{"1": true, " Yes ": true, "ON": true, "": false, "0": false, "off": false, "sure": false} {
+		t.Setenv("AB_TEST_FLAG", v)
+		if got := EnvTrue("AB_TEST_FLAG"); got != want {
+			t.Errorf("EnvTrue(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package wiql builds Work Item Query Language statements.
+//
+// Values are always rendered through Literal, which quotes and escapes
+// strings, so user input such as titles or names can never end a string
+// literal early and change the query. Conditions are plain strings so that
+// they compose with And, Or and Query.Where.
+package wiql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Macro is a WIQL macro such as @Me or @Today, rendered verbatim.
+type Macro string
+
+// Me is the signed-in user.
+const Me Macro = "@Me"
+
+// Quote returns s as a single-quoted WIQL string literal.
+func Quote(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+
+// Field returns the bracketed reference of a field name.
+func Field(name string) string {
+	return "[" + strings.Trim(strings.TrimSpace(name), "[]") + "]"
+}
+
+// Source and Target qualify a field of the linked items of a LinkQuery,
+// e.g. [Target].[System.State]; the results work as field names.
+func Source(field string) string { return "[Source]." + Field(field) }
+func Target(field string) string { return "[Target]." + Field(field) }
+
+// LinkType is the field holding the link type of a LinkQuery.
+const LinkType = "System.Links.LinkType"
+
+// Hierarchy is the link type from a parent to its children.
+const Hierarchy = "System.LinkTypes.Hierarchy-Forward"
+
+// Literal renders v as a WIQL value: strings are quoted, numbers and
+// macros verbatim and times as quoted dates (WIQL compares whole days).
+// Anything else is quoted in its fmt.Sprint form.
+func Literal(v any) string {
+	switch t := v.(type) {
+	case Macro:
+		return string(t)
+	case string:
+		return Quote(t)
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case time.Time:
+		return Quote(t.Format("2006-01-02"))
+	}
+	return Quote(fmt.Sprint(v))
+}
+
+// Op returns the condition "[field] op value".
+func Op(field, op string, v any) string { return Field(field) + " " + op + " " + Literal(v) }
+
+// Eq, Ne, Ge and Le compare a field with a value.
+func Eq(field string, v any) string { return Op(field, "=", v) }
+func Ne(field string, v any) string { return Op(field, "<>", v) }
+func Ge(field string, v any) string { return Op(field, ">=", v) }
+func Le(field string, v any) string { return Op(field, "<=", v) }
+
+// Contains matches a substring of a text field (or a tag of System.Tags).
+func Contains(field, s string) string { return Op(field, "CONTAINS", s) }
+
+// Under matches a tree path (area or iteration) and the paths below it.
+func Under(field, path string) string { return Op(field, "UNDER", path) }
+
+// In matches any of vs; a single value becomes an Eq.
+func In(field string, vs ...any) string { return list(field, "IN", vs) }
+
+// NotIn matches none of vs; a single value becomes a Ne.
+func NotIn(field string, vs ...any) string { return list(field, "NOT IN", vs) }
+
+func list(field, op string, vs []any) string {
+	if len(vs) == 1 {
+		if op == "IN" {
+			return Eq(field, vs[0])
+		}
+		return Ne(field, vs[0])
+	}
+	lits := make([]string, 0, len(vs))
+	for _, v := range vs {
+		lits = append(lits, Literal(v))
+	}
+	return Field(field) + " " + op + " (" + strings.Join(lits, ",") + ")"
+}
+
+// Strings converts ss for In and NotIn.
+func Strings(ss []string) []any {
+	out := make([]any, 0, len(ss))
+	for _, s := range ss {
+		out = append(out, s)
+	}
+	return out
+}
+
+// And joins conditions with AND, skipping empty ones.
+func And(conds ...string) string { return join(" AND ", conds) }
+
+// Or joins conditions with OR in parentheses, skipping empty ones.
+func Or(conds ...string) string {
+	s := join(" OR ", conds)
+	if strings.Contains(s, " OR ") {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func join(sep string, conds []string) string {
+	var keep []string
+	for _, c := range conds {
+		if c = strings.TrimSpace(c); c != "" {
+			keep = append(keep, c)
+		}
+	}
+	return strings.Join(keep, sep)
+}
+
+// Query is a flat WorkItems query.
+type Query struct {
+	fields []string
+	where  []string
+	order  []string
+}
+
+// Select starts a query selecting fields (names with or without brackets).
+func Select(fields ...string) *Query {
+	q := &Query{}
+	for _, f := range fields {
+		q.fields = append(q.fields, Field(f))
+	}
+	return q
+}
+
+// Where adds conditions, all of which must hold; empty ones are skipped.
+func (q *Query) Where(conds ...string) *Query {
+	for _, c := range conds {
+		if c = strings.TrimSpace(c); c != "" {
+			q.where = append(q.where, c)
+		}
+	}
+	return q
+}
+
+// OrderBy appends sort keys such as "[System.ChangedDate] DESC".
+func (q *Query) OrderBy(keys ...string) *Query {
+	q.order = append(q.order, keys...)
+	return q
+}
+
+// String renders the statement.
+func (q *Query) String() string {
+	var b strings.Builder
+	b.WriteString("SELECT " + strings.Join(q.fields, ", ") + " FROM WorkItems")
+	if len(q.where) > 0 {
+		b.WriteString(" WHERE " + strings.Join(q.where, " AND "))
+	}
+	if len(q.order) > 0 {
+		b.WriteString(" ORDER BY " + strings.Join(q.order, ", "))
+	}
+	return b.String()
+}
+
+// Modes of a LinkQuery.
+const (
+	// Recursive follows the links down from the top-level items (tree
+	// queries; hierarchy links only).
+	Recursive = "Recursive"
+	// MustContain returns top-level items with at least one matching link.
+	MustContain = "MustContain"
+	// MayContain returns all top-level items with their matching links.
+	MayContain = "MayContain"
+)
+
+// LinkQuery is a WorkItemLinks query. Conditions on the top-level items
+// use Source, those on the linked items Target, and LinkType the link.
+type LinkQuery struct {
+	Query
+	mode string
+}
+
+// SelectLinks starts a WorkItemLinks query selecting fields.
+func SelectLinks(fields ...string) *LinkQuery {
+	return &LinkQuery{Query: *Select(fields...)}
+}
+
+// Where adds conditions, all of which must hold; empty ones are skipped.
+func (q *LinkQuery) Where(conds ...string) *LinkQuery {
+	q.Query.Where(conds...)
+	return q
+}
+
+// Mode sets the MODE of the query.
+func (q *LinkQuery) Mode(mode string) *LinkQuery {
+	q.mode = mode
+	return q
+}
+
+// String renders the statement.
+func (q *LinkQuery) String() string {
+	s := strings.Replace(q.Query.String(), " FROM WorkItems", " FROM WorkItemLinks", 1)
+	if q.mode != "" {
+		s += " MODE (" + q.mode + ")"
+	}
+	return s
+}
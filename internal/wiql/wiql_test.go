@@ -0,0 +1,52 @@
+package wiql
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryString(t *testing.T) {
+	q := Select("System.Id", "[System.Title]").
+		Where(Ne("System.State", "Closed"), "", In("System.WorkItemType", "User Story", "Bug")).
+		Where(Eq("System.AssignedTo", Me), Eq("System.Parent", 12)).
+		OrderBy("[System.ChangedDate] DESC")
+	want := "SELECT [System.Id], [System.Title] FROM WorkItems WHERE [System.State] <> 'Closed' AND " +
+		"[System.WorkItemType] IN ('User Story','Bug') AND [System.AssignedTo] = @Me AND [System.Parent] = 12 " +
+		"ORDER BY [System.ChangedDate] DESC"
+	if got := q.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+	if got := Select("System.Id").String(); got != "SELECT [System.Id] FROM WorkItems" {
+		t.Fatalf("bare select = %s", got)
+	}
+}
+
+func TestLinkQueryString(t *testing.T) {
+	q := SelectLinks("System.Id").
+		Where(Eq(Source("System.Id"), 7), Ne(Target("[System.State]"), "Closed")).
+		Where(Eq(LinkType, Hierarchy)).
+		Mode(Recursive)
+	want := "SELECT [System.Id] FROM WorkItemLinks WHERE [Source].[System.Id] = 7 AND [Target].[System.State] <> 'Closed' AND " +
+		"[System.Links.LinkType] = 'System.LinkTypes.Hierarchy-Forward' MODE (Recursive)"
+	if got := q.String(); got != want {
+		t.Fatalf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestLiteralsEscape(t *testing.T) {
+	for _, tc := range []struct{ got, want string }{
+		{Contains("System.Title", "it's ' OR 1=1 --"), "[System.Title] CONTAINS 'it''s '' OR 1=1 --'"},
+		{Eq("System.AssignedTo", "O'Brien <ob@example.com>"), "[System.AssignedTo] = 'O''Brien <ob@example.com>'"},
+		{In("System.State", "Active"), "[System.State] = 'Active'"},
+		{NotIn("System.State", Strings([]string{"A", "B'"})...), "[System.State] NOT IN ('A','B''')"},
+		{Ge("System.ChangedDate", time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)), "[System.ChangedDate] >= '2026-10-16'"},
+		{Under("System.AreaPath", `Fake\Front'end`), `[System.AreaPath] UNDER 'Fake\Front''end'`},
+		{Or(Eq("A", 1), "", Eq("B", 2)), "([A] = 1 OR [B] = 2)"},
+		{Or(Eq("A", 1)), "[A] = 1"},
+		{And("", Eq("A", 1), Eq("B", 2)), "[A] = 1 AND [B] = 2"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got  %s\nwant %s", tc.got, tc.want)
+		}
+	}
+}